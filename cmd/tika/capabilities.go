@@ -0,0 +1,152 @@
+/*
+Copyright 2017 Google Inc. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/google/go-tika/tika"
+)
+
+// capabilities are what a server supports.
+type capabilities struct {
+	version   string
+	parsers   map[string]bool
+	detectors map[string]bool
+	mimeTypes map[string]bool
+	// parsedTypes are the MIME types some parser supports.
+	parsedTypes map[string]bool
+}
+
+// setDiff lists the elements only in the first server and only in the second.
+type setDiff struct {
+	Removed []string `json:"removed"`
+	Added   []string `json:"added"`
+}
+
+// capabilitiesDiff is the difference between the capabilities of two servers.
+type capabilitiesDiff struct {
+	Version struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"version"`
+	Parsers     setDiff `json:"parsers"`
+	Detectors   setDiff `json:"detectors"`
+	MIMETypes   setDiff `json:"mime_types"`
+	ParsedTypes setDiff `json:"parsed_types"`
+}
+
+// getCapabilities queries the capabilities of the server c talks to.
+func getCapabilities(ctx context.Context, c *tika.Client) (*capabilities, error) {
+	v, err := c.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	caps := &capabilities{
+		version:     strings.TrimSpace(v),
+		parsers:     map[string]bool{},
+		detectors:   map[string]bool{},
+		mimeTypes:   map[string]bool{},
+		parsedTypes: map[string]bool{},
+	}
+	p, err := c.Parsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var addParser func(p *tika.Parser)
+	addParser = func(p *tika.Parser) {
+		if len(p.Children) == 0 {
+			caps.parsers[p.Name] = true
+		}
+		for _, t := range p.SupportedTypes {
+			caps.parsedTypes[t] = true
+		}
+		for i := range p.Children {
+			addParser(&p.Children[i])
+		}
+	}
+	addParser(p)
+
+	d, err := c.Detectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var addDetector func(d *tika.Detector)
+	addDetector = func(d *tika.Detector) {
+		if len(d.Children) == 0 {
+			caps.detectors[d.Name] = true
+		}
+		for i := range d.Children {
+			addDetector(&d.Children[i])
+		}
+	}
+	addDetector(d)
+
+	mt, err := c.MIMETypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for t := range mt {
+		caps.mimeTypes[t] = true
+	}
+	return caps, nil
+}
+
+// diffSets returns the elements only in a and only in b, sorted.
+func diffSets(a, b map[string]bool) setDiff {
+	d := setDiff{Removed: []string{}, Added: []string{}}
+	for k := range a {
+		if !b[k] {
+			d.Removed = append(d.Removed, k)
+		}
+	}
+	for k := range b {
+		if !a[k] {
+			d.Added = append(d.Added, k)
+		}
+	}
+	sort.Strings(d.Removed)
+	sort.Strings(d.Added)
+	return d
+}
+
+// compareCapabilities queries the servers behind from and to and returns the
+// difference in their capabilities as indented JSON.
+func compareCapabilities(ctx context.Context, from, to *tika.Client) (string, error) {
+	a, err := getCapabilities(ctx, from)
+	if err != nil {
+		return "", err
+	}
+	b, err := getCapabilities(ctx, to)
+	if err != nil {
+		return "", err
+	}
+	var d capabilitiesDiff
+	d.Version.From = a.version
+	d.Version.To = b.version
+	d.Parsers = diffSets(a.parsers, b.parsers)
+	d.Detectors = diffSets(a.detectors, b.detectors)
+	d.MIMETypes = diffSets(a.mimeTypes, b.mimeTypes)
+	d.ParsedTypes = diffSets(a.parsedTypes, b.parsedTypes)
+	bytes, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
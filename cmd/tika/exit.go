@@ -0,0 +1,112 @@
+/*
+Copyright 2017 Google Inc. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/go-tika/tika"
+)
+
+// Exit codes, so scripts can tell failures apart without parsing messages.
+const (
+	// exitError is any failure not covered by another exit code.
+	exitError = 1
+	// exitUsage is an invalid action, flag, or combination of flags.
+	exitUsage = 2
+	// exitUnreachable is a server that could not be started or reached, or
+	// which answered that it is unavailable.
+	exitUnreachable = 3
+	// exitParse is an error returned by the server for the input.
+	exitParse = 4
+	// exitPartial is a pipeline in which some inputs failed.
+	exitPartial = 5
+)
+
+// exitKinds names the exit codes in the errors printed with -json_errors.
+var exitKinds = map[int]string{
+	exitError:       "error",
+	exitUsage:       "usage",
+	exitUnreachable: "unreachable",
+	exitParse:       "parse",
+	exitPartial:     "partial",
+}
+
+var (
+	// errUsage is wrapped by errors caused by invalid flags.
+	errUsage = errors.New("usage error")
+	// errPartial is wrapped by the error of a pipeline in which some, but
+	// not necessarily all, inputs failed.
+	errPartial = errors.New("partial failure")
+)
+
+// stopServer stops the server started with -server_jar, if any, before fatal
+// exits.
+var stopServer = func() {}
+
+// exitCode returns the exit code for err.
+func exitCode(err error) int {
+	var ce tika.ClientError
+	switch {
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, errPartial):
+		return exitPartial
+	case errors.Is(err, tika.ErrServerUnavailable):
+		return exitUnreachable
+	case errors.As(err, &ce):
+		return exitParse
+	}
+	return exitError
+}
+
+// jsonError is an error printed with -json_errors.
+type jsonError struct {
+	Code  int    `json:"code"`
+	Kind  string `json:"kind"`
+	Error string `json:"error"`
+	// Status and RequestID are set for errors returned by the server.
+	Status    int    `json:"status,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// fatal stops the server, if any, prints err to stderr, as a JSON object with
+// -json_errors, and exits with code.
+func fatal(code int, err error) {
+	stopServer()
+	if !*jsonErrors {
+		// Not log.Fatal, which -q silences.
+		log.New(os.Stderr, "", log.LstdFlags).Print(err)
+		os.Exit(code)
+	}
+	je := jsonError{Code: code, Kind: exitKinds[code], Error: err.Error()}
+	var ce tika.ClientError
+	if errors.As(err, &ce) {
+		je.Status = ce.StatusCode
+		je.RequestID = ce.RequestID
+	}
+	json.NewEncoder(os.Stderr).Encode(je)
+	os.Exit(code)
+}
+
+// fatalf is fatal with a formatted error, which may wrap another with %w.
+func fatalf(code int, format string, args ...interface{}) {
+	fatal(code, fmt.Errorf(format, args...))
+}
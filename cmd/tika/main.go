@@ -22,18 +22,21 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/go-tika/tika"
 )
 
 func usage() {
 	fmt.Printf("Usage: %s [OPTIONS] ACTION\n\n", os.Args[0])
-	fmt.Printf("ACTIONS: parse, detect, language, meta, version, parsers, mimetypes, detectors\n\n")
+	fmt.Printf("ACTIONS: parse, detect, language, meta, version, parsers, mimetypes, detectors, pipeline, capabilities, server, watch\n\n")
 	fmt.Println("OPTIONS:")
 	flag.PrintDefaults()
+	fmt.Printf("\nEXIT CODES: %d error, %d usage, %d server unreachable, %d parse failure, %d partial pipeline or watch failure\n", exitError, exitUsage, exitUnreachable, exitParse, exitPartial)
 }
 
 // Flags requiring input.
@@ -52,87 +55,161 @@ const (
 	detectors = "detectors"
 )
 
+// Actions which don't take a single input.
+const (
+	// pipelineAction runs the pipeline spec given by -pipeline.
+	pipelineAction = "pipeline"
+	// capabilitiesAction compares the server with the one at -compare_url.
+	capabilitiesAction = "capabilities"
+	// serverAction runs the server at -server_jar as a service.
+	serverAction = "server"
+	// watchAction runs -action on the files appearing in -dir.
+	watchAction = "watch"
+)
+
 // Command line flags.
 var (
-	downloadVersion = flag.String("download_version", "", fmt.Sprintf("Tika Server JAR version to download. If -serverJAR is specified, it will be downloaded to that location, otherwise it will be downloaded to your working directory. If the JAR has already been downloaded and has the correct MD5, this will do nothing. Valid versions: %v.", tika.Versions))
+	downloadVersion = flag.String("download_version", "", fmt.Sprintf("Tika Server JAR version to download. If -server_jar is specified, it will be downloaded to that location, otherwise it will be downloaded to the go-tika directory of your user cache directory and reused across runs. If the JAR has already been downloaded and has the correct MD5, this will do nothing. Valid versions: %v, a major version such as 1 for the latest in that line, or latest.", tika.SupportedVersions()))
 	filename        = flag.String("filename", "", "Path to file to parse.")
+	inputURL        = flag.String("url", "", "URL of the document to parse instead of -filename. The document is fetched by this program and streamed to the server, and its Content-Type is logged.")
+	maxURLBytes     = flag.Int64("max_url_bytes", tika.DefaultMaxURLBytes, "Maximum size in bytes of the document fetched from -url.")
+	text            = flag.String("text", "", `Text whose language to detect with the "language" action, instead of -filename or -url.`)
+	allowPrivate    = flag.Bool("allow_private_urls", false, "Allow -url to fetch from loopback and private network addresses.")
+	pipelineFile    = flag.String("pipeline", "", `Path to a JSON or YAML pipeline spec for the "pipeline" action, listing sources, per-MIME type actions, concurrency, emitters, and error policy.`)
+	compareURL      = flag.String("compare_url", "", `URL of a second Tika server for the "capabilities" action, which prints the parsers, detectors, MIME types, and version that differ from the first server as JSON.`)
 	metaField       = flag.String("field", "", `Specific field to get when using the "meta" action. Undefined when using the -recursive flag.`)
+	metaOutput      = flag.String("meta_format", formatAuto, `Output format of the "meta" action without -field: table, json, csv (as returned by the server), or auto for table when writing to a terminal and json otherwise. csv is undefined with -recursive.`)
 	recursive       = flag.Bool("recursive", false, `Whether to run "parse" or "meta" recursively, returning a list with one element per embedded document. Undefined when using the -field flag.`)
-	serverJAR       = flag.String("server_jar", "", "Absolute path to the Tika Server JAR. This will start a new server, ignoring -serverURL.")
+	serverJAR       = flag.String("server_jar", "", "Absolute path to the Tika Server JAR. This will start a new server, ignoring -serverURL. If neither -server_jar nor -server_url is set, an installed JAR is used if one is found (see $TIKA_SERVER_JAR).")
 	serverURL       = flag.String("server_url", "", "URL of Tika server.")
+	watchDir        = flag.String("dir", "", `Directory the "watch" action polls for new or changed files.`)
+	watchActionFlag = flag.String("action", parse, `Action the "watch" action runs on each file: parse, detect, language, or meta.`)
+	outDir          = flag.String("out_dir", "", `Directory the "watch" action writes results to, mirroring -dir, with a .txt extension added, or .json for meta. Files with an up to date result are skipped.`)
+	pollInterval    = flag.Duration("poll_interval", 2*time.Second, `How often the "watch" action polls -dir.`)
+	debounce        = flag.Duration("debounce", 5*time.Second, `How long a file must stay unchanged before the "watch" action processes it, so files still being written are not read.`)
+	quiet           = flag.Bool("q", false, "Quiet: log nothing but a fatal error.")
+	jsonErrors      = flag.Bool("json_errors", false, `Print a fatal error to stderr as a JSON object with its exit code, kind (error, usage, unreachable, parse, or partial), and message, plus the HTTP status and request ID of errors returned by the server.`)
+	retries         = flag.Int("retries", 3, `How many times the "watch" action retries a failed file, with exponential backoff, before giving up on it until it changes.`)
 )
 
 func main() {
 	flag.Usage = usage
 	flag.Parse()
+	if *quiet {
+		log.SetOutput(ioutil.Discard)
+	}
 	if flag.NArg() != 1 {
 		flag.Usage()
-		os.Exit(1)
+		fatalf(exitUsage, "error: you must provide one action")
 	}
 	action := flag.Arg(0)
 
 	if *downloadVersion != "" {
-		v := tika.Versions[len(tika.Versions)-1]
-		supported := false
-		for _, sv := range tika.Versions {
-			if tika.Version(*downloadVersion) == sv {
-				v = tika.Version(*downloadVersion)
-				supported = true
-				break
+		v, err := tika.ParseVersion(*downloadVersion)
+		if err != nil {
+			fatalf(exitUsage, "%w: %v", errUsage, err)
+		}
+		if *serverJAR == "" {
+			path, err := tika.EnsureServer(context.Background(), v)
+			if err != nil {
+				fatal(exitError, err)
 			}
+			*serverJAR = path
+		} else if err := tika.DownloadServer(context.Background(), v, *serverJAR); err != nil {
+			fatal(exitError, err)
 		}
-		if !supported {
-			log.Fatalf("unsupported server version: %q", *downloadVersion)
+	}
+	if *serverURL == "" && *serverJAR == "" {
+		// Fall back to an installed JAR, if any.
+		path, err := tika.FindServerJAR()
+		if err != nil {
+			fatalf(exitUsage, "no URL specified: set serverURL, serverJAR and/or downloadVersion, or %s (%v)", tika.ServerJAREnv, err)
 		}
+		*serverJAR = path
+	}
+
+	if action == serverAction {
 		if *serverJAR == "" {
-			*serverJAR = "tika-server-" + string(v) + ".jar"
+			fatalf(exitUsage, "error: the server action needs a -server_jar")
+		}
+		if *systemdUnit {
+			u, err := unit(*serverJAR)
+			if err != nil {
+				fatal(exitError, err)
+			}
+			fmt.Print(u)
+			return
 		}
-		if err := tika.DownloadServer(context.Background(), v, *serverJAR); err != nil {
-			log.Fatal(err)
+		if err := runService(*serverJAR); err != nil {
+			fatal(exitError, err)
 		}
-	}
-	if *serverURL == "" && *serverJAR == "" {
-		log.Fatal("no URL specified: set serverURL, serverJAR and/or downloadVersion")
+		return
 	}
 
-	var cancel func()
 	if *serverJAR != "" {
 		s, err := tika.NewServer(*serverJAR, "")
 		if err != nil {
-			log.Fatal(err)
+			fatal(exitError, err)
 		}
 
 		err = s.Start(context.Background())
 		if err != nil {
-			log.Fatalf("could not start server: %v", err)
+			fatalf(exitUnreachable, "could not start server: %v", err)
 		}
 		defer s.Stop()
+		stopServer = func() { s.Stop() }
 
 		*serverURL = s.URL()
 	}
 
+	c, err := tika.NewClientURL(nil, *serverURL, tika.WithURLPolicy(tika.URLPolicy{AllowPrivate: *allowPrivate}))
+	if err != nil {
+		fatalf(exitUsage, "error: %v", err)
+	}
+
 	var file io.Reader
 
 	// Check actions requiring input have an input and get it.
 	switch action {
 	case parse, detect, language, meta:
-		if *filename == "" {
-			cancel()
-			log.Fatalf("error: you must provide an input filename")
-		}
-		var err error
-		file, err = os.Open(*filename)
-		if err != nil {
-			cancel()
-			log.Fatalf("error opening file: %v", err)
+		switch {
+		case *text != "":
+			if action != language {
+				fatalf(exitUsage, "error: -text is only supported by the %s action", language)
+			}
+		case *inputURL != "":
+			d, err := c.FetchURL(context.Background(), *inputURL, *maxURLBytes)
+			if err != nil {
+				fatalf(exitCode(err), "error fetching URL: %w", err)
+			}
+			defer d.Close()
+			log.Printf("fetched %s with Content-Type %q", d.URL, d.ContentType)
+			file = d
+		case *filename != "":
+			file, err = os.Open(*filename)
+			if err != nil {
+				fatalf(exitError, "error opening file: %v", err)
+			}
+		default:
+			fatalf(exitUsage, "error: you must provide an input -filename, -url, or, for the %s action, -text", language)
 		}
 	}
 
-	c := tika.NewClient(nil, *serverURL)
+	if action == pipelineAction && *pipelineFile == "" {
+		fatalf(exitUsage, "error: you must provide a -pipeline spec")
+	}
+
+	if action == capabilitiesAction && *compareURL == "" {
+		fatalf(exitUsage, "error: you must provide a -compare_url")
+	}
+
+	if action == watchAction && (*watchDir == "" || *outDir == "") {
+		fatalf(exitUsage, "error: you must provide a -dir and an -out_dir")
+	}
+
 	b, err := process(c, action, file)
 	if err != nil {
-		cancel()
-		log.Fatalf("tika error: %v", err)
+		fatalf(exitCode(err), "tika error: %w", err)
 	}
 	fmt.Println(b)
 }
@@ -141,7 +218,7 @@ func process(c *tika.Client, action string, file io.Reader) (string, error) {
 	switch action {
 	default:
 		flag.Usage()
-		return "", fmt.Errorf("error: invalid action %q", action)
+		return "", fmt.Errorf("%w: invalid action %q", errUsage, action)
 	case parse:
 		if *recursive {
 			bs, err := c.ParseRecursive(context.Background(), file)
@@ -154,23 +231,56 @@ func process(c *tika.Client, action string, file io.Reader) (string, error) {
 	case detect:
 		return c.Detect(context.Background(), file)
 	case language:
+		if *text != "" {
+			return c.LanguageString(context.Background(), *text)
+		}
 		return c.Language(context.Background(), file)
 	case meta:
 		if *metaField != "" {
 			return c.MetaField(context.Background(), file, *metaField)
 		}
+		format, err := metaFormat(*metaOutput)
+		if err != nil {
+			return "", err
+		}
+		var v interface{}
 		if *recursive {
 			mr, err := c.MetaRecursive(context.Background(), file)
 			if err != nil {
 				return "", err
 			}
-			bytes, err := json.MarshalIndent(mr, "", "  ")
+			if format == formatTable {
+				return metaTables(mr), nil
+			}
+			v = mr
+		} else {
+			if format == formatCSV {
+				return c.Meta(context.Background(), file)
+			}
+			m, err := c.Metadata(context.Background(), file)
 			if err != nil {
 				return "", err
 			}
-			return string(bytes), nil
+			if format == formatTable {
+				return metaTable(m), nil
+			}
+			v = m
+		}
+		bytes, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	case capabilitiesAction:
+		other, err := tika.NewClientURL(nil, *compareURL)
+		if err != nil {
+			return "", err
 		}
-		return c.Meta(context.Background(), file)
+		return compareCapabilities(context.Background(), c, other)
+	case pipelineAction:
+		return runPipeline(context.Background(), c, *pipelineFile)
+	case watchAction:
+		return runWatch(context.Background(), c)
 	case version:
 		return c.Version(context.Background())
 	case parsers:
@@ -0,0 +1,440 @@
+/*
+Copyright 2017 Google Inc. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-tika/tika"
+	"github.com/google/go-tika/tika/emit"
+	"github.com/google/go-tika/tika/emit/elastic"
+	"github.com/google/go-tika/tika/source"
+	"gopkg.in/yaml.v3"
+)
+
+// pipelineSpec is the specification of a pipeline run, read from JSON or,
+// for files ending in .yaml or .yml, from YAML with the same keys. For
+// example:
+//
+//	{
+//	  "sources": ["docs/", "gs://bucket/report.pdf"],
+//	  "concurrency": 4,
+//	  "rules": {"application/zip": "recursive", "video/*": "skip"},
+//	  "default": "parse",
+//	  "timeouts": {"image/*": "2m", "text/plain": "5s"},
+//	  "deny": ["application/x-msdownload", "video/*"],
+//	  "emitters": [
+//	    {"type": "files", "dir": "out"},
+//	    {"type": "ndjson", "path": "out.ndjson"}
+//	  ],
+//	  "on_error": "continue"
+//	}
+type pipelineSpec struct {
+	// Sources are files, directories, or gs:// and s3:// object references.
+	Sources []string `json:"sources"`
+	// Concurrency is the number of documents processed at once.
+	Concurrency int `json:"concurrency"`
+	// Rules maps MIME types, or types with a wildcard subtype, to one of the
+	// actions "parse", "recursive", or "skip".
+	Rules map[string]string `json:"rules"`
+	// Default is the action for documents matching no rule.
+	Default string `json:"default"`
+	// Timeouts maps MIME types, or types with a wildcard subtype, to the
+	// maximum time to process them, such as "30s". The value "default"
+	// uses tika.DefaultTimeouts.
+	Timeouts json.RawMessage `json:"timeouts"`
+	// Allow, if not empty, lists the only MIME types, or types with a
+	// wildcard subtype, that are processed, and Deny those that never are.
+	// Denied documents are logged and skipped.
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+	// Emitters receive every processed document.
+	Emitters []emitterSpec `json:"emitters"`
+	// OnError is "stop" (the default) to stop at the first error, or
+	// "continue" to log errors and process the remaining documents.
+	OnError string `json:"on_error"`
+}
+
+// emitterSpec configures an Emitter. Type is "files" (uses Dir and Ext),
+// "ndjson" (uses Path, or standard output if empty), or "elastic" (uses URL
+// and Index).
+type emitterSpec struct {
+	Type  string `json:"type"`
+	Dir   string `json:"dir"`
+	Ext   string `json:"ext"`
+	Path  string `json:"path"`
+	URL   string `json:"url"`
+	Index string `json:"index"`
+}
+
+// readPipelineSpec reads and validates the spec at path.
+func readPipelineSpec(path string) (*pipelineSpec, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		if b, err = yamlToJSON(b); err != nil {
+			return nil, fmt.Errorf("error reading pipeline spec %s: %v", path, err)
+		}
+	}
+	spec := &pipelineSpec{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	if err := d.Decode(spec); err != nil {
+		return nil, fmt.Errorf("error reading pipeline spec %s: %v", path, err)
+	}
+	if len(spec.Sources) == 0 {
+		return nil, fmt.Errorf("pipeline spec %s has no sources", path)
+	}
+	if len(spec.Emitters) == 0 {
+		return nil, fmt.Errorf("pipeline spec %s has no emitters", path)
+	}
+	switch spec.OnError {
+	case "", "stop", "continue":
+	default:
+		return nil, fmt.Errorf("invalid on_error %q: want stop or continue", spec.OnError)
+	}
+	return spec, nil
+}
+
+// yamlToJSON converts the YAML document b to JSON, so that YAML specs are
+// decoded and validated like JSON ones.
+func yamlToJSON(b []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// parseAction returns the tika.Action named s. The empty string is parse.
+func parseAction(s string) (tika.Action, error) {
+	for _, a := range []tika.Action{tika.ActionParse, tika.ActionRecursive, tika.ActionSkip} {
+		if s == a.String() {
+			return a, nil
+		}
+	}
+	if s == "" {
+		return tika.ActionParse, nil
+	}
+	return 0, fmt.Errorf("invalid action %q: want parse, recursive, or skip", s)
+}
+
+// pipeline builds the tika.Pipeline described by spec.
+func (spec *pipelineSpec) pipeline(c *tika.Client) (*tika.Pipeline, error) {
+	p := &tika.Pipeline{Client: c, Rules: map[string]tika.Rule{}}
+	a, err := parseAction(spec.Default)
+	if err != nil {
+		return nil, err
+	}
+	p.Default.Action = a
+	for mimeType, action := range spec.Rules {
+		a, err := parseAction(action)
+		if err != nil {
+			return nil, err
+		}
+		p.Rules[mimeType] = tika.Rule{Action: a}
+	}
+	if p.Timeouts, err = spec.timeouts(); err != nil {
+		return nil, err
+	}
+	if len(spec.Allow) > 0 || len(spec.Deny) > 0 {
+		p.Policy = &tika.TypePolicy{Allow: spec.Allow, Deny: spec.Deny, DetectPrefix: tika.DefaultDetectPrefix}
+	}
+	return p, nil
+}
+
+// timeouts parses the Timeouts of spec.
+func (spec *pipelineSpec) timeouts() (tika.TimeoutTable, error) {
+	if len(spec.Timeouts) == 0 {
+		return nil, nil
+	}
+	var name string
+	if json.Unmarshal(spec.Timeouts, &name) == nil {
+		if name != "default" {
+			return nil, fmt.Errorf("invalid timeouts %q: want default or an object", name)
+		}
+		return tika.DefaultTimeouts, nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal(spec.Timeouts, &m); err != nil {
+		return nil, fmt.Errorf("invalid timeouts: %v", err)
+	}
+	t := tika.TimeoutTable{}
+	for mimeType, s := range m {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout for %s: %v", mimeType, err)
+		}
+		t[mimeType] = d
+	}
+	return t, nil
+}
+
+// emitters opens the emitters of spec. The returned function flushes and
+// closes them.
+func (spec *pipelineSpec) emitters() (tika.Emitter, func() error, error) {
+	var (
+		es      []tika.Emitter
+		closers []func() error
+	)
+	closeAll := func() error {
+		var first error
+		for _, c := range closers {
+			if err := c(); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+	for _, e := range spec.Emitters {
+		switch e.Type {
+		case "files":
+			if e.Dir == "" {
+				closeAll()
+				return nil, nil, fmt.Errorf("files emitter has no dir")
+			}
+			es = append(es, &emit.Files{Dir: e.Dir, Ext: e.Ext})
+		case "ndjson":
+			var w io.Writer = os.Stdout
+			if e.Path != "" {
+				f, err := os.Create(e.Path)
+				if err != nil {
+					closeAll()
+					return nil, nil, err
+				}
+				closers = append(closers, f.Close)
+				w = f
+			}
+			es = append(es, emit.NewNDJSON(w))
+		case "elastic":
+			if e.URL == "" || e.Index == "" {
+				closeAll()
+				return nil, nil, fmt.Errorf("elastic emitter needs url and index")
+			}
+			ix := &elastic.Indexer{URL: e.URL, Index: e.Index}
+			closers = append(closers, func() error { return ix.Flush(context.Background()) })
+			es = append(es, ix)
+		default:
+			closeAll()
+			return nil, nil, fmt.Errorf("invalid emitter type %q: want files, ndjson, or elastic", e.Type)
+		}
+	}
+	return emit.Multi(es...), closeAll, nil
+}
+
+// inputs returns the inputs of every source of spec.
+func (spec *pipelineSpec) inputs(ctx context.Context) func(yield func(tika.Input) bool) {
+	return func(yield func(tika.Input) bool) {
+		for _, src := range spec.Sources {
+			if strings.Contains(src, "://") {
+				if !yield(source.Input(ctx, source.Default, src)) {
+					return
+				}
+				continue
+			}
+			if fi, err := os.Stat(src); err == nil && fi.IsDir() {
+				stopped := false
+				tika.Walk(src)(func(i tika.Input) bool {
+					stopped = !yield(i)
+					return !stopped
+				})
+				if stopped {
+					return
+				}
+				continue
+			}
+			if !yield(tika.FileInput(src)) {
+				return
+			}
+		}
+	}
+}
+
+// runPipeline runs the pipeline described by the spec at path and returns a
+// summary of the run.
+func runPipeline(ctx context.Context, c *tika.Client, path string) (string, error) {
+	spec, err := readPipelineSpec(path)
+	if err != nil {
+		return "", err
+	}
+	p, err := spec.pipeline(c)
+	if err != nil {
+		return "", err
+	}
+	e, closeEmitters, err := spec.emitters()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	workers := spec.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var (
+		mu                        sync.Mutex
+		firstErr                  error
+		processed, skipped, fails int
+	)
+	fail := func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fails++
+		if spec.OnError == "continue" {
+			log.Printf("%s: %v", name, err)
+			return
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", name, err)
+			cancel()
+		}
+	}
+
+	in := make(chan tika.Input)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for input := range in {
+				docs, err := processInput(ctx, p, input)
+				if err != nil {
+					fail(input.Name, err)
+					continue
+				}
+				mu.Lock()
+				if docs == nil {
+					skipped++
+				} else {
+					processed++
+				}
+				for _, d := range docs {
+					if err = e.Emit(ctx, d); err != nil {
+						break
+					}
+				}
+				mu.Unlock()
+				if err != nil {
+					fail(input.Name, err)
+				}
+			}
+		}()
+	}
+	spec.inputs(ctx)(func(i tika.Input) bool {
+		select {
+		case in <- i:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	close(in)
+	wg.Wait()
+
+	if err := closeEmitters(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if firstErr != nil {
+		return "", firstErr
+	}
+	summary := fmt.Sprintf("processed %d documents, skipped %d, failed %d", processed, skipped, fails)
+	if fails > 0 {
+		return "", fmt.Errorf("%w: %s", errPartial, summary)
+	}
+	return summary, nil
+}
+
+// processInput processes input with p, returning the documents to emit, or
+// nil if the input was skipped.
+func processInput(ctx context.Context, p *tika.Pipeline, input tika.Input) ([]tika.DocResult, error) {
+	rs, done, err := openSeeker(input)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	res, err := p.Process(ctx, rs)
+	if err != nil {
+		return nil, err
+	}
+	switch res.Action {
+	case tika.ActionSkip:
+		if res.Denied {
+			log.Printf("%s: skipped, type %s denied by policy", input.Name, res.MIMEType)
+		}
+		return nil, nil
+	case tika.ActionRecursive:
+		docs := make([]tika.DocResult, 0, len(res.Documents))
+		for _, m := range res.Documents {
+			docs = append(docs, tika.DocResult{
+				Name:     input.Name + m.Get(tika.XTIKAEmbeddedResourcePath),
+				Content:  m.Get(tika.XTIKAContent),
+				Metadata: m,
+			})
+		}
+		return docs, nil
+	}
+	return []tika.DocResult{{Name: input.Name, Content: res.Content}}, nil
+}
+
+// openSeeker opens input for reading and rewinding. Inputs that cannot seek,
+// such as objects in cloud storage or pipes, are spooled to a temporary file rather
+// than held in memory. The returned reader hides the Close method, as HTTP
+// requests close their body, and the returned function closes the input and
+// removes the temporary file.
+func openSeeker(input tika.Input) (io.ReadSeeker, func(), error) {
+	rc, err := input.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		if _, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			return struct{ io.ReadSeeker }{rs}, func() { rc.Close() }, nil
+		}
+	}
+	defer rc.Close()
+	f, err := ioutil.TempFile("", "tika-pipeline-")
+	if err != nil {
+		return nil, nil, err
+	}
+	done := func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		done()
+		return nil, nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		done()
+		return nil, nil, err
+	}
+	return struct{ io.ReadSeeker }{f}, done, nil
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2017 Google Inc. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-tika/tika"
+)
+
+// writeFile writes content to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadPipelineSpec(t *testing.T) {
+	dir := t.TempDir()
+	want := &pipelineSpec{
+		Sources:     []string{"docs/"},
+		Concurrency: 2,
+		Rules:       map[string]string{"video/*": "skip"},
+		Timeouts:    json.RawMessage(`{"image/*":"2m"}`),
+		Emitters:    []emitterSpec{{Type: "files", Dir: "out"}},
+		OnError:     "continue",
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    *pipelineSpec
+		wantErr string
+	}{
+		{
+			name:    "spec.json",
+			content: `{"sources":["docs/"],"concurrency":2,"rules":{"video/*":"skip"},"timeouts":{"image/*":"2m"},"emitters":[{"type":"files","dir":"out"}],"on_error":"continue"}`,
+			want:    want,
+		},
+		{
+			name: "spec.yaml",
+			content: `sources: [docs/]
+concurrency: 2
+rules:
+  video/*: skip
+timeouts:
+  image/*: 2m
+emitters:
+  - type: files
+    dir: out
+on_error: continue
+`,
+			want: want,
+		},
+		{name: "unknown.json", content: `{"sources":["a"],"emitters":[{"type":"files","dir":"o"}],"bogus":1}`, wantErr: "bogus"},
+		{name: "unknown.yml", content: "sources: [a]\nemitters: [{type: files, dir: o}]\nbogus: 1\n", wantErr: "bogus"},
+		{name: "bad.yaml", content: "sources: [a", wantErr: "error reading pipeline spec"},
+		{name: "nosources.json", content: `{"emitters":[{"type":"files","dir":"o"}]}`, wantErr: "no sources"},
+		{name: "noemitters.json", content: `{"sources":["a"]}`, wantErr: "no emitters"},
+		{name: "onerror.json", content: `{"sources":["a"],"emitters":[{"type":"files","dir":"o"}],"on_error":"retry"}`, wantErr: "invalid on_error"},
+	}
+	for _, test := range tests {
+		got, err := readPipelineSpec(writeFile(t, dir, test.name, test.content))
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("readPipelineSpec(%s) got error %v, want one containing %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("readPipelineSpec(%s) got error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("readPipelineSpec(%s) got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPipelineSpecTimeouts(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    tika.TimeoutTable
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: `"default"`, want: tika.DefaultTimeouts},
+		{in: `{"image/*":"2m","text/plain":"5s"}`, want: tika.TimeoutTable{"image/*": 2 * time.Minute, "text/plain": 5 * time.Second}},
+		{in: `"fast"`, wantErr: true},
+		{in: `{"image/*":"soon"}`, wantErr: true},
+		{in: `[1]`, wantErr: true},
+	}
+	for _, test := range tests {
+		spec := &pipelineSpec{Timeouts: json.RawMessage(test.in)}
+		got, err := spec.timeouts()
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("timeouts(%s) got error %v, want error %v", test.in, err, test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("timeouts(%s) got %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestPipelineSpecEmitters(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		emitters []emitterSpec
+		wantErr  bool
+	}{
+		{name: "files", emitters: []emitterSpec{{Type: "files", Dir: dir}}},
+		{name: "ndjson", emitters: []emitterSpec{{Type: "ndjson", Path: filepath.Join(dir, "out.ndjson")}}},
+		{name: "elastic", emitters: []emitterSpec{{Type: "elastic", URL: "http://localhost:9200", Index: "docs"}}},
+		{name: "files without dir", emitters: []emitterSpec{{Type: "files"}}, wantErr: true},
+		{name: "ndjson in missing dir", emitters: []emitterSpec{{Type: "ndjson", Path: filepath.Join(dir, "missing", "out.ndjson")}}, wantErr: true},
+		{name: "elastic without index", emitters: []emitterSpec{{Type: "elastic", URL: "http://localhost:9200"}}, wantErr: true},
+		{name: "unknown", emitters: []emitterSpec{{Type: "kafka"}}, wantErr: true},
+	}
+	for _, test := range tests {
+		spec := &pipelineSpec{Emitters: test.emitters}
+		e, closeAll, err := spec.emitters()
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("emitters(%s) got error %v, want error %v", test.name, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if e == nil {
+			t.Errorf("emitters(%s) got a nil Emitter", test.name)
+		}
+		if err := closeAll(); err != nil {
+			t.Errorf("emitters(%s) close got error: %v", test.name, err)
+		}
+	}
+}
+
+// pipelineServer returns a server detecting inputs starting with "fail" as
+// application/pdf, which it fails to parse, others starting with "hello" as
+// text/plain, and the rest as application/octet-stream. It parses text by
+// upper-casing it.
+func pipelineServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("error reading request: %v", err)
+		}
+		switch r.URL.Path {
+		case "/detect/stream":
+			switch {
+			case strings.HasPrefix(string(b), "fail"):
+				w.Write([]byte("application/pdf"))
+			case strings.HasPrefix(string(b), "hello"):
+				w.Write([]byte("text/plain"))
+			default:
+				w.Write([]byte("application/octet-stream"))
+			}
+		case "/tika":
+			if strings.HasPrefix(string(b), "fail") {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			w.Write([]byte(strings.ToUpper(string(b))))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestRunPipeline(t *testing.T) {
+	ts := pipelineServer(t)
+	defer ts.Close()
+	c := tika.NewClient(nil, ts.URL)
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		onError     string
+		wantSummary string
+		wantErr     error
+		wantOut     []string
+	}{
+		{
+			name:        "ok",
+			files:       map[string]string{"a.txt": "hello a", "b.bin": "\x00\x01"},
+			wantSummary: "processed 1 documents, skipped 1, failed 0",
+			wantOut:     []string{"HELLO A"},
+		},
+		{
+			name:    "continue",
+			files:   map[string]string{"a.txt": "hello a", "c.pdf": "fail"},
+			onError: "continue",
+			wantErr: errPartial,
+			wantOut: []string{"HELLO A"},
+		},
+		{
+			name:    "stop",
+			files:   map[string]string{"c.pdf": "fail"},
+			wantErr: tika.ErrUnprocessable,
+		},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		docs := filepath.Join(dir, "docs")
+		if err := os.Mkdir(docs, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range test.files {
+			writeFile(t, docs, name, content)
+		}
+		out := filepath.Join(dir, "out.ndjson")
+		spec := `sources: [` + docs + `]
+concurrency: 2
+rules: {application/octet-stream: skip}
+emitters: [{type: ndjson, path: ` + out + `}]
+on_error: ` + test.onError + `
+`
+		got, err := runPipeline(context.Background(), c, writeFile(t, dir, "spec.yaml", spec))
+		if test.wantErr != nil {
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("runPipeline(%s) got error %v, want %v", test.name, err, test.wantErr)
+			}
+		} else if err != nil {
+			t.Errorf("runPipeline(%s) got error: %v", test.name, err)
+		} else if got != test.wantSummary {
+			t.Errorf("runPipeline(%s) got %q, want %q", test.name, got, test.wantSummary)
+		}
+		b, err := ioutil.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(string(b), "\n"); lines != len(test.wantOut) {
+			t.Errorf("runPipeline(%s) emitted %d documents, want %d", test.name, lines, len(test.wantOut))
+		}
+		for _, s := range test.wantOut {
+			if !strings.Contains(string(b), s) {
+				t.Errorf("runPipeline(%s) output %q does not contain %q", test.name, b, s)
+			}
+		}
+	}
+}
+
+func TestOpenSeeker(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	path := writeFile(t, t.TempDir(), "a.txt", "hello")
+
+	tests := []struct {
+		name  string
+		input tika.Input
+	}{
+		{"file", tika.FileInput(path)},
+		{"stream", tika.Input{Name: "stream", Open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader("hello")), nil
+		}}},
+	}
+	for _, test := range tests {
+		rs, done, err := openSeeker(test.input)
+		if err != nil {
+			t.Fatalf("openSeeker(%s) got error: %v", test.name, err)
+		}
+		for i := 0; i < 2; i++ {
+			b, err := ioutil.ReadAll(rs)
+			if err != nil || string(b) != "hello" {
+				t.Errorf("openSeeker(%s) read %d got %q, %v, want %q", test.name, i, b, err, "hello")
+			}
+			if _, err := rs.Seek(0, io.SeekStart); err != nil {
+				t.Errorf("openSeeker(%s) Seek got error: %v", test.name, err)
+			}
+		}
+		done()
+		if fs, _ := ioutil.ReadDir(tmp); len(fs) != 0 {
+			t.Errorf("openSeeker(%s) left %d temporary files", test.name, len(fs))
+		}
+	}
+}
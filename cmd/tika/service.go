@@ -0,0 +1,122 @@
+/*
+Copyright 2017 Google Inc. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/google/go-tika/tika"
+)
+
+// Flags of the "server" action.
+var (
+	port        = flag.String("port", "9998", `Port of the server run by the "server" action.`)
+	pidFile     = flag.String("pid_file", "", `Path of the PID file written by the "server" action.`)
+	logFile     = flag.String("log_file", "", `Path of the log file receiving the output of the server run by the "server" action. It is rotated every -log_max_bytes, keeping -log_max_files old files.`)
+	logMaxBytes = flag.Int64("log_max_bytes", 64<<20, "Size at which -log_file is rotated.")
+	logMaxFiles = flag.Int("log_max_files", 5, "Number of rotated -log_file files to keep.")
+	maxRestarts = flag.Int("max_restarts", -1, `Number of times in a row the "server" action restarts a failing server. If negative, it is always restarted.`)
+	systemdUnit = flag.Bool("systemd_unit", false, `Print a systemd unit running the "server" action with the same flags instead of running it.`)
+)
+
+// runService runs the Tika Server at jar until the process receives SIGINT or
+// SIGTERM. The reloadSignals, such as SIGHUP, restart the Java process.
+func runService(jar string) error {
+	s, err := tika.NewServer(jar, *port)
+	if err != nil {
+		return err
+	}
+	opts := tika.ServiceOptions{PIDFile: *pidFile, MaxRestarts: *maxRestarts}
+	if *logFile != "" {
+		f := &tika.RotatingFile{Path: *logFile, MaxBytes: *logMaxBytes, MaxFiles: *logMaxFiles}
+		defer f.Close()
+		opts.Output = f
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	hup := make(chan os.Signal, 1)
+	if len(reloadSignals) > 0 {
+		signal.Notify(hup, reloadSignals...)
+		defer signal.Stop(hup)
+	}
+	restart := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-hup:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case restart <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	opts.Restart = restart
+	return s.RunService(ctx, opts)
+}
+
+// unit returns a systemd unit running the "server" action for jar.
+func unit(jar string) (string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if jar, err = filepath.Abs(jar); err != nil {
+		return "", err
+	}
+	args := []string{self, "-server_jar", jar, "-port", *port, "-max_restarts", fmt.Sprint(*maxRestarts)}
+	if *pidFile != "" {
+		args = append(args, "-pid_file", *pidFile)
+	}
+	if *logFile != "" {
+		args = append(args, "-log_file", *logFile, "-log_max_bytes", fmt.Sprint(*logMaxBytes), "-log_max_files", fmt.Sprint(*logMaxFiles))
+	}
+	args = append(args, serverAction)
+	for i, a := range args {
+		if strings.ContainsAny(a, " \t\"\\") {
+			args[i] = fmt.Sprintf("%q", a)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintln(&b, "[Unit]")
+	fmt.Fprintln(&b, "Description=Apache Tika Server")
+	fmt.Fprintln(&b, "After=network.target")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Service]")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(args, " "))
+	if *pidFile != "" {
+		fmt.Fprintf(&b, "PIDFile=%s\n", *pidFile)
+	}
+	// The command restarts the Java process itself; systemd restarts the
+	// command if it gives up.
+	fmt.Fprintln(&b, "ExecReload=/bin/kill -HUP $MAINPID")
+	fmt.Fprintln(&b, "Restart=on-failure")
+	fmt.Fprintln(&b, "KillSignal=SIGTERM")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "[Install]")
+	fmt.Fprintln(&b, "WantedBy=multi-user.target")
+	return b.String(), nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2017 Google Inc. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/go-tika/tika"
+)
+
+// Output formats of the meta action.
+const (
+	// formatAuto is formatTable when writing to a terminal and formatJSON
+	// otherwise.
+	formatAuto  = "auto"
+	formatTable = "table"
+	formatJSON  = "json"
+	// formatCSV is the raw CSV returned by the server.
+	formatCSV = "csv"
+)
+
+// isTerminal reports whether f is a terminal rather than a pipe or a file.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// metaFormat returns the output format of the meta action given by format,
+// resolving formatAuto.
+func metaFormat(format string) (string, error) {
+	switch format {
+	case formatAuto:
+		if isTerminal(os.Stdout) {
+			return formatTable, nil
+		}
+		return formatJSON, nil
+	case formatTable, formatJSON, formatCSV:
+		return format, nil
+	}
+	return "", fmt.Errorf("%w: invalid -meta_format %q, want %s, %s, %s, or %s", errUsage, format, formatAuto, formatTable, formatJSON, formatCSV)
+}
+
+// metaTable renders m as a table with a key column and a values column,
+// sorted by key, with multiple values joined by "; ".
+func metaTable(m tika.Metadata) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	// Values spanning lines or containing tabs would break the columns.
+	clean := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUES")
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%s\n", clean.Replace(k), clean.Replace(strings.Join(m[k], "; ")))
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// metaTables renders the metadata of each document returned by
+// MetaRecursive with metaTable, separated by a heading naming the document.
+func metaTables(docs []map[string][]string) string {
+	tables := make([]string, len(docs))
+	for i, d := range docs {
+		name := tika.Metadata(d).Get("X-TIKA:embedded_resource_path")
+		if name == "" {
+			name = "(container)"
+		}
+		tables[i] = fmt.Sprintf("# %d %s\n%s", i, name, metaTable(d))
+	}
+	return strings.Join(tables, "\n\n")
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2017 Google Inc. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/go-tika/tika"
+)
+
+// watchFile is the state of a file in the watched directory.
+type watchFile struct {
+	size    int64
+	modTime time.Time
+	// firstSeen is when this size and modification time were first seen.
+	firstSeen time.Time
+	// done is whether this version of the file was processed or given up
+	// on.
+	done     bool
+	attempts int
+	retryAt  time.Time
+}
+
+// watcher polls a directory, running an action on every file once it stops
+// changing and writing the result to a file in an output directory.
+type watcher struct {
+	c      *tika.Client
+	action string
+	dir    string
+	outDir string
+	// absOutDir is outDir made absolute, to skip it when it is inside dir.
+	absOutDir string
+	interval  time.Duration
+	debounce  time.Duration
+	retries   int
+
+	files             map[string]*watchFile
+	processed, failed int
+}
+
+// runWatch runs the -action on the files appearing in -dir until
+// interrupted. Directories are polled rather than subscribed to, which works
+// the same on every platform and on network file systems.
+func runWatch(ctx context.Context, c *tika.Client) (string, error) {
+	switch *watchActionFlag {
+	case parse, detect, language, meta:
+	default:
+		return "", fmt.Errorf("%w: invalid -action %q, want %s, %s, %s, or %s", errUsage, *watchActionFlag, parse, detect, language, meta)
+	}
+	if *pollInterval <= 0 {
+		return "", fmt.Errorf("%w: -poll_interval must be positive", errUsage)
+	}
+	if fi, err := os.Stat(*watchDir); err != nil {
+		return "", err
+	} else if !fi.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", *watchDir)
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return "", err
+	}
+	absOutDir, err := filepath.Abs(*outDir)
+	if err != nil {
+		return "", err
+	}
+	w := &watcher{
+		c:         c,
+		action:    *watchActionFlag,
+		dir:       *watchDir,
+		outDir:    *outDir,
+		absOutDir: absOutDir,
+		interval:  *pollInterval,
+		debounce:  *debounce,
+		retries:   *retries,
+		files:     map[string]*watchFile{},
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	log.Printf("watching %s", w.dir)
+	for {
+		w.poll(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			summary := fmt.Sprintf("processed %d files, %d failed", w.processed, w.failed)
+			if w.failed > 0 {
+				return "", fmt.Errorf("%w: %s", errPartial, summary)
+			}
+			return summary, nil
+		case <-t.C:
+		}
+	}
+}
+
+// poll scans the directory once, processing the files which have not
+// changed for the debounce period and are not done or waiting for a retry.
+func (w *watcher) poll(ctx context.Context, now time.Time) {
+	seen := map[string]bool{}
+	filepath.Walk(w.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			log.Printf("%s: %v", path, err)
+			return nil
+		}
+		// Skip hidden files, often partial downloads or editor swap files.
+		if path != w.dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if abs, err := filepath.Abs(path); err == nil && abs == w.absOutDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(w.dir, path)
+		if err != nil {
+			return nil
+		}
+		seen[rel] = true
+		f := w.files[rel]
+		if f == nil || f.size != info.Size() || !f.modTime.Equal(info.ModTime()) {
+			f = &watchFile{size: info.Size(), modTime: info.ModTime(), firstSeen: now}
+			w.files[rel] = f
+			// Skip files processed by an earlier run.
+			if out, err := os.Stat(w.outPath(rel)); err == nil && !out.ModTime().Before(info.ModTime()) {
+				f.done = true
+			}
+		}
+		if f.done || now.Sub(f.firstSeen) < w.debounce || now.Before(f.retryAt) || ctx.Err() != nil {
+			return nil
+		}
+		if err := w.process(ctx, path, rel); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			f.attempts++
+			if f.attempts > w.retries {
+				log.Printf("%s: giving up after %d attempts: %v", path, f.attempts, err)
+				f.done = true
+				w.failed++
+				return nil
+			}
+			delay := w.interval << uint(f.attempts)
+			log.Printf("%s: %v; retrying in %v", path, err, delay)
+			f.retryAt = now.Add(delay)
+			return nil
+		}
+		log.Printf("%s: wrote %s", path, w.outPath(rel))
+		f.done = true
+		w.processed++
+		return nil
+	})
+	// Forget removed files, so they are processed again if they reappear.
+	for rel := range w.files {
+		if !seen[rel] {
+			delete(w.files, rel)
+		}
+	}
+}
+
+// outPath is the path of the output of the file at rel in the directory.
+func (w *watcher) outPath(rel string) string {
+	ext := ".txt"
+	if w.action == meta {
+		ext = ".json"
+	}
+	return filepath.Join(w.outDir, rel+ext)
+}
+
+// process runs the action on the file at path and writes the result to its
+// output path, replacing it atomically.
+func (w *watcher) process(ctx context.Context, path, rel string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var out string
+	switch w.action {
+	case parse:
+		out, err = w.c.Parse(ctx, f)
+	case detect:
+		out, err = w.c.Detect(ctx, f)
+	case language:
+		out, err = w.c.Language(ctx, f)
+	case meta:
+		var m tika.Metadata
+		if m, err = w.c.Metadata(ctx, f); err == nil {
+			var b []byte
+			b, err = json.MarshalIndent(m, "", "  ")
+			out = string(b)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	dst := w.outPath(rel)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
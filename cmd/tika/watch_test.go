@@ -0,0 +1,195 @@
+/*
+Copyright 2017 Google Inc. All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-tika/tika"
+)
+
+// newTestWatcher returns a watcher parsing the files of a new directory with
+// a server that upper-cases them, failing for those starting with "bad". It
+// also returns the number of requests per file content.
+func newTestWatcher(t *testing.T) (*watcher, func(content string) int) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("error reading request: %v", err)
+		}
+		mu.Lock()
+		requests[string(b)]++
+		mu.Unlock()
+		if strings.HasPrefix(string(b), "bad") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.Write([]byte(strings.ToUpper(string(b))))
+	}))
+	t.Cleanup(ts.Close)
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	abs, err := filepath.Abs(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &watcher{
+		c:         tika.NewClient(nil, ts.URL),
+		action:    parse,
+		dir:       dir,
+		outDir:    out,
+		absOutDir: abs,
+		interval:  time.Second,
+		debounce:  5 * time.Second,
+		retries:   2,
+		files:     map[string]*watchFile{},
+	}
+	return w, func(content string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[content]
+	}
+}
+
+func TestWatcherDebounce(t *testing.T) {
+	w, requests := newTestWatcher(t)
+	ctx := context.Background()
+	now := time.Now()
+	path := writeFile(t, w.dir, "a.txt", "hello")
+
+	w.poll(ctx, now)
+	w.poll(ctx, now.Add(4*time.Second))
+	if got := requests("hello"); got != 0 {
+		t.Fatalf("poll before the debounce period sent %d requests, want 0", got)
+	}
+	// A change restarts the debounce period.
+	if err := ioutil.WriteFile(path, []byte("hello, world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w.poll(ctx, now.Add(5*time.Second))
+	if got := requests("hello, world"); got != 0 {
+		t.Fatalf("poll after a change sent %d requests, want 0", got)
+	}
+	w.poll(ctx, now.Add(10*time.Second))
+	if got := requests("hello, world"); got != 1 {
+		t.Fatalf("poll after the debounce period sent %d requests, want 1", got)
+	}
+	b, err := ioutil.ReadFile(w.outPath("a.txt"))
+	if err != nil {
+		t.Fatalf("poll did not write the output: %v", err)
+	}
+	if got, want := string(b), "HELLO, WORLD"; got != want {
+		t.Errorf("poll wrote %q, want %q", got, want)
+	}
+	w.poll(ctx, now.Add(20*time.Second))
+	if got := requests("hello, world"); got != 1 {
+		t.Errorf("poll of a processed file sent %d requests, want 1", got)
+	}
+	if w.processed != 1 || w.failed != 0 {
+		t.Errorf("poll got %d processed and %d failed, want 1 and 0", w.processed, w.failed)
+	}
+}
+
+func TestWatcherRetry(t *testing.T) {
+	w, requests := newTestWatcher(t)
+	ctx := context.Background()
+	now := time.Now()
+	writeFile(t, w.dir, "bad.txt", "bad")
+
+	// The first attempt fails, and the retries wait 2s, then 4s.
+	for _, test := range []struct {
+		after time.Duration
+		want  int
+	}{
+		{0, 0},
+		{5 * time.Second, 1},
+		{6 * time.Second, 1},
+		{7 * time.Second, 2},
+		{10 * time.Second, 2},
+		{11 * time.Second, 3},
+		{60 * time.Second, 3},
+	} {
+		w.poll(ctx, now.Add(test.after))
+		if got := requests("bad"); got != test.want {
+			t.Errorf("poll after %v sent %d requests, want %d", test.after, got, test.want)
+		}
+	}
+	if w.processed != 0 || w.failed != 1 {
+		t.Errorf("poll got %d processed and %d failed, want 0 and 1", w.processed, w.failed)
+	}
+	if _, err := os.Stat(w.outPath("bad.txt")); !os.IsNotExist(err) {
+		t.Errorf("poll wrote the output of a failed file: %v", err)
+	}
+}
+
+func TestWatcherSkip(t *testing.T) {
+	w, requests := newTestWatcher(t)
+	ctx := context.Background()
+	now := time.Now()
+	old := now.Add(-time.Hour)
+
+	// done.txt has an up to date output from an earlier run, stale.txt an
+	// out of date one.
+	for _, name := range []string{"done.txt", "stale.txt"} {
+		path := writeFile(t, w.dir, name, name)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(w.outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, w.outDir, "done.txt.txt", "DONE.TXT")
+	stale := writeFile(t, w.outDir, "stale.txt.txt", "old")
+	if err := os.Chtimes(stale, old.Add(-time.Hour), old.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, w.dir, ".partial", "partial")
+
+	w.poll(ctx, now)
+	w.poll(ctx, now.Add(w.debounce))
+	for _, test := range []struct {
+		content string
+		want    int
+	}{
+		{"done.txt", 0},
+		{"stale.txt", 1},
+		{"partial", 0},
+		{"DONE.TXT", 0},
+		{"old", 0},
+	} {
+		if got := requests(test.content); got != test.want {
+			t.Errorf("poll sent %d requests for %q, want %d", got, test.content, test.want)
+		}
+	}
+	if w.processed != 1 {
+		t.Errorf("poll processed %d files, want 1", w.processed)
+	}
+}
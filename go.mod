@@ -2,4 +2,7 @@ module github.com/google/go-tika
 
 go 1.11
 
-require golang.org/x/net v0.23.0
+require (
+	golang.org/x/text v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,162 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AdaptiveConcurrency tunes the number of concurrent requests of a Batch
+// to what the server sustains, using additive increase and multiplicative
+// decrease (AIMD), like TCP congestion control: the limit grows by about one
+// request each time a full limit's worth of requests succeeds, and is cut by
+// Backoff when the server shows signs of overload. This avoids tuning
+// Batch.Workers by hand for mixes of small and large documents.
+//
+// The signs of overload are errors matching ErrServerUnavailable, 429 Too
+// Many Requests responses, inputs skipped with SkipTimeout, and, if
+// TargetLatency is set, parses slower than TargetLatency plus LatencyPerMB
+// for every megabyte of the input's Size.
+//
+// An AdaptiveConcurrency holds the state of the limit, so it can be shared
+// by successive runs, or batches using the same server. It must not be
+// copied after first use.
+type AdaptiveConcurrency struct {
+	// Min is the lowest limit, and the one to start from. If Min is less
+	// than 1, it is 1.
+	Min int
+	// Max is the highest limit. If Max is less than or equal to 0,
+	// Batch.Workers is used.
+	Max int
+	// TargetLatency, if greater than 0, is the parse time above which the
+	// server is considered overloaded.
+	TargetLatency time.Duration
+	// LatencyPerMB is added to TargetLatency for every megabyte of the
+	// Size of an input, so large inputs are allowed more time.
+	LatencyPerMB time.Duration
+	// Backoff is the factor the limit is multiplied by on overload. If it
+	// is not between 0 and 1, 0.5 is used.
+	Backoff float64
+
+	mu       sync.Mutex
+	wake     chan struct{} // wake is closed when a request ends.
+	limit    float64
+	inflight int
+	cut      time.Time // cut is when the limit was last decreased.
+}
+
+// Limit returns the current limit on concurrent requests.
+func (a *AdaptiveConcurrency) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int(a.current(0))
+}
+
+// current returns the limit, initializing it if needed. max is the maximum
+// limit to apply if Max is not set. a.mu must be held.
+func (a *AdaptiveConcurrency) current(max int) float64 {
+	min := float64(a.Min)
+	if min < 1 {
+		min = 1
+	}
+	if a.Max > 0 {
+		max = a.Max
+	}
+	if a.limit < min {
+		a.limit = min
+	}
+	if max > 0 && a.limit > float64(max) {
+		a.limit = float64(max)
+	}
+	return a.limit
+}
+
+// acquire waits until a request can be started under the limit, or ctx is
+// done. It returns when the request started.
+func (a *AdaptiveConcurrency) acquire(ctx context.Context, max int) (time.Time, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.inflight >= int(a.current(max)) {
+		if a.wake == nil {
+			a.wake = make(chan struct{})
+		}
+		wake := a.wake
+		a.mu.Unlock()
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			a.mu.Lock()
+			return time.Time{}, ctx.Err()
+		}
+		a.mu.Lock()
+	}
+	a.inflight++
+	return time.Now(), nil
+}
+
+// release ends a request started at start, adjusting the limit according to
+// its outcome.
+func (a *AdaptiveConcurrency) release(start time.Time, size int64, r Result, err error, max int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inflight--
+	if a.wake != nil {
+		close(a.wake)
+		a.wake = nil
+	}
+	limit := a.current(max)
+	switch {
+	case a.overloaded(time.Since(start), size, r, err):
+		// Requests started before the last cut saw the old limit, so they
+		// do not cut it again.
+		if start.Before(a.cut) {
+			return
+		}
+		backoff := a.Backoff
+		if backoff <= 0 || backoff >= 1 {
+			backoff = 0.5
+		}
+		a.limit = limit * backoff
+		a.cut = time.Now()
+	case err == nil && !r.Skipped:
+		a.limit = limit + 1/limit
+	}
+	a.current(max)
+}
+
+// overloaded reports whether the outcome of a request shows the server is
+// overloaded.
+func (a *AdaptiveConcurrency) overloaded(elapsed time.Duration, size int64, r Result, err error) bool {
+	var ce ClientError
+	switch {
+	case errors.Is(err, ErrServerUnavailable),
+		errors.As(err, &ce) && ce.StatusCode == http.StatusTooManyRequests,
+		r.Skipped && r.SkipReason == SkipTimeout:
+		return true
+	case err != nil:
+		return false
+	}
+	if a.TargetLatency <= 0 {
+		return false
+	}
+	target := a.TargetLatency + time.Duration(float64(a.LatencyPerMB)*float64(size)/(1<<20))
+	return elapsed > target
+}
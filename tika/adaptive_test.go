@@ -0,0 +1,143 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveConcurrencyAIMD(t *testing.T) {
+	a := &AdaptiveConcurrency{Max: 4}
+	if got := a.Limit(); got != 1 {
+		t.Errorf("initial Limit() = %d, want 1", got)
+	}
+	for i := 0; i < 20; i++ {
+		start, err := a.acquire(context.Background(), 8)
+		if err != nil {
+			t.Fatalf("acquire got error: %v", err)
+		}
+		a.release(start, 0, Result{}, nil, 8)
+	}
+	if got := a.Limit(); got != 4 {
+		t.Errorf("Limit() after successes = %d, want Max 4", got)
+	}
+
+	before := time.Now().Add(-time.Millisecond)
+	a.release(time.Now(), 0, Result{}, ClientError{StatusCode: http.StatusServiceUnavailable}, 8)
+	if got := a.Limit(); got != 2 {
+		t.Errorf("Limit() after overload = %d, want 2", got)
+	}
+	a.release(before, 0, Result{Skipped: true, SkipReason: SkipTimeout}, nil, 8)
+	if got := a.Limit(); got != 2 {
+		t.Errorf("Limit() after overload of a request started before the cut = %d, want 2", got)
+	}
+	a.release(time.Now(), 0, Result{}, ClientError{StatusCode: http.StatusTooManyRequests}, 8)
+	a.release(time.Now(), 0, Result{}, ClientError{StatusCode: http.StatusTooManyRequests}, 8)
+	if got := a.Limit(); got != 1 {
+		t.Errorf("Limit() after repeated overload = %d, want Min 1", got)
+	}
+	a.release(time.Now(), 0, Result{}, ClientError{StatusCode: http.StatusUnprocessableEntity}, 8)
+	if got := a.Limit(); got != 1 {
+		t.Errorf("Limit() after a parse error = %d, want 1", got)
+	}
+	a.inflight = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := a.acquire(ctx, 8); err != nil {
+		t.Fatalf("acquire got error: %v", err)
+	}
+	cancel()
+	if _, err := a.acquire(ctx, 8); err != context.Canceled {
+		t.Errorf("acquire over the limit with a canceled context got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestAdaptiveConcurrencyLatency(t *testing.T) {
+	a := &AdaptiveConcurrency{TargetLatency: time.Second, LatencyPerMB: time.Second}
+	tests := []struct {
+		elapsed time.Duration
+		size    int64
+		want    bool
+	}{
+		{elapsed: 500 * time.Millisecond, want: false},
+		{elapsed: 2 * time.Second, want: true},
+		{elapsed: 2 * time.Second, size: 2 << 20, want: false},
+		{elapsed: 4 * time.Second, size: 2 << 20, want: true},
+	}
+	for _, test := range tests {
+		if got := a.overloaded(test.elapsed, test.size, Result{}, nil); got != test.want {
+			t.Errorf("overloaded(%v, %d) = %v, want %v", test.elapsed, test.size, got, test.want)
+		}
+	}
+}
+
+func TestBatchAdaptive(t *testing.T) {
+	// The server rejects requests beyond 2 at a time.
+	var active, peak int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&active, 1)
+		defer atomic.AddInt64(&active, -1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		if n > 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		io.Copy(w, r.Body)
+	}))
+	defer ts.Close()
+
+	a := &AdaptiveConcurrency{}
+	b := &Batch{Client: NewClient(nil, ts.URL), Workers: 16, Adaptive: a}
+	var inputs []Input
+	for i := 0; i < 100; i++ {
+		inputs = append(inputs, stringInput(fmt.Sprint(i), "x"))
+	}
+	var parsed, rejected int
+	err := b.Run(context.Background(), Inputs(inputs...), func(r Result, err error) bool {
+		if err != nil {
+			rejected++
+		} else {
+			parsed++
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Run got error: %v", err)
+	}
+	if parsed == 0 {
+		t.Error("Run parsed no input")
+	}
+	if rejected > len(inputs)/4 {
+		t.Errorf("Run got %d inputs rejected for overload, want few", rejected)
+	}
+	if peak > 8 || a.Limit() > 4 {
+		t.Errorf("Run reached %d concurrent requests and ended with Limit() = %d, want the limit kept near 2", peak, a.Limit())
+	}
+}
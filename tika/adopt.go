@@ -0,0 +1,110 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Adopt creates a Server for a Tika Server that was started by someone else,
+// for example an init system in a container, listening at urlString. If pid
+// is greater than 0, it is the process ID of the Java process, which allows
+// Stop and Shutdown to stop it.
+//
+// Start on an adopted Server does not launch anything: it only waits for the
+// server to be ready. Configuration methods such as ChildMode and JavaProps
+// have no effect.
+func Adopt(urlString string, pid int) (*Server, error) {
+	u, err := url.Parse(urlString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %v", urlString, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: want http(s)://host[:port]", urlString)
+	}
+	s := &Server{
+		url:       strings.TrimSuffix(u.String(), "/"),
+		port:      u.Port(),
+		adopted:   true,
+		JavaProps: map[string]string{},
+	}
+	if pid > 0 {
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			return nil, fmt.Errorf("could not find process %d: %v", pid, err)
+		}
+		s.proc = p
+	}
+	return s, nil
+}
+
+// stopAdopted stops the process of an adopted server using stop.
+func (s *Server) stopAdopted(stop func(*os.Process) error) error {
+	if s.proc == nil {
+		return fmt.Errorf("adopted server has no process ID, cannot stop it")
+	}
+	if err := stop(s.proc); err != nil {
+		return fmt.Errorf("could not stop server: %v", err)
+	}
+	return nil
+}
+
+// shutdownAdopted interrupts the process of an adopted server and waits for
+// the server to stop responding, killing the process if ctx is done first.
+// The process is not a child, so it cannot be waited for directly.
+func (s *Server) shutdownAdopted(ctx context.Context) error {
+	if err := s.stopAdopted(interruptProcess); err != nil {
+		return err
+	}
+	c := NewClient(nil, s.url)
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if _, err := c.Version(ctx); err != nil && ctx.Err() == nil {
+				return nil
+			}
+		case <-ctx.Done():
+			return s.stopAdopted(killProcess)
+		}
+	}
+}
+
+// Supervise probes the server every interval until ctx is done, calling
+// onFailure with the error of every probe that fails. Supervise works for
+// both started and adopted servers, and returns ctx.Err().
+func (s *Server) Supervise(ctx context.Context, interval time.Duration, onFailure func(error)) error {
+	c := NewClient(nil, s.url)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if _, err := c.Version(ctx); err != nil && ctx.Err() == nil {
+				onFailure(err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestAdopt(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "1.14")
+	}))
+	defer ts.Close()
+
+	s, err := Adopt(ts.URL+"/", 0)
+	if err != nil {
+		t.Fatalf("Adopt got error: %v", err)
+	}
+	if s.URL() != ts.URL {
+		t.Errorf("URL() = %q, want %q", s.URL(), ts.URL)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Start(ctx); err != nil {
+		t.Errorf("Start of an adopted server got error: %v", err)
+	}
+	if err := s.Stop(); err == nil {
+		t.Error("Stop of an adopted server without a pid got no error")
+	}
+}
+
+func TestAdoptError(t *testing.T) {
+	for _, u := range []string{"", "localhost:9998", "ftp://localhost", "http://%31"} {
+		if _, err := Adopt(u, 0); err == nil {
+			t.Errorf("Adopt(%q) got no error", u)
+		}
+	}
+}
+
+func TestAdoptStop(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep", "10")
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("could not start helper process: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+
+	s, err := Adopt("http://localhost:9998", cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("Adopt got error: %v", err)
+	}
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop got error: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("Stop did not kill the adopted process")
+	}
+}
+
+func TestSupervise(t *testing.T) {
+	ts := errorServer
+	s, err := Adopt(ts.URL, 0)
+	if err != nil {
+		t.Fatalf("Adopt got error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	failures := 0
+	err = s.Supervise(ctx, 10*time.Millisecond, func(err error) {
+		failures++
+		if failures == 3 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Errorf("Supervise got error %v, want %v", err, context.Canceled)
+	}
+	if failures != 3 {
+		t.Errorf("Supervise reported %d failures, want 3", failures)
+	}
+}
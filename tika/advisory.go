@@ -0,0 +1,219 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Severity is the severity of an Advisory.
+type Severity int
+
+// Severities, from least to most severe.
+const (
+	SeverityLow Severity = iota
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// A VersionRange is a range of versions, from From (inclusive) to Fixed
+// (exclusive). An empty From matches every version before Fixed.
+type VersionRange struct {
+	From  Version
+	Fixed Version
+}
+
+// Contains reports whether v is in r.
+func (r VersionRange) Contains(v Version) bool {
+	return (r.From == "" || compareVersions(string(v), string(r.From)) >= 0) &&
+		compareVersions(string(v), string(r.Fixed)) < 0
+}
+
+// An Advisory is a known security vulnerability of Tika.
+type Advisory struct {
+	// ID is the CVE identifier, such as "CVE-2018-1335".
+	ID       string
+	Severity Severity
+	Summary  string
+	// Affected are the affected versions.
+	Affected []VersionRange
+}
+
+// Affects reports whether a affects version v.
+func (a Advisory) Affects(v Version) bool {
+	for _, r := range a.Affected {
+		if r.Contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a Advisory) String() string {
+	return fmt.Sprintf("%s (%v): %s", a.ID, a.Severity, a.Summary)
+}
+
+// Advisories is the table of known advisories used by CheckVersion. It is
+// not exhaustive; see https://tika.apache.org/security.html for the complete
+// list. Programs may replace or extend it.
+var Advisories = []Advisory{
+	{
+		ID:       "CVE-2015-3271",
+		Severity: SeverityHigh,
+		Summary:  "tika-server can be made to read arbitrary local files",
+		Affected: []VersionRange{{From: "1.9", Fixed: "1.10"}},
+	},
+	{
+		ID:       "CVE-2016-6809",
+		Severity: SeverityCritical,
+		Summary:  "Java deserialization in the MATLAB parser allows remote code execution",
+		Affected: []VersionRange{{From: "1.6", Fixed: "1.14"}},
+	},
+	{
+		ID:       "CVE-2018-1335",
+		Severity: SeverityCritical,
+		Summary:  "headers sent to tika-server allow command injection with Tesseract OCR",
+		Affected: []VersionRange{{From: "1.7", Fixed: "1.18"}},
+	},
+	{
+		ID:       "CVE-2018-11761",
+		Severity: SeverityMedium,
+		Summary:  "XML entity expansion in XML parsers allows denial of service",
+		Affected: []VersionRange{{Fixed: "1.19"}},
+	},
+	{
+		ID:       "CVE-2018-17197",
+		Severity: SeverityMedium,
+		Summary:  "a crafted SQLite file causes an infinite loop",
+		Affected: []VersionRange{{From: "1.8", Fixed: "1.20"}},
+	},
+	{
+		ID:       "CVE-2019-10088",
+		Severity: SeverityHigh,
+		Summary:  "a crafted ZIP file causes out of memory errors in the recursive parser",
+		Affected: []VersionRange{{From: "1.7", Fixed: "1.22"}},
+	},
+	{
+		ID:       "CVE-2020-1950",
+		Severity: SeverityMedium,
+		Summary:  "a crafted PSD file causes excessive memory use",
+		Affected: []VersionRange{{Fixed: "1.24"}},
+	},
+	{
+		ID:       "CVE-2021-28657",
+		Severity: SeverityMedium,
+		Summary:  "a crafted MP3 file causes an infinite loop",
+		Affected: []VersionRange{{Fixed: "1.26"}},
+	},
+	{
+		ID:       "CVE-2022-30126",
+		Severity: SeverityHigh,
+		Summary:  "a regular expression in the StandardsText handler allows denial of service",
+		Affected: []VersionRange{{Fixed: "1.28.3"}, {From: "2.0.0", Fixed: "2.4.0"}},
+	},
+	{
+		ID:       "CVE-2025-54988",
+		Severity: SeverityCritical,
+		Summary:  "XML external entities in XFA forms of PDF files allow reading files and server-side request forgery",
+		Affected: []VersionRange{{From: "1.13", Fixed: "3.2.2"}},
+	},
+}
+
+// CheckVersion returns the Advisories affecting version v, most severe first.
+func CheckVersion(v Version) []Advisory {
+	var as []Advisory
+	for _, a := range Advisories {
+		if a.Affects(v) {
+			as = append(as, a)
+		}
+	}
+	// Insertion sort keeps the table order within a severity.
+	for i := 1; i < len(as); i++ {
+		for j := i; j > 0 && as[j].Severity > as[j-1].Severity; j-- {
+			as[j], as[j-1] = as[j-1], as[j]
+		}
+	}
+	return as
+}
+
+// AdvisoryPolicy controls what a Downloader does when the version it
+// downloads has known critical advisories.
+type AdvisoryPolicy int
+
+const (
+	// AdvisoryWarn logs the critical advisories and downloads the version.
+	AdvisoryWarn AdvisoryPolicy = iota
+	// AdvisoryRefuse returns an *AdvisoryError without downloading.
+	AdvisoryRefuse
+	// AdvisoryIgnore downloads the version silently.
+	AdvisoryIgnore
+)
+
+// An AdvisoryError is returned by a Downloader with the AdvisoryRefuse policy
+// for a version with critical advisories.
+type AdvisoryError struct {
+	Version    Version
+	Advisories []Advisory
+}
+
+func (e *AdvisoryError) Error() string {
+	ids := make([]string, len(e.Advisories))
+	for i, a := range e.Advisories {
+		ids[i] = a.ID
+	}
+	return fmt.Sprintf("Tika %s has critical vulnerabilities: %s", e.Version, strings.Join(ids, ", "))
+}
+
+// checkAdvisories applies policy to the critical advisories of v.
+func checkAdvisories(v Version, policy AdvisoryPolicy) error {
+	if policy == AdvisoryIgnore {
+		return nil
+	}
+	var critical []Advisory
+	for _, a := range CheckVersion(v) {
+		if a.Severity == SeverityCritical {
+			critical = append(critical, a)
+		}
+	}
+	if len(critical) == 0 {
+		return nil
+	}
+	if policy == AdvisoryRefuse {
+		return &AdvisoryError{Version: v, Advisories: critical}
+	}
+	for _, a := range critical {
+		log.Printf("warning: Tika %s: %v", v, a)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func advisoryIDs(as []Advisory) []string {
+	var ids []string
+	for _, a := range as {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func TestCheckVersion(t *testing.T) {
+	old := Advisories
+	defer func() { Advisories = old }()
+	Advisories = []Advisory{
+		{ID: "low", Severity: SeverityLow, Affected: []VersionRange{{Fixed: "1.20"}}},
+		{ID: "critical", Severity: SeverityCritical, Affected: []VersionRange{{From: "1.10", Fixed: "1.18"}}},
+		{ID: "split", Severity: SeverityHigh, Affected: []VersionRange{{Fixed: "1.28.3"}, {From: "2.0.0", Fixed: "2.4.0"}}},
+	}
+	tests := []struct {
+		v    Version
+		want []string
+	}{
+		{"1.14", []string{"critical", "split", "low"}},
+		{"1.18", []string{"split", "low"}},
+		{"1.28.3", nil},
+		{"2.3.0", []string{"split"}},
+		{"2.4.0", nil},
+	}
+	for _, test := range tests {
+		if got := advisoryIDs(CheckVersion(test.v)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("CheckVersion(%q) = %v, want %v", test.v, got, test.want)
+		}
+	}
+}
+
+func TestCheckVersionDefaultTable(t *testing.T) {
+	as := CheckVersion("1.14")
+	if len(as) == 0 || as[0].Severity != SeverityCritical {
+		t.Errorf("CheckVersion(1.14) = %v, want a critical advisory first", advisoryIDs(as))
+	}
+}
+
+func TestDownloaderAdvisoryRefuse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("Download contacted the mirror, want it to refuse first")
+	}))
+	defer ts.Close()
+	old := Advisories
+	defer func() { Advisories = old }()
+	Advisories = []Advisory{{ID: "CVE-0", Severity: SeverityCritical, Affected: []VersionRange{{Fixed: "2.0"}}}}
+
+	d := &Downloader{Mirrors: []string{ts.URL + "/{version}"}, AdvisoryPolicy: AdvisoryRefuse}
+	err := d.Download(context.Background(), Version119, filepath.Join(t.TempDir(), "tika.jar"))
+	var aerr *AdvisoryError
+	if !errors.As(err, &aerr) {
+		t.Fatalf("Download got error %v, want an *AdvisoryError", err)
+	}
+	if got := advisoryIDs(aerr.Advisories); !reflect.DeepEqual(got, []string{"CVE-0"}) {
+		t.Errorf("Download refused for %v, want [CVE-0]", got)
+	}
+}
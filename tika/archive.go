@@ -0,0 +1,120 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// An ArchiveResult is the result of parsing one Input with ParseArchive.
+type ArchiveResult struct {
+	// Name is the Name of the Input.
+	Name string
+	// Documents are the metadata and content of the Input, followed by those
+	// of its embedded documents, as returned by MetaRecursive. Documents is
+	// empty if the server returned nothing for the Input, for example because
+	// it could not be parsed.
+	Documents []Metadata
+}
+
+// ParseArchive parses all of inputs in a single request, to amortize the
+// per-request overhead when parsing many small files. The inputs are streamed
+// to the server as the entries of a ZIP archive, and the recursive results are
+// demultiplexed by embedded resource path. The result for inputs[i] is at
+// index i. contentType is as for MetaRecursiveType.
+//
+// Each entry is named by its index and the extension of the Input's Name, so
+// the server's detection can still use the extension.
+func (c *Client) ParseArchive(ctx context.Context, inputs []Input, contentType string) ([]ArchiveResult, error) {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(writeArchive(pw, inputs))
+	}()
+
+	results := make([]ArchiveResult, len(inputs))
+	for i, in := range inputs {
+		results[i].Name = in.Name
+	}
+	first := true
+	err := c.MetaRecursiveStream(ctx, pr, contentType, func(m Metadata) error {
+		if first {
+			// The archive itself.
+			first = false
+			return nil
+		}
+		if i, ok := archiveIndex(m.Get(XTIKAEmbeddedResourcePath)); ok && i < len(results) {
+			results[i].Documents = append(results[i].Documents, m)
+		}
+		return nil
+	})
+	pr.CloseWithError(io.ErrClosedPipe)
+	<-done
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// writeArchive writes inputs to w as a ZIP archive.
+func writeArchive(w io.Writer, inputs []Input) error {
+	zw := zip.NewWriter(w)
+	for i, in := range inputs {
+		if err := writeArchiveEntry(zw, archiveName(i, in.Name), in); err != nil {
+			return fmt.Errorf("%s: %v", in.Name, err)
+		}
+	}
+	return zw.Close()
+}
+
+func writeArchiveEntry(zw *zip.Writer, name string, in Input) error {
+	r, err := in.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// archiveName returns the name of the i-th entry of an archive written by
+// ParseArchive.
+func archiveName(i int, name string) string {
+	return strconv.Itoa(i) + path.Ext(name)
+}
+
+// archiveIndex returns the index of the entry an embedded resource path
+// belongs to, such as 3 for "/3.pdf" or "/3.pdf/image0.png".
+func archiveIndex(resourcePath string) (int, bool) {
+	s := strings.TrimPrefix(resourcePath, "/")
+	if j := strings.IndexAny(s, "./"); j >= 0 {
+		s = s[:j]
+	}
+	i, err := strconv.Atoi(s)
+	return i, err == nil && i >= 0
+}
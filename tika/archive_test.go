@@ -0,0 +1,122 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// archiveServer lists the entries of a ZIP archive like /rmeta/text, adding
+// an embedded document to entries named *.zip.
+func archiveServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			// The client aborted the request.
+			return
+		}
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			t.Errorf("server got an invalid archive: %v", err)
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		docs := []Metadata{{XTIKAContent: {""}}}
+		for _, f := range zr.File {
+			rc, _ := f.Open()
+			content, _ := ioutil.ReadAll(rc)
+			rc.Close()
+			p := "/" + f.Name
+			docs = append(docs, Metadata{XTIKAContent: {string(content)}, XTIKAEmbeddedResourcePath: {p}})
+			if strings.HasSuffix(f.Name, ".zip") {
+				docs = append(docs, Metadata{XTIKAContent: {"inner"}, XTIKAEmbeddedResourcePath: {p + "/inner.txt"}})
+			}
+		}
+		json.NewEncoder(w).Encode(docs)
+	}))
+}
+
+func TestParseArchive(t *testing.T) {
+	ts := archiveServer(t)
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	inputs := []Input{
+		stringInput("a.txt", "first"),
+		stringInput("dir/b.zip", "second"),
+		stringInput("c", "third"),
+	}
+	got, err := c.ParseArchive(context.Background(), inputs, "text")
+	if err != nil {
+		t.Fatalf("ParseArchive got error: %v", err)
+	}
+	want := []ArchiveResult{
+		{Name: "a.txt", Documents: []Metadata{{XTIKAContent: {"first"}, XTIKAEmbeddedResourcePath: {"/0.txt"}}}},
+		{Name: "dir/b.zip", Documents: []Metadata{
+			{XTIKAContent: {"second"}, XTIKAEmbeddedResourcePath: {"/1.zip"}},
+			{XTIKAContent: {"inner"}, XTIKAEmbeddedResourcePath: {"/1.zip/inner.txt"}},
+		}},
+		{Name: "c", Documents: []Metadata{{XTIKAContent: {"third"}, XTIKAEmbeddedResourcePath: {"/2"}}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseArchive got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseArchiveOpenError(t *testing.T) {
+	ts := archiveServer(t)
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	openErr := errors.New("open failed")
+	inputs := []Input{
+		stringInput("a.txt", "first"),
+		{Name: "b.txt", Open: func() (io.ReadCloser, error) { return nil, openErr }},
+	}
+	if _, err := c.ParseArchive(context.Background(), inputs, "text"); err == nil {
+		t.Error("ParseArchive got no error, want an error")
+	}
+}
+
+func TestArchiveIndex(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"/3.pdf", 3, true},
+		{"/3", 3, true},
+		{"/12.tar.gz/a/b.txt", 12, true},
+		{"/x.txt", 0, false},
+		{"", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := archiveIndex(test.in)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("archiveIndex(%q) = %d, %v, want %d, %v", test.in, got, ok, test.want, test.wantOK)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrSuspectedArchiveBomb is returned, wrapped, for ZIP inputs exceeding the
+// ArchiveLimits of a Client, which are never sent to the server.
+var ErrSuspectedArchiveBomb = errors.New("suspected archive bomb")
+
+// MaxEmbeddedResourcesHeader is the header limiting the number of embedded
+// documents Tika Server 2 and later returns from the recursive metadata
+// endpoint.
+const MaxEmbeddedResourcesHeader = "maxEmbeddedResources"
+
+// ArchiveLimits protect a shared server from archive bombs: small archives
+// expanding to huge or deeply nested contents. Zero fields are not limits.
+type ArchiveLimits struct {
+	// MaxRatio is the maximum ratio of uncompressed to compressed size of
+	// an archive, and of each of its entries.
+	MaxRatio float64
+	// MaxDepth is the maximum nesting of archives in archives; 1 allows
+	// archives containing no archive.
+	MaxDepth int
+	// MaxEntries is the maximum number of entries of an archive, counting
+	// those of nested archives.
+	MaxEntries int
+	// MaxUncompressedBytes is the maximum uncompressed size of an archive,
+	// counting nested archives once, as their contents.
+	MaxUncompressedBytes int64
+	// MaxEmbeddedResources is sent to the server with the
+	// MaxEmbeddedResourcesHeader of recursive requests, so that servers
+	// supporting it stop after that many embedded documents.
+	MaxEmbeddedResources int
+}
+
+// DefaultArchiveLimits are limits suitable for most servers.
+var DefaultArchiveLimits = ArchiveLimits{
+	MaxRatio:             100,
+	MaxDepth:             10,
+	MaxEntries:           10000,
+	MaxUncompressedBytes: 1 << 30,
+	MaxEmbeddedResources: 10000,
+}
+
+// maxNestedArchive is the maximum size of a nested archive read into memory
+// to check it, and of those checked at all when MaxUncompressedBytes is not
+// set. It is a variable for testing.
+var maxNestedArchive int64 = 64 << 20
+
+// WithArchiveLimits makes the Client check ZIP inputs, including formats
+// based on ZIP such as OOXML documents and JARs, against limits before
+// sending them, failing requests for inputs exceeding them with an error
+// wrapping ErrSuspectedArchiveBomb. Only inputs implementing io.ReaderAt and
+// io.Seeker, such as *os.File and *bytes.Reader, can be checked; other
+// inputs are sent unchecked, relying on the protection of the server.
+// Recursive requests also send limits.MaxEmbeddedResources.
+//
+// The sizes declared by the headers of an archive are checked first, and
+// then every entry is decompressed, up to MaxRatio times its compressed size
+// and MaxUncompressedBytes in total, so that archives declaring smaller
+// sizes than those of their real contents are rejected too. Checking an
+// archive thus costs as much CPU as decompressing it.
+func WithArchiveLimits(limits ArchiveLimits) Option {
+	return func(c *Client) {
+		c.archiveLimits = &limits
+	}
+}
+
+// checkInput checks input against l if it is a ZIP archive that can be read
+// without consuming it.
+func (l *ArchiveLimits) checkInput(input io.Reader) error {
+	r, ok := input.(interface {
+		io.ReaderAt
+		io.Seeker
+	})
+	if !ok {
+		return nil
+	}
+	off, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Seek(off, io.SeekStart); err != nil {
+		return err
+	}
+	return l.Check(io.NewSectionReader(r, off, end-off), end-off)
+}
+
+// header returns header with the headers enforcing l on the server for a
+// request to path.
+func (l *ArchiveLimits) header(path string, header http.Header) http.Header {
+	if l.MaxEmbeddedResources <= 0 || !strings.HasPrefix(path, "/rmeta") {
+		return header
+	}
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(MaxEmbeddedResourcesHeader, strconv.Itoa(l.MaxEmbeddedResources))
+	return header
+}
+
+// zipMagic starts ZIP archives.
+var zipMagic = []byte("PK\x03\x04")
+
+// Check checks the size bytes read from r against l, returning an error
+// wrapping ErrSuspectedArchiveBomb if they are a ZIP archive exceeding them.
+// Other content, including archives that cannot be read, is not checked.
+func (l ArchiveLimits) Check(r io.ReaderAt, size int64) error {
+	var entries int
+	var total int64
+	return l.check(r, size, 1, &entries, &total)
+}
+
+func (l ArchiveLimits) check(r io.ReaderAt, size int64, depth int, entries *int, total *int64) error {
+	magic := make([]byte, len(zipMagic))
+	if _, err := r.ReadAt(magic, 0); err != nil || !bytes.Equal(magic, zipMagic) {
+		return nil
+	}
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return fmt.Errorf("%w: archives nested more than %d deep", ErrSuspectedArchiveBomb, l.MaxDepth)
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil
+	}
+	*entries += len(zr.File)
+	if l.MaxEntries > 0 && *entries > l.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", ErrSuspectedArchiveBomb, l.MaxEntries)
+	}
+	var uncompressed uint64
+	for _, f := range zr.File {
+		uncompressed += f.UncompressedSize64
+		if l.MaxRatio > 0 && float64(f.UncompressedSize64) > l.MaxRatio*float64(f.CompressedSize64+1) {
+			return fmt.Errorf("%w: entry %s has compression ratio above %v", ErrSuspectedArchiveBomb, f.Name, l.MaxRatio)
+		}
+	}
+	if l.MaxRatio > 0 && float64(uncompressed) > l.MaxRatio*float64(size+1) {
+		return fmt.Errorf("%w: compression ratio above %v", ErrSuspectedArchiveBomb, l.MaxRatio)
+	}
+	for _, f := range zr.File {
+		// A nested archive counts as its contents, not as itself.
+		nested, err := l.checkEntry(f, depth, entries, total)
+		if err != nil {
+			return err
+		}
+		if !nested {
+			n, err := l.inflate(f, *total)
+			if err != nil {
+				return err
+			}
+			*total += n
+		}
+		if l.MaxUncompressedBytes > 0 && *total > l.MaxUncompressedBytes {
+			return fmt.Errorf("%w: more than %d bytes uncompressed", ErrSuspectedArchiveBomb, l.MaxUncompressedBytes)
+		}
+	}
+	return nil
+}
+
+// inflate decompresses f, returning its real uncompressed size, and an error
+// wrapping ErrSuspectedArchiveBomb if it exceeds l or the size declared by
+// its header. total is the uncompressed size of the entries already checked.
+// Entries that cannot be decompressed count as their declared size.
+func (l ArchiveLimits) inflate(f *zip.File, total int64) (int64, error) {
+	if l.MaxRatio <= 0 && l.MaxUncompressedBytes <= 0 {
+		return int64(f.UncompressedSize64), nil
+	}
+	limit := int64(-1)
+	if l.MaxRatio > 0 {
+		limit = int64(l.MaxRatio * float64(f.CompressedSize64+1))
+	}
+	if l.MaxUncompressedBytes > 0 {
+		if left := l.MaxUncompressedBytes - total; limit < 0 || left < limit {
+			limit = left
+		}
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return int64(f.UncompressedSize64), nil
+	}
+	defer rc.Close()
+	// The zip package fails with ErrFormat once an entry exceeds its
+	// declared size.
+	n, err := io.Copy(ioutil.Discard, io.LimitReader(rc, limit+1))
+	switch {
+	case errors.Is(err, zip.ErrFormat):
+		return 0, fmt.Errorf("%w: entry %s is larger than its declared size", ErrSuspectedArchiveBomb, f.Name)
+	case n <= limit:
+		return n, nil
+	case l.MaxRatio > 0 && float64(n) > l.MaxRatio*float64(f.CompressedSize64+1):
+		return 0, fmt.Errorf("%w: entry %s has compression ratio above %v", ErrSuspectedArchiveBomb, f.Name, l.MaxRatio)
+	}
+	return 0, fmt.Errorf("%w: more than %d bytes uncompressed", ErrSuspectedArchiveBomb, l.MaxUncompressedBytes)
+}
+
+// checkEntry checks f if it is a nested archive, reporting whether it is.
+// Nested archives up to maxNestedArchive bytes are read into memory, and
+// larger ones, up to MaxUncompressedBytes, are spooled to a temporary file.
+func (l ArchiveLimits) checkEntry(f *zip.File, depth int, entries *int, total *int64) (bool, error) {
+	max := l.MaxUncompressedBytes
+	if max <= 0 {
+		max = maxNestedArchive
+	}
+	if f.UncompressedSize64 < uint64(len(zipMagic)) || f.UncompressedSize64 > uint64(max) {
+		return false, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return false, nil
+	}
+	defer rc.Close()
+	magic := make([]byte, len(zipMagic))
+	if _, err := io.ReadFull(rc, magic); err != nil || !bytes.Equal(magic, zipMagic) {
+		return false, nil
+	}
+	r := io.MultiReader(bytes.NewReader(magic), io.LimitReader(rc, int64(f.UncompressedSize64)-int64(len(magic))))
+	var ra io.ReaderAt
+	var n int64
+	if f.UncompressedSize64 <= uint64(maxNestedArchive) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return false, nil
+		}
+		ra, n = bytes.NewReader(b), int64(len(b))
+	} else {
+		tmp, err := ioutil.TempFile("", "go-tika-archive-")
+		if err != nil {
+			return false, err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if n, err = io.Copy(tmp, r); err != nil {
+			return false, nil
+		}
+		ra = tmp
+	}
+	// An entry that only looks like an archive counts as itself.
+	if _, err := zip.NewReader(ra, n); err != nil {
+		return false, nil
+	}
+	return true, l.check(ra, n, depth+1, entries, total)
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// zipOf returns a ZIP archive holding files, compressed.
+func zipOf(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, b := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveLimitsCheck(t *testing.T) {
+	small := zipOf(t, map[string][]byte{"a.txt": []byte("hello"), "b.txt": []byte("world")})
+	bomb := zipOf(t, map[string][]byte{"zeros": make([]byte, 10<<20)})
+	many := map[string][]byte{}
+	for i := 0; i < 20; i++ {
+		many[fmt.Sprint(i)] = []byte("x")
+	}
+	nested := zipOf(t, map[string][]byte{"1.zip": zipOf(t, map[string][]byte{"2.zip": small})})
+	// 1000 bytes of contents, and more with the inner archive itself.
+	once := zipOf(t, map[string][]byte{"inner.zip": zipOf(t, map[string][]byte{"a.txt": bytes.Repeat([]byte("x"), 1000)})})
+	lookalike := zipOf(t, map[string][]byte{"fake.zip": append([]byte("PK\x03\x04"), make([]byte, 1000)...)})
+
+	tests := []struct {
+		name    string
+		input   []byte
+		limits  ArchiveLimits
+		wantErr bool
+	}{
+		{name: "not an archive", input: []byte("plain text"), limits: DefaultArchiveLimits},
+		{name: "truncated archive", input: small[:10], limits: DefaultArchiveLimits},
+		{name: "small", input: small, limits: DefaultArchiveLimits},
+		{name: "high ratio", input: bomb, limits: DefaultArchiveLimits, wantErr: true},
+		{name: "high ratio without limit", input: bomb},
+		{name: "too many entries", input: zipOf(t, many), limits: ArchiveLimits{MaxEntries: 10}, wantErr: true},
+		{name: "too large", input: small, limits: ArchiveLimits{MaxUncompressedBytes: 8}, wantErr: true},
+		{name: "nested", input: nested, limits: ArchiveLimits{MaxDepth: 3}},
+		{name: "nested too deep", input: nested, limits: ArchiveLimits{MaxDepth: 2}, wantErr: true},
+		{name: "nested entries", input: nested, limits: ArchiveLimits{MaxEntries: 3}, wantErr: true},
+		{name: "nested counted once", input: once, limits: ArchiveLimits{MaxUncompressedBytes: 1000}},
+		{name: "nested too large", input: once, limits: ArchiveLimits{MaxUncompressedBytes: 999}, wantErr: true},
+		{name: "nested lookalike", input: lookalike, limits: ArchiveLimits{MaxUncompressedBytes: 1000}, wantErr: true},
+	}
+	for _, test := range tests {
+		err := test.limits.Check(bytes.NewReader(test.input), int64(len(test.input)))
+		if test.wantErr != errors.Is(err, ErrSuspectedArchiveBomb) || (!test.wantErr && err != nil) {
+			t.Errorf("%s: Check got error %v, want archive bomb %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+// lyingZip returns a ZIP archive holding b, compressed, whose headers
+// declare an uncompressed size of declared bytes.
+func lyingZip(t *testing.T, b []byte, declared uint64) []byte {
+	t.Helper()
+	var compressed bytes.Buffer
+	fw, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(b)
+	fw.Close()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "zeros",
+		Method:             zip.Deflate,
+		CRC32:              crc32.ChecksumIEEE(b),
+		CompressedSize64:   uint64(compressed.Len()),
+		UncompressedSize64: declared,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(compressed.Bytes())
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveLimitsLyingHeader(t *testing.T) {
+	lying := lyingZip(t, make([]byte, 10<<20), 100)
+	honest := lyingZip(t, bytes.Repeat([]byte("abc"), 100), 300)
+	tests := []struct {
+		name    string
+		input   []byte
+		limits  ArchiveLimits
+		wantErr bool
+	}{
+		{name: "ratio", input: lying, limits: ArchiveLimits{MaxRatio: 100}, wantErr: true},
+		{name: "size", input: lying, limits: ArchiveLimits{MaxUncompressedBytes: 1 << 20}, wantErr: true},
+		{name: "defaults", input: lying, limits: DefaultArchiveLimits, wantErr: true},
+		{name: "no limits", input: lying},
+		{name: "honest", input: honest, limits: DefaultArchiveLimits},
+	}
+	for _, test := range tests {
+		err := test.limits.Check(bytes.NewReader(test.input), int64(len(test.input)))
+		if test.wantErr != errors.Is(err, ErrSuspectedArchiveBomb) || (!test.wantErr && err != nil) {
+			t.Errorf("%s: Check got error %v, want archive bomb %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestArchiveLimitsSpool(t *testing.T) {
+	defer func(max int64) { maxNestedArchive = max }(maxNestedArchive)
+	maxNestedArchive = 16
+
+	small := zipOf(t, map[string][]byte{"a.txt": []byte("hello")})
+	nested := zipOf(t, map[string][]byte{"1.zip": zipOf(t, map[string][]byte{"2.zip": small})})
+	limits := ArchiveLimits{MaxDepth: 2, MaxUncompressedBytes: 1 << 20}
+	if err := limits.Check(bytes.NewReader(nested), int64(len(nested))); !errors.Is(err, ErrSuspectedArchiveBomb) {
+		t.Errorf("Check of archives nested too deep above the memory limit got error %v, want %v", err, ErrSuspectedArchiveBomb)
+	}
+	limits.MaxDepth = 3
+	if err := limits.Check(bytes.NewReader(nested), int64(len(nested))); err != nil {
+		t.Errorf("Check of nested archives above the memory limit got error: %v", err)
+	}
+}
+
+func TestWithArchiveLimits(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/rmeta/text" {
+			fmt.Fprintf(w, `[{"max": [%q]}]`, r.Header.Get(MaxEmbeddedResourcesHeader))
+			return
+		}
+		io.Copy(ioutil.Discard, r.Body)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL, WithArchiveLimits(DefaultArchiveLimits))
+
+	bomb := zipOf(t, map[string][]byte{"zeros": make([]byte, 10<<20)})
+	r := bytes.NewReader(bomb)
+	if _, err := c.Parse(context.Background(), r); !errors.Is(err, ErrSuspectedArchiveBomb) {
+		t.Errorf("Parse of an archive bomb got error %v, want %v", err, ErrSuspectedArchiveBomb)
+	}
+	if requests != 0 {
+		t.Errorf("Parse of an archive bomb sent %d requests, want 0", requests)
+	}
+	if _, err := c.Parse(context.Background(), ioutil.NopCloser(bytes.NewReader(bomb))); err != nil {
+		t.Errorf("Parse of an archive bomb stream got error: %v", err)
+	}
+
+	// Checking does not consume the input.
+	small := zipOf(t, map[string][]byte{"a.txt": []byte("hello")})
+	r = bytes.NewReader(small)
+	if _, err := c.Parse(context.Background(), r); err != nil || r.Len() != 0 {
+		t.Errorf("Parse of a small archive got error %v with %d bytes unread, want the archive sent", err, r.Len())
+	}
+
+	docs, err := c.MetaRecursiveType(context.Background(), strings.NewReader("doc"), "text")
+	if err != nil {
+		t.Fatalf("MetaRecursiveType got error: %v", err)
+	}
+	if got := docs[0]["max"]; len(got) != 1 || got[0] != "10000" {
+		t.Errorf("MetaRecursiveType sent %s %v, want 10000", MaxEmbeddedResourcesHeader, got)
+	}
+}
@@ -0,0 +1,447 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// An Input is a named document to be parsed by a Batch.
+type Input struct {
+	// Name identifies the input in its Result, for example a file path.
+	Name string
+	// Open returns the content of the input. The returned reader is closed
+	// once the input has been parsed.
+	Open func() (io.ReadCloser, error)
+	// Size is the size of the content in bytes, or 0 if it is unknown. It
+	// lets a Batch skip inputs larger than MaxInputBytes without opening
+	// them.
+	Size int64
+	// ModTime is the modification time of the content, or the zero time if
+	// it is unknown. Together with Name and Size, it lets a Batch with a
+	// SeenSet skip unchanged inputs without opening them.
+	ModTime time.Time
+}
+
+// FileInput returns an Input for the file at path.
+func FileInput(path string) Input {
+	return Input{
+		Name: path,
+		Open: func() (io.ReadCloser, error) { return os.Open(path) },
+	}
+}
+
+// Inputs returns the given inputs in order, in a form suitable for Batch.Run.
+func Inputs(inputs ...Input) func(yield func(Input) bool) {
+	return func(yield func(Input) bool) {
+		for _, i := range inputs {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// A Result is the outcome of parsing a single Input.
+type Result struct {
+	// Name is the Name of the Input.
+	Name string
+	// Content is the parsed body of the Input.
+	Content string
+	// Metadata is the metadata of the Input, without the content of its
+	// embedded documents, if Batch.Metadata is true.
+	Metadata Metadata
+	// Skipped reports that the Input was not parsed, for the reason given
+	// by SkipReason.
+	Skipped bool
+	// SkipReason is why the Input was skipped, if it was.
+	SkipReason SkipReason
+	// Hash is the TextHash of Content, set when Batch.Dedup is true.
+	Hash string
+	// DuplicateOf is the Name of an earlier Result of the same run with the
+	// same Hash, or "" if there is none. It is only set when Batch.Dedup is
+	// true.
+	DuplicateOf string
+}
+
+// A SkipReason is why a Batch skipped an Input.
+type SkipReason string
+
+// Reasons for skipping an Input.
+const (
+	// SkipUnchanged means the Journal records the Input as already
+	// processed with the same content, or the SeenSet as already processed
+	// with the same size and modification time.
+	SkipUnchanged SkipReason = "unchanged"
+	// SkipTooLarge means the Input is larger than Batch.MaxInputBytes.
+	SkipTooLarge SkipReason = "too large"
+	// SkipTimeout means parsing the Input took longer than
+	// Batch.MaxParseTime.
+	SkipTimeout SkipReason = "timeout"
+	// SkipBudget means the Batch had exhausted MaxTotalBytes or
+	// MaxTotalTime before reaching the Input.
+	SkipBudget SkipReason = "budget exhausted"
+	// SkipQuarantined means the Batch Quarantine holds the Input.
+	SkipQuarantined SkipReason = "quarantined"
+)
+
+// TextHash returns the hex encoded SHA-256 hash of s with runs of whitespace
+// collapsed to single spaces and leading and trailing whitespace removed, so
+// texts differing only in layout have the same hash.
+func TextHash(s string) string {
+	h := sha256.New()
+	for i, f := range strings.Fields(s) {
+		if i > 0 {
+			h.Write([]byte{' '})
+		}
+		io.WriteString(h, f)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Batch parses many Inputs concurrently using a single Client.
+type Batch struct {
+	// Client is the Client used to parse every Input.
+	Client *Client
+	// Workers is the maximum number of concurrent requests. If Workers is
+	// less than or equal to 0, inputs are parsed one at a time.
+	Workers int
+	// Journal, if not nil, records every Input that is parsed successfully.
+	// Inputs the Journal records with the same content are skipped, and
+	// their Result has Skipped set. Each Input is opened twice: once to hash
+	// its content and once to parse it.
+	Journal *Journal
+	// DryRun, if true, skips parsing. Results are reported with no Content,
+	// and Skipped set for inputs the Journal records as unchanged, so a
+	// caller can see what a run would parse. Nothing is recorded in the
+	// Journal.
+	DryRun bool
+	// Metadata, if true, parses every Input with MetaRecursiveStream, in a
+	// single request, and sets the Metadata of its Result. Content is then
+	// the text of the Input followed by that of its embedded documents, one
+	// per line.
+	Metadata bool
+	// Dedup, if true, sets the Hash of every parsed Result and flags Results
+	// whose text duplicates an earlier Result by setting DuplicateOf.
+	Dedup bool
+
+	// MaxInputBytes, if greater than 0, is the maximum size of an Input.
+	// Larger inputs are skipped with SkipTooLarge, before opening them if
+	// their Size is known, else once that many bytes have been sent.
+	MaxInputBytes int64
+	// MaxParseTime, if greater than 0, is the maximum time to parse an
+	// Input. Inputs taking longer are skipped with SkipTimeout.
+	MaxParseTime time.Duration
+	// MaxTotalBytes, if greater than 0, is the budget of bytes sent for the
+	// whole run. Once it is spent, the remaining inputs are skipped with
+	// SkipBudget.
+	MaxTotalBytes int64
+	// MaxTotalTime, if greater than 0, is the time budget of the whole run.
+	// Once it is spent, the remaining inputs are skipped with SkipBudget.
+	MaxTotalTime time.Duration
+	// Quarantine, if not nil, records inputs which crash the server, closing
+	// or resetting the connection while parsing them, or time out, as
+	// reported by SkipTimeout. Failures to reach the server are not
+	// recorded. Quarantined inputs are skipped with SkipQuarantined.
+	Quarantine *Quarantine
+	// Seen, if not nil, records every Input with a ModTime that is parsed
+	// successfully. Inputs it holds with the same Name, Size, and ModTime
+	// are skipped with SkipUnchanged before being opened, which is much
+	// cheaper than the hashing done for a Journal on large corpora. Call
+	// Seen.Save to checkpoint it, for example from fn.
+	Seen *SeenSet
+	// Adaptive, if not nil, limits the number of concurrent requests to
+	// what the server sustains, between Adaptive.Min and Adaptive.Max,
+	// which defaults to Workers.
+	Adaptive *AdaptiveConcurrency
+}
+
+// errInputTooLarge is returned by a budgetReader that exceeded its limit.
+var errInputTooLarge = errors.New("input too large")
+
+// budgetReader counts the bytes read from r into the run total, and fails
+// with errInputTooLarge once more than max bytes are read, if max > 0.
+type budgetReader struct {
+	r     io.Reader
+	n     int64
+	max   int64
+	total *int64
+}
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	atomic.AddInt64(b.total, int64(n))
+	if b.max > 0 && b.n > b.max {
+		return n, errInputTooLarge
+	}
+	return n, err
+}
+
+// Run parses every Input produced by inputs and calls fn with its Result, or
+// with the error encountered while opening or parsing it. inputs has the same
+// shape as iter.Seq[Input]: it is called once and must stop when its yield
+// function returns false.
+//
+// Results are passed to fn in completion order, one at a time, from the
+// goroutine that called Run. Run stops early if fn returns false. Run returns
+// when every Input has been processed, fn returns false, or ctx is done, in
+// which case ctx.Err() is returned.
+func (b *Batch) Run(ctx context.Context, inputs func(yield func(Input) bool), fn func(Result, error) bool) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := b.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	if b.Adaptive != nil && b.Adaptive.Max > workers {
+		workers = b.Adaptive.Max
+	}
+
+	start := time.Now()
+	var sent int64
+	budgetLeft := func() bool {
+		if b.MaxTotalBytes > 0 && atomic.LoadInt64(&sent) >= b.MaxTotalBytes {
+			return false
+		}
+		return b.MaxTotalTime <= 0 || time.Since(start) < b.MaxTotalTime
+	}
+
+	type item struct {
+		r   Result
+		err error
+	}
+	in := make(chan Input)
+	out := make(chan item)
+
+	go func() {
+		defer close(in)
+		inputs(func(i Input) bool {
+			select {
+			case in <- i:
+				return true
+			case <-runCtx.Done():
+				return false
+			}
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for input := range in {
+				var (
+					r   Result
+					err error
+				)
+				switch {
+				case !budgetLeft():
+					r = Result{Name: input.Name, Skipped: true, SkipReason: SkipBudget}
+				case b.Adaptive != nil:
+					start, aerr := b.Adaptive.acquire(runCtx, workers)
+					if aerr != nil {
+						return
+					}
+					r, err = b.parse(runCtx, input, &sent)
+					b.Adaptive.release(start, input.Size, r, err, workers)
+				default:
+					r, err = b.parse(runCtx, input, &sent)
+				}
+				select {
+				case out <- item{r, err}:
+				case <-runCtx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	seen := map[string]string{}
+	for it := range out {
+		if b.Dedup && it.err == nil && !it.r.Skipped && !b.DryRun {
+			it.r.Hash = TextHash(it.r.Content)
+			if first, ok := seen[it.r.Hash]; ok {
+				it.r.DuplicateOf = first
+			} else {
+				seen[it.r.Hash] = it.r.Name
+			}
+		}
+		if !fn(it.r, it.err) {
+			cancel()
+			break
+		}
+	}
+	// Wait for the workers to return.
+	for range out {
+	}
+	return ctx.Err()
+}
+
+// parse opens and parses a single Input, adding the bytes sent to *sent.
+func (b *Batch) parse(ctx context.Context, input Input, sent *int64) (Result, error) {
+	r := Result{Name: input.Name}
+	var seenKey string
+	if b.Seen != nil {
+		if seenKey = SeenKey(input); seenKey != "" && b.Seen.Contains(seenKey) {
+			r.Skipped, r.SkipReason = true, SkipUnchanged
+			return r, nil
+		}
+	}
+	// Check what is cheap before hashing the input for the journal.
+	if b.Quarantine != nil && b.Quarantine.Quarantined(input.Name) {
+		r.Skipped, r.SkipReason = true, SkipQuarantined
+		return r, nil
+	}
+	if b.MaxInputBytes > 0 && input.Size > b.MaxInputBytes {
+		r.Skipped, r.SkipReason = true, SkipTooLarge
+		return r, nil
+	}
+	var hash string
+	if b.Journal != nil {
+		var err error
+		if hash, err = hashInput(input); err != nil {
+			return r, err
+		}
+		if b.Journal.Done(input.Name, hash) {
+			r.Skipped, r.SkipReason = true, SkipUnchanged
+			return r, nil
+		}
+	}
+	if b.DryRun {
+		return r, nil
+	}
+	body, err := input.Open()
+	if err != nil {
+		return r, err
+	}
+	defer body.Close()
+	parseCtx := ctx
+	if b.MaxParseTime > 0 {
+		var cancel context.CancelFunc
+		parseCtx, cancel = context.WithTimeout(ctx, b.MaxParseTime)
+		defer cancel()
+	}
+	br := &budgetReader{r: body, max: b.MaxInputBytes, total: sent}
+	if b.Metadata {
+		r.Content, r.Metadata, err = b.parseMetadata(parseCtx, br)
+	} else {
+		r.Content, err = b.Client.Parse(parseCtx, br)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, errInputTooLarge) || (b.MaxInputBytes > 0 && br.n > b.MaxInputBytes):
+			r.Skipped, r.SkipReason = true, SkipTooLarge
+			return r, nil
+		case ctx.Err() == nil && parseCtx.Err() == context.DeadlineExceeded:
+			r.Skipped, r.SkipReason = true, SkipTimeout
+			if b.Quarantine != nil {
+				return r, b.Quarantine.Timeout(input)
+			}
+			return r, nil
+		case b.Quarantine != nil && ctx.Err() == nil && isCrash(err):
+			if qerr := b.Quarantine.Crash(input); qerr != nil {
+				return r, qerr
+			}
+		}
+		return r, err
+	}
+	if seenKey != "" {
+		b.Seen.Add(seenKey)
+	}
+	if b.Journal != nil {
+		err = b.Journal.Record(input.Name, hash)
+	}
+	return r, err
+}
+
+// parseMetadata parses input with MetaRecursiveStream, returning the text of
+// every document and the metadata of the container document.
+func (b *Batch) parseMetadata(ctx context.Context, input io.Reader) (string, Metadata, error) {
+	var texts []string
+	var meta Metadata
+	err := b.Client.MetaRecursiveStream(ctx, input, "text", func(m Metadata) error {
+		if text := m.Get(XTIKAContent); text != "" {
+			texts = append(texts, text)
+		}
+		if meta == nil {
+			meta = Metadata{}
+			for k, v := range m {
+				if k != XTIKAContent {
+					meta[k] = v
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.Join(texts, "\n"), meta, nil
+}
+
+// ParseAll parses every Input produced by inputs one at a time, calling fn
+// with each Result. See Batch.Run for details. To parse inputs concurrently,
+// use a Batch.
+func (c *Client) ParseAll(ctx context.Context, inputs func(yield func(Input) bool), fn func(Result, error) bool) error {
+	b := &Batch{Client: c}
+	return b.Run(ctx, inputs, fn)
+}
+
+// errStopWalk is used to stop filepath.Walk early.
+var errStopWalk = errors.New("walk stopped")
+
+// Walk returns the regular files under root, in lexical order, as Inputs
+// suitable for Batch.Run. An error walking the tree is reported by the Open
+// function of the Input for the offending path.
+func Walk(root string) func(yield func(Input) bool) {
+	return func(yield func(Input) bool) {
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			var input Input
+			switch {
+			case err != nil:
+				input = Input{Name: path, Open: func() (io.ReadCloser, error) { return nil, err }}
+			case info.Mode().IsRegular():
+				input = FileInput(path)
+				input.Size = info.Size()
+				input.ModTime = info.ModTime()
+			default:
+				return nil
+			}
+			if !yield(input) {
+				return errStopWalk
+			}
+			return nil
+		})
+	}
+}
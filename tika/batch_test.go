@@ -0,0 +1,420 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoServer responds with the body of each request.
+func echoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+}
+
+func stringInput(name, content string) Input {
+	return Input{
+		Name: name,
+		Open: func() (io.ReadCloser, error) { return ioutil.NopCloser(strings.NewReader(content)), nil },
+	}
+}
+
+func TestBatchRun(t *testing.T) {
+	ts := echoServer()
+	defer ts.Close()
+	b := &Batch{Client: NewClient(nil, ts.URL), Workers: 3}
+
+	openErr := errors.New("open failed")
+	inputs := Inputs(
+		stringInput("a", "content a"),
+		stringInput("b", "content b"),
+		Input{Name: "bad", Open: func() (io.ReadCloser, error) { return nil, openErr }},
+		stringInput("c", "content c"),
+	)
+	var got []Result
+	var gotErr error
+	err := b.Run(context.Background(), inputs, func(r Result, err error) bool {
+		if err != nil {
+			gotErr = err
+			return true
+		}
+		got = append(got, r)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].Name < got[j].Name })
+	want := []Result{
+		{Name: "a", Content: "content a"},
+		{Name: "b", Content: "content b"},
+		{Name: "c", Content: "content c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run got %+v, want %+v", got, want)
+	}
+	if gotErr != openErr {
+		t.Errorf("Run got error %v, want %v", gotErr, openErr)
+	}
+}
+
+// rmetaServer serves /rmeta/text, returning a container document with the
+// request body as its title and content, and an embedded document.
+func rmetaServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rmeta/text" {
+			http.NotFound(w, r)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		json.NewEncoder(w).Encode([]Metadata{
+			{"dc:title": {string(b)}, XTIKAContent: {string(b)}},
+			{XTIKAContent: {"embedded"}},
+		})
+	}))
+}
+
+func TestBatchMetadata(t *testing.T) {
+	ts := rmetaServer()
+	defer ts.Close()
+	b := &Batch{Client: NewClient(nil, ts.URL), Metadata: true}
+	var got []Result
+	err := b.Run(context.Background(), Inputs(stringInput("a", "A")), func(r Result, err error) bool {
+		if err != nil {
+			t.Errorf("Run got error for %s: %v", r.Name, err)
+		}
+		got = append(got, r)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	want := []Result{{Name: "a", Content: "A\nembedded", Metadata: Metadata{"dc:title": {"A"}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run with Metadata got %+v, want %+v", got, want)
+	}
+}
+
+func TestBatchRunStop(t *testing.T) {
+	ts := echoServer()
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+
+	var inputs []Input
+	for i := 0; i < 10; i++ {
+		inputs = append(inputs, stringInput("input", "content"))
+	}
+	calls := 0
+	err := c.ParseAll(context.Background(), Inputs(inputs...), func(Result, error) bool {
+		calls++
+		return false
+	})
+	if err != nil {
+		t.Errorf("ParseAll returned an error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("ParseAll called fn %d times after it returned false, want 1", calls)
+	}
+}
+
+func TestBatchRunCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := errorClient.ParseAll(ctx, Inputs(stringInput("a", "")), func(Result, error) bool { return true })
+	if err != context.Canceled {
+		t.Errorf("ParseAll got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "walk")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"b.txt", "a.txt", filepath.Join("sub", "c.txt")} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	var got []string
+	Walk(dir)(func(i Input) bool {
+		got = append(got, i.Name)
+		return true
+	})
+	want := []string{
+		filepath.Join(dir, "a.txt"),
+		filepath.Join(dir, "b.txt"),
+		filepath.Join(dir, "sub", "c.txt"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk got %v, want %v", got, want)
+	}
+
+	got = nil
+	Walk(dir)(func(i Input) bool {
+		got = append(got, i.Name)
+		return false
+	})
+	if len(got) != 1 {
+		t.Errorf("Walk yielded %d inputs after yield returned false, want 1", len(got))
+	}
+
+	var walkErr error
+	Walk(filepath.Join(dir, "missing"))(func(i Input) bool {
+		_, walkErr = i.Open()
+		return true
+	})
+	if walkErr == nil {
+		t.Error("Walk of a missing directory got no error, want an error")
+	}
+}
+
+func TestTextHash(t *testing.T) {
+	if TextHash("a  b\n c ") != TextHash("a b c") {
+		t.Error("TextHash differs for texts differing only in whitespace")
+	}
+	if TextHash("a b") == TextHash("ab") {
+		t.Error("TextHash is the same for different texts")
+	}
+}
+
+func TestBatchRunDedup(t *testing.T) {
+	ts := echoServer()
+	defer ts.Close()
+	b := &Batch{Client: NewClient(nil, ts.URL), Dedup: true}
+
+	var got []Result
+	inputs := Inputs(stringInput("a", "x y"), stringInput("b", "other"), stringInput("c", " x\ty\n"))
+	err := b.Run(context.Background(), inputs, func(r Result, err error) bool {
+		got = append(got, r)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	want := []string{"", "", "a"}
+	for i, r := range got {
+		if r.Hash == "" {
+			t.Errorf("Result %s has no Hash", r.Name)
+		}
+		if r.DuplicateOf != want[i] {
+			t.Errorf("Result %s got DuplicateOf %q, want %q", r.Name, r.DuplicateOf, want[i])
+		}
+	}
+}
+
+func TestBatchBudgets(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if strings.HasPrefix(string(b), "slow") {
+			select {
+			case <-time.After(5 * time.Second):
+			case <-r.Context().Done():
+			}
+		}
+		w.Write(b)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	notOpened := Input{Name: "big", Size: 100, Open: func() (io.ReadCloser, error) {
+		return nil, errors.New("opened an input known to be too large")
+	}}
+
+	tests := []struct {
+		name  string
+		batch *Batch
+		input []Input
+		want  []Result
+	}{
+		{
+			name:  "per input",
+			batch: &Batch{Client: c, MaxInputBytes: 10, MaxParseTime: 50 * time.Millisecond},
+			input: []Input{
+				stringInput("small", "small"),
+				notOpened,
+				stringInput("unknown size", strings.Repeat("x", 64<<10)),
+				stringInput("slow", "slow"),
+			},
+			want: []Result{
+				{Name: "small", Content: "small"},
+				{Name: "big", Skipped: true, SkipReason: SkipTooLarge},
+				{Name: "unknown size", Skipped: true, SkipReason: SkipTooLarge},
+				{Name: "slow", Skipped: true, SkipReason: SkipTimeout},
+			},
+		},
+		{
+			name:  "total bytes",
+			batch: &Batch{Client: c, MaxTotalBytes: 10},
+			input: []Input{stringInput("a", "123456"), stringInput("b", "123456"), stringInput("c", "1")},
+			want: []Result{
+				{Name: "a", Content: "123456"},
+				{Name: "b", Content: "123456"},
+				{Name: "c", Skipped: true, SkipReason: SkipBudget},
+			},
+		},
+		{
+			name:  "total time",
+			batch: &Batch{Client: c, MaxTotalTime: time.Nanosecond},
+			input: []Input{stringInput("a", "a")},
+			want:  []Result{{Name: "a", Skipped: true, SkipReason: SkipBudget}},
+		},
+	}
+	for _, test := range tests {
+		var got []Result
+		err := test.batch.Run(context.Background(), Inputs(test.input...), func(r Result, err error) bool {
+			if err != nil {
+				t.Errorf("%s: %s got error: %v", test.name, r.Name, err)
+			}
+			got = append(got, r)
+			return true
+		})
+		if err != nil {
+			t.Errorf("%s: Run got error: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Run got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestBatchQuarantine(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		switch string(b) {
+		case "crash":
+			// Drop the connection like a crashing server.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		case "slow":
+			select {
+			case <-time.After(5 * time.Second):
+			case <-r.Context().Done():
+			}
+		}
+		w.Write(b)
+	}))
+	defer ts.Close()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "quarantine.ndjson")
+	q, err := OpenQuarantine(path)
+	if err != nil {
+		t.Fatalf("OpenQuarantine got error: %v", err)
+	}
+	q.Dir = filepath.Join(dir, "poison")
+	b := &Batch{Client: NewClient(nil, ts.URL), MaxParseTime: 50 * time.Millisecond, Quarantine: q}
+	inputs := Inputs(stringInput("ok", "ok"), stringInput("dir/crash", "crash"), stringInput("slow", "slow"))
+
+	run := func() map[string]Result {
+		got := map[string]Result{}
+		b.Run(context.Background(), inputs, func(r Result, err error) bool {
+			got[r.Name] = r
+			return true
+		})
+		return got
+	}
+	first := run()
+	if first["ok"].Content != "ok" || first["slow"].SkipReason != SkipTimeout || first["dir/crash"].Skipped {
+		t.Errorf("first run got %+v", first)
+	}
+	second := run()
+	if second["dir/crash"].Skipped || second["slow"].SkipReason != SkipTimeout {
+		t.Errorf("second run got %+v, want crash parsed again and slow timed out", second)
+	}
+	if !q.Quarantined("dir/crash") {
+		t.Errorf("Quarantined(dir/crash) got false after two crashes, want true")
+	}
+	q.Close()
+
+	// A new run reloads the failures recorded by the previous runs.
+	q, err = OpenQuarantine(path)
+	if err != nil {
+		t.Fatalf("OpenQuarantine got error: %v", err)
+	}
+	defer q.Close()
+	b.Quarantine = q
+	third := run()
+	for _, name := range []string{"dir/crash", "slow"} {
+		if third[name].SkipReason != SkipQuarantined {
+			t.Errorf("third run got %+v for %s, want it quarantined", third[name], name)
+		}
+	}
+	if third["ok"].Content != "ok" {
+		t.Errorf("third run got %+v for ok", third["ok"])
+	}
+	names := q.Names()
+	sort.Strings(names)
+	if want := []string{"dir/crash", "slow"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Names() = %v, want %v", names, want)
+	}
+	for _, f := range []string{"dir_crash", "slow"} {
+		if _, err := os.Stat(filepath.Join(dir, "poison", f)); err != nil {
+			t.Errorf("quarantined input %s was not copied: %v", f, err)
+		}
+	}
+}
+
+func TestBatchQuarantineUnavailable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "restarting", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	// A closed listener refuses connections.
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	for _, url := range []string{down.URL, ts.URL} {
+		q, err := OpenQuarantine(filepath.Join(t.TempDir(), "quarantine.ndjson"))
+		if err != nil {
+			t.Fatalf("OpenQuarantine got error: %v", err)
+		}
+		b := &Batch{Client: NewClient(nil, url), Quarantine: q}
+		inputs := Inputs(stringInput("a", "a"), stringInput("b", "b"), stringInput("c", "c"))
+		for i := 0; i < 3; i++ {
+			b.Run(context.Background(), inputs, func(r Result, err error) bool {
+				if err == nil || r.Skipped {
+					t.Errorf("Run of %s against %s got %+v, %v, want an error", r.Name, url, r, err)
+				}
+				return true
+			})
+		}
+		if names := q.Names(); len(names) != 0 {
+			t.Errorf("Run against %s quarantined %v, want nothing", url, names)
+		}
+		q.Close()
+	}
+}
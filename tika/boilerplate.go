@@ -0,0 +1,181 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// BoilerplateOptions configure RemoveBoilerplate.
+type BoilerplateOptions struct {
+	// MinBlockChars is the number of characters of text a block needs to be
+	// kept, unless it is a heading. If less than or equal to 0, 25 is used.
+	MinBlockChars int
+	// MaxLinkDensity is the largest share of the text of a block which may
+	// be inside links for it to be kept. Menus and lists of related
+	// articles are mostly links. If less than or equal to 0, 0.5 is used.
+	MaxLinkDensity float64
+}
+
+// boilerplateElements are dropped with their content.
+var boilerplateElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true,
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+	"iframe": true, "button": true, "select": true,
+}
+
+// boilerplateBlocks delimit the blocks RemoveBoilerplate keeps or drops.
+var boilerplateBlocks = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true,
+	"blockquote": true, "pre": true, "li": true, "dd": true, "dt": true,
+	"td": true, "th": true, "table": true, "ul": true, "ol": true, "dl": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// boilerplateAttr matches the class or id of elements that are usually not
+// part of the main content. Tika drops these attributes by default, so they
+// are only seen if the server is configured to keep them.
+var boilerplateAttr = regexp.MustCompile(`(?i)\b(ad|ads|advert\w*|banner|breadcrumbs?|comments?|cookie\w*|footer|masthead|menu|nav\w*|promo\w*|share|sidebar|social|sponsor\w*|subscribe)\b`)
+
+// RemoveBoilerplate returns a TextProcessor which extracts the main content of
+// a web page from the XHTML Tika produces for it, dropping navigation, ads,
+// footers, and other chrome, and returns it as plain text with one block per
+// paragraph. Like readability tools, it drops elements such as <nav> and
+// <footer>, then every block which is too short or mostly links; a heading
+// is kept only if content follows it. Text which is not XHTML is returned
+// unchanged.
+//
+// Use it in a Pipeline Rule which asks the server for HTML:
+//
+//	p.Rules["text/html"] = tika.Rule{
+//	    Header:     http.Header{"Accept": {"text/html"}},
+//	    Processors: []tika.TextProcessor{tika.RemoveBoilerplate(tika.BoilerplateOptions{})},
+//	}
+func RemoveBoilerplate(opts BoilerplateOptions) TextProcessor {
+	if opts.MinBlockChars <= 0 {
+		opts.MinBlockChars = 25
+	}
+	if opts.MaxLinkDensity <= 0 {
+		opts.MaxLinkDensity = 0.5
+	}
+	return TextProcessorFunc(func(_ context.Context, text string) (string, error) {
+		if !strings.HasPrefix(strings.TrimSpace(text), "<") {
+			return text, nil
+		}
+		return removeBoilerplate(strings.NewReader(text), opts)
+	})
+}
+
+func removeBoilerplate(r io.Reader, opts BoilerplateOptions) (string, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	var (
+		out     []string
+		heading string // heading waiting for content to follow it
+		block   strings.Builder
+		inH     bool // whether block is a heading
+		links   int  // characters of block inside links
+		inLink  int
+		skip    int
+	)
+	flush := func() {
+		s := strings.Join(strings.Fields(block.String()), " ")
+		block.Reset()
+		n := utf8.RuneCountInString(s)
+		switch {
+		case n == 0:
+		case inH:
+			heading = s
+		case n >= opts.MinBlockChars && float64(links) <= opts.MaxLinkDensity*float64(n):
+			if heading != "" {
+				out = append(out, heading)
+				heading = ""
+			}
+			out = append(out, s)
+		}
+		links = 0
+		inH = false
+	}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if skip > 0 || boilerplateElements[name] || boilerplateClass(t.Attr) {
+				skip++
+				continue
+			}
+			switch {
+			case boilerplateBlocks[name]:
+				flush()
+				inH = len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6'
+			case name == "br":
+				block.WriteByte(' ')
+			case name == "a":
+				inLink++
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if skip > 0 {
+				skip--
+				continue
+			}
+			switch {
+			case boilerplateBlocks[name]:
+				flush()
+			case name == "a" && inLink > 0:
+				inLink--
+			}
+		case xml.CharData:
+			if skip > 0 {
+				continue
+			}
+			s := string(t)
+			if inLink > 0 {
+				links += utf8.RuneCountInString(strings.Join(strings.Fields(s), " "))
+			}
+			block.WriteString(s)
+		}
+	}
+	flush()
+	return strings.Join(out, "\n\n"), nil
+}
+
+// boilerplateClass reports whether the class or id of an element marks it as
+// boilerplate.
+func boilerplateClass(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if (a.Name.Local == "class" || a.Name.Local == "id") && boilerplateAttr.MatchString(a.Value) {
+			return true
+		}
+	}
+	return false
+}
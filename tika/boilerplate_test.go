@@ -0,0 +1,54 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"testing"
+)
+
+const boilerplatePage = `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>News</title><script>track()</script></head>
+<body>
+<nav><a href="/">Home</a> <a href="/world">World</a></nav>
+<ul><li><a href="/a">Sports</a></li><li><a href="/b">Weather</a></li><li><a href="/c">Markets</a></li></ul>
+<h2>Menu</h2>
+<p><a href="/1">A very long related article title</a> and <a href="/2">another one</a></p>
+<h1>Rivers rise after storm</h1>
+<p>Heavy rain overnight pushed several rivers above flood stage, officials said on Tuesday.</p>
+<p>Residents near the <a href="/river">Elm River</a> were asked to move to higher ground &amp; stay alert.</p>
+<div class="ad-banner"><p>Buy one mattress, get a second mattress for free today only!</p></div>
+<p>Share</p>
+<footer><p>Copyright 2026 Example News. All rights reserved.</p></footer>
+</body></html>`
+
+func TestRemoveBoilerplate(t *testing.T) {
+	p := RemoveBoilerplate(BoilerplateOptions{})
+	got, err := p.Process(context.Background(), boilerplatePage)
+	if err != nil {
+		t.Fatalf("RemoveBoilerplate got error: %v", err)
+	}
+	want := "Rivers rise after storm\n\n" +
+		"Heavy rain overnight pushed several rivers above flood stage, officials said on Tuesday.\n\n" +
+		"Residents near the Elm River were asked to move to higher ground & stay alert."
+	if got != want {
+		t.Errorf("RemoveBoilerplate got\n%s\nwant\n%s", got, want)
+	}
+
+	if got, err := p.Process(context.Background(), "plain text"); err != nil || got != "plain text" {
+		t.Errorf("RemoveBoilerplate(plain text) = %q, %v, want it unchanged", got, err)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the circuit
+// breaker configured with WithCircuitBreaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// WithCircuitBreaker makes the Client fail fast with ErrCircuitOpen for
+// coolDown after threshold consecutive requests fail. A request fails if it
+// gets no response, times out, or gets a 5xx response; requests canceled by
+// the caller are not counted. Once coolDown has passed, a single request is
+// let through: if it succeeds the breaker closes, otherwise it opens for
+// another coolDown.
+//
+// The breaker protects a single server URL. To spread requests over several
+// servers, use a Client, each with its own breaker, per server.
+func WithCircuitBreaker(threshold int, coolDown time.Duration) Option {
+	return func(c *Client) {
+		c.breaker = &breaker{threshold: threshold, coolDown: coolDown, now: time.Now}
+	}
+}
+
+// breaker is a circuit breaker.
+type breaker struct {
+	threshold int
+	coolDown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow returns ErrCircuitOpen if a request may not be made.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record records the outcome of a request allowed by allow.
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	var ce ClientError
+	switch {
+	case err == nil, errors.As(err, &ce) && ce.StatusCode < 500, errors.Is(err, ErrResponseTooLarge):
+		b.failures = 0
+	case errors.Is(err, context.Canceled):
+		// The caller gave up; this says nothing about the server.
+	default:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openUntil = b.now().Add(b.coolDown)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	status := http.StatusInternalServerError
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, ts.URL, WithCircuitBreaker(2, time.Minute))
+	clock := time.Now()
+	c.breaker.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Version(ctx); err == ErrCircuitOpen {
+			t.Fatalf("request %d got ErrCircuitOpen before the threshold", i)
+		}
+	}
+	if _, err := c.Version(ctx); err != ErrCircuitOpen {
+		t.Errorf("Version got %v after 2 failures, want ErrCircuitOpen", err)
+	}
+	if requests != 2 {
+		t.Errorf("server got %d requests, want 2", requests)
+	}
+
+	// After the cool-down a failing probe opens the breaker again.
+	clock = clock.Add(time.Minute)
+	if _, err := c.Version(ctx); err == ErrCircuitOpen {
+		t.Error("probe got ErrCircuitOpen after the cool-down")
+	}
+	if _, err := c.Version(ctx); err != ErrCircuitOpen {
+		t.Errorf("Version got %v after a failed probe, want ErrCircuitOpen", err)
+	}
+
+	// A successful probe closes it.
+	clock = clock.Add(time.Minute)
+	status = http.StatusOK
+	for i := 0; i < 3; i++ {
+		if _, err := c.Version(ctx); err != nil {
+			t.Errorf("Version got %v after a successful probe, want nil", err)
+		}
+	}
+}
+
+func TestCircuitBreakerIgnored(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, ts.URL, WithCircuitBreaker(1, time.Minute))
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for i := 0; i < 3; i++ {
+		if _, err := c.Version(ctx); err == ErrCircuitOpen {
+			t.Fatal("4xx responses opened the circuit breaker")
+		}
+		if _, err := c.Version(canceled); err == ErrCircuitOpen {
+			t.Fatal("canceled requests opened the circuit breaker")
+		}
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadString(t *testing.T) {
+	tests := []string{"", "hello", strings.Repeat("x", maxPooledBuffer+1)}
+	for _, want := range tests {
+		// Twice, so the second read may reuse a pooled buffer.
+		for i := 0; i < 2; i++ {
+			got, err := readString(strings.NewReader(want))
+			if err != nil {
+				t.Errorf("readString(%d bytes) got error: %v", len(want), err)
+				continue
+			}
+			if got != want {
+				t.Errorf("readString(%d bytes) returned %d bytes, want %d", len(want), len(got), len(want))
+			}
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != 5 {
+			t.Errorf("ParseBytes sent Content-Length %d, want 5", r.ContentLength)
+		}
+		io.Copy(w, r.Body)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	got, err := c.ParseBytes(context.Background(), []byte("hello"))
+	if err != nil {
+		t.Fatalf("ParseBytes got error: %v", err)
+	}
+	if got != "hello" {
+		t.Errorf("ParseBytes = %q, want %q", got, "hello")
+	}
+}
+
+func benchmarkServer(b *testing.B) *httptest.Server {
+	b.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rmeta/text":
+			io.WriteString(w, `[{"Content-Type":["text/plain"],"X-TIKA:content":"small document"}]`)
+		default:
+			io.Copy(w, r.Body)
+		}
+	}))
+	b.Cleanup(ts.Close)
+	return ts
+}
+
+func BenchmarkParse(b *testing.B) {
+	ts := benchmarkServer(b)
+	c := NewClient(nil, ts.URL)
+	doc := strings.Repeat("small document ", 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Parse(context.Background(), strings.NewReader(doc)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	ts := benchmarkServer(b)
+	c := NewClient(nil, ts.URL)
+	doc := bytes.Repeat([]byte("small document "), 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ParseBytes(context.Background(), doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseParallel(b *testing.B) {
+	ts := benchmarkServer(b)
+	c := NewClient(nil, ts.URL)
+	doc := []byte(strings.Repeat("small document ", 64))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := c.ParseBytes(context.Background(), doc); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkMetaRecursive(b *testing.B) {
+	ts := benchmarkServer(b)
+	c := NewClient(nil, ts.URL)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.MetaRecursive(context.Background(), strings.NewReader("doc")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadString(b *testing.B) {
+	doc := strings.Repeat("small document ", 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := readString(strings.NewReader(doc)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// userCacheDir is overridden in tests.
+var userCacheDir = os.UserCacheDir
+
+const (
+	cachePrefix = "tika-server-"
+	cacheSuffix = ".jar"
+)
+
+// CacheDir returns the directory used to cache downloaded Tika Server JARs,
+// which is a go-tika directory inside os.UserCacheDir.
+func CacheDir() (string, error) {
+	dir, err := userCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-tika"), nil
+}
+
+// cachePath returns the path of the cached JAR for v.
+func cachePath(v Version) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, cachePrefix+string(v)+cacheSuffix), nil
+}
+
+// EnsureServer returns the path to a validated Tika Server JAR for version v
+// in CacheDir, downloading it if it is not already cached. Unlike
+// DownloadServer, the JAR is reused across runs and should not be removed by
+// the caller; see PruneCache.
+func EnsureServer(ctx context.Context, v Version) (string, error) {
+	var d Downloader
+	return d.Ensure(ctx, v)
+}
+
+// Ensure is like EnsureServer, but uses the configuration of d.
+func (d *Downloader) Ensure(ctx context.Context, v Version) (string, error) {
+	path, err := cachePath(v)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("error creating cache directory: %v", err)
+	}
+	if err := d.Download(ctx, v, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// CachedVersions returns the versions of the Tika Server JARs in CacheDir, in
+// lexical order.
+func CachedVersions() ([]Version, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var vs []Version
+	for _, f := range files {
+		name := f.Name()
+		if f.Mode().IsRegular() && strings.HasPrefix(name, cachePrefix) && strings.HasSuffix(name, cacheSuffix) {
+			vs = append(vs, Version(strings.TrimSuffix(strings.TrimPrefix(name, cachePrefix), cacheSuffix)))
+		}
+	}
+	sort.Slice(vs, func(i, j int) bool { return vs[i] < vs[j] })
+	return vs, nil
+}
+
+// PruneCache removes every cached Tika Server JAR except those for the given
+// versions.
+func PruneCache(keep ...Version) error {
+	vs, err := CachedVersions()
+	if err != nil {
+		return err
+	}
+	kept := make(map[Version]bool)
+	for _, v := range keep {
+		kept[v] = true
+	}
+	for _, v := range vs {
+		if kept[v] {
+			continue
+		}
+		path, err := cachePath(v)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"crypto/sha512"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// withCacheDir points CacheDir at a temporary directory until the returned
+// function is called.
+func withCacheDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	old := userCacheDir
+	userCacheDir = func() (string, error) { return dir, nil }
+	return dir, func() {
+		userCacheDir = old
+		os.RemoveAll(dir)
+	}
+}
+
+func TestEnsureServer(t *testing.T) {
+	_, cleanup := withCacheDir(t)
+	defer cleanup()
+
+	content := []byte("tika server jar")
+	v := Version("test")
+	sha512s[v] = fmt.Sprintf("%x", sha512.Sum512(content))
+	defer delete(sha512s, v)
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Write(content)
+	}))
+	defer ts.Close()
+
+	d := &Downloader{Mirrors: []string{ts.URL}}
+	for i := 0; i < 2; i++ {
+		path, err := d.Ensure(context.Background(), v)
+		if err != nil {
+			t.Fatalf("Ensure got error: %v", err)
+		}
+		if got, want := filepath.Base(path), "tika-server-test.jar"; got != want {
+			t.Errorf("Ensure returned %q, want a file named %q", path, want)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Ensure downloaded the JAR %d times, want 1", requests)
+	}
+}
+
+func TestCachedVersionsAndPrune(t *testing.T) {
+	dir, cleanup := withCacheDir(t)
+	defer cleanup()
+
+	if vs, err := CachedVersions(); err != nil || len(vs) != 0 {
+		t.Errorf("CachedVersions of a missing cache = %v, %v, want none", vs, err)
+	}
+	cache := filepath.Join(dir, "go-tika")
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	for _, name := range []string{"tika-server-1.21.jar", "tika-server-1.19.jar", "other.txt", "tika-server-1.20.jar.part"} {
+		if err := ioutil.WriteFile(filepath.Join(cache, name), nil, 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	vs, err := CachedVersions()
+	if err != nil {
+		t.Fatalf("CachedVersions got error: %v", err)
+	}
+	if want := []Version{Version119, Version121}; !reflect.DeepEqual(vs, want) {
+		t.Errorf("CachedVersions = %v, want %v", vs, want)
+	}
+
+	if err := PruneCache(Version121); err != nil {
+		t.Fatalf("PruneCache got error: %v", err)
+	}
+	vs, err = CachedVersions()
+	if err != nil {
+		t.Fatalf("CachedVersions got error: %v", err)
+	}
+	if want := []Version{Version121}; !reflect.DeepEqual(vs, want) {
+		t.Errorf("CachedVersions after PruneCache = %v, want %v", vs, want)
+	}
+}
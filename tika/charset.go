@@ -0,0 +1,237 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// An InvalidPolicy is what WithUTF8 does with bytes that are not valid in the
+// charset of a response.
+type InvalidPolicy int
+
+// Policies for invalid bytes.
+const (
+	// InvalidReplace replaces invalid bytes with U+FFFD.
+	InvalidReplace InvalidPolicy = iota
+	// InvalidDrop removes invalid bytes.
+	InvalidDrop
+	// InvalidFail fails the read with ErrInvalidEncoding.
+	InvalidFail
+)
+
+// ErrInvalidEncoding is returned when a response contains bytes that are not
+// valid in its charset and the InvalidFail policy is in use.
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+// WithUTF8 converts every response to valid UTF-8. The charset of a response
+// is the one in its Content-Type or, if it has none, the one set for the call
+// with ContextWithCharset, usually the charset Tika detected for the input as
+// reported by Metadata.Charset. Responses with neither are treated as UTF-8
+// and validated. Invalid bytes are handled according to policy.
+//
+// Charsets are those of the WHATWG Encoding Standard, such as Shift_JIS, GBK,
+// EUC-KR, windows-1251, ISO-8859-2, and UTF-16, under any of their labels.
+// Requests for responses in other charsets fail with an error.
+func WithUTF8(policy InvalidPolicy) Option {
+	return func(c *Client) {
+		c.transcode = true
+		c.invalidPolicy = policy
+	}
+}
+
+type charsetKey struct{}
+
+// ContextWithCharset returns a context making a Client created with WithUTF8
+// decode responses without a charset in their Content-Type as charset:
+//
+//	m, err := client.Metadata(ctx, file)
+//	...
+//	text, err := client.Parse(tika.ContextWithCharset(ctx, m.Charset()), file)
+func ContextWithCharset(ctx context.Context, charset string) context.Context {
+	return context.WithValue(ctx, charsetKey{}, charset)
+}
+
+// Charset returns the charset of the document m describes: the charset
+// parameter of its Content-Type or, if it has none, its Content-Encoding,
+// which Tika sets to the charset it detected for text documents. It returns
+// "" if m has neither.
+func (m Metadata) Charset() string {
+	if _, params, err := mime.ParseMediaType(m.Get("Content-Type")); err == nil && params["charset"] != "" {
+		return params["charset"]
+	}
+	return m.Get("Content-Encoding")
+}
+
+// NewUTF8Reader returns a reader converting r from charset, one of those
+// supported by WithUTF8, to UTF-8, handling invalid bytes according to
+// policy. An empty charset is UTF-8.
+func NewUTF8Reader(r io.Reader, charset string, policy InvalidPolicy) (io.Reader, error) {
+	return newTranscoder(ioutil.NopCloser(r), charset, policy)
+}
+
+// responseCharset returns the charset of a response with the given
+// Content-Type to a request with ctx.
+func responseCharset(ctx context.Context, contentType string) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return params["charset"]
+	}
+	charset, _ := ctx.Value(charsetKey{}).(string)
+	return charset
+}
+
+// decodeFunc decodes a prefix of src, which is UTF-8, into dst. It returns
+// the number of bytes of src consumed. Unless atEOF, it may leave an
+// incomplete character at the end of src unconsumed. Invalid bytes are
+// reported by calling invalid, which returns false to stop decoding.
+type decodeFunc func(dst *[]byte, src []byte, atEOF bool, invalid func() bool) int
+
+// transcoder validates UTF-8 read from r, applying an InvalidPolicy.
+type transcoder struct {
+	r       io.ReadCloser
+	decode  decodeFunc
+	policy  InvalidPolicy
+	src     []byte // Undecoded input.
+	dst     []byte // Decoded output not yet returned.
+	err     error  // Error to return once dst is drained.
+	started bool
+}
+
+// readCloser reads from a Reader and closes a Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// newTranscoder returns a reader converting r from charset to UTF-8. r is
+// closed if an error is returned.
+func newTranscoder(r io.ReadCloser, charset string, policy InvalidPolicy) (io.ReadCloser, error) {
+	label := strings.ToLower(strings.TrimSpace(charset))
+	if label == "" {
+		label = "utf-8"
+	}
+	var enc encoding.Encoding
+	if label == "utf-16" {
+		// WHATWG decodes UTF-16 as little endian, but a byte order mark
+		// says otherwise.
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	} else {
+		var err error
+		if enc, err = htmlindex.Get(label); err != nil {
+			r.Close()
+			return nil, fmt.Errorf("unsupported charset %q", charset)
+		}
+	}
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return &transcoder{r: r, decode: decodeUTF8, policy: policy}, nil
+	}
+	// Decoders replace invalid bytes with U+FFFD, which decodeReplaced
+	// then reports as invalid.
+	return &transcoder{
+		r:      readCloser{transform.NewReader(r, enc.NewDecoder()), r},
+		decode: decodeReplaced,
+		policy: policy,
+	}, nil
+}
+
+func (t *transcoder) Read(p []byte) (int, error) {
+	for len(t.dst) == 0 && t.err == nil {
+		buf := make([]byte, 4096)
+		n, err := t.r.Read(buf)
+		t.src = append(t.src, buf[:n]...)
+		atEOF := err == io.EOF
+		if err != nil && !atEOF {
+			t.err = err
+		}
+		invalid := func() bool {
+			switch t.policy {
+			case InvalidReplace:
+				t.dst = append(t.dst, string(utf8.RuneError)...)
+			case InvalidFail:
+				t.err = ErrInvalidEncoding
+				return false
+			}
+			return true
+		}
+		used := t.decode(&t.dst, t.src, atEOF, invalid)
+		t.src = t.src[used:]
+		// Drop a leading byte order mark.
+		if !t.started && len(t.dst) >= 3 && string(t.dst[:3]) == "\uFEFF" {
+			t.dst = t.dst[3:]
+		}
+		if len(t.dst) > 0 {
+			t.started = true
+		}
+		if atEOF && t.err == nil {
+			t.err = io.EOF
+		}
+	}
+	n := copy(p, t.dst)
+	t.dst = t.dst[n:]
+	if len(t.dst) == 0 && t.err != nil {
+		return n, t.err
+	}
+	return n, nil
+}
+
+func (t *transcoder) Close() error {
+	return t.r.Close()
+}
+
+func decodeUTF8(dst *[]byte, src []byte, atEOF bool, invalid func() bool) int {
+	return validateUTF8(dst, src, atEOF, invalid, false)
+}
+
+// decodeReplaced is decodeUTF8 for the output of a decoder from another
+// charset, also reporting every U+FFFD as invalid: it is what the decoder
+// replaced invalid bytes with, as legacy charsets cannot encode it. A U+FFFD
+// encoded in UTF-16 input is reported too.
+func decodeReplaced(dst *[]byte, src []byte, atEOF bool, invalid func() bool) int {
+	return validateUTF8(dst, src, atEOF, invalid, true)
+}
+
+// validateUTF8 implements decodeUTF8 and decodeReplaced.
+func validateUTF8(dst *[]byte, src []byte, atEOF bool, invalid func() bool, replaced bool) int {
+	i := 0
+	for i < len(src) {
+		if !atEOF && !utf8.FullRune(src[i:]) {
+			break
+		}
+		r, size := utf8.DecodeRune(src[i:])
+		if r == utf8.RuneError && (size == 1 || replaced) {
+			if !invalid() {
+				return i
+			}
+		} else {
+			*dst = append(*dst, src[i:i+size]...)
+		}
+		i += size
+	}
+	return i
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestTranscoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		charset string
+		in      string
+		policy  InvalidPolicy
+		want    string
+		wantErr bool
+	}{
+		{"utf-8", "UTF-8", "héllo 世界", InvalidReplace, "héllo 世界", false},
+		{"no charset", "", "a\xffb", InvalidReplace, "a�b", false},
+		{"drop", "", "a\xffb", InvalidDrop, "ab", false},
+		{"fail", "", "a\xffb", InvalidFail, "a", true},
+		{"truncated rune", "", "a\xe4\xb8", InvalidReplace, "a��", false},
+		{"utf-8 replacement character", "utf-8", "a\ufffdb", InvalidDrop, "a\ufffdb", false},
+		{"latin1", "ISO-8859-1", "caf\xe9", InvalidReplace, "café", false},
+		{"windows-1252", "windows-1252", "\x93quoted\x94 \x80", InvalidReplace, "“quoted” €", false},
+		{"utf-16le", "UTF-16LE", "h\x00i\x00=\xd8\x00\xde", InvalidReplace, "hi😀", false},
+		{"utf-16be", "UTF-16BE", "\x00h\x00i", InvalidReplace, "hi", false},
+		{"utf-16 bom", "UTF-16", "\xff\xfeh\x00i\x00", InvalidReplace, "hi", false},
+		{"utf-16 big endian bom", "UTF-16", "\xfe\xff\x00h\x00i", InvalidReplace, "hi", false},
+		{"utf-16 lone surrogate", "UTF-16BE", "\xd8\x00\x00h", InvalidReplace, "�h", false},
+		{"utf-8 bom", "", "\xef\xbb\xbfhi", InvalidReplace, "hi", false},
+		{"shift_jis", "Shift_JIS", "\x82\xa0\x82\xa2", InvalidReplace, "あい", false},
+		{"gbk", "GBK", "\xc4\xe3\xba\xc3", InvalidReplace, "你好", false},
+		{"euc-kr", "EUC-KR", "\xc7\xd1\xb1\xdb", InvalidReplace, "한글", false},
+		{"windows-1251", "windows-1251", "\xcf\xf0\xe8\xe2\xe5\xf2", InvalidReplace, "Привет", false},
+		{"iso-8859-2", "ISO-8859-2", "\xb1\xea", InvalidReplace, "ąę", false},
+		{"label", "  latin2 ", "\xb1", InvalidReplace, "ą", false},
+		{"shift_jis invalid", "Shift_JIS", "a\x82", InvalidReplace, "a�", false},
+		{"shift_jis drop", "Shift_JIS", "a\x82", InvalidDrop, "a", false},
+		{"shift_jis fail", "Shift_JIS", "a\x82", InvalidFail, "a", true},
+	}
+	for _, test := range tests {
+		for _, oneByte := range []bool{false, true} {
+			var r = ioutil.NopCloser(strings.NewReader(test.in))
+			if oneByte {
+				r = ioutil.NopCloser(iotest.OneByteReader(strings.NewReader(test.in)))
+			}
+			tr, err := newTranscoder(r, test.charset, test.policy)
+			if err != nil {
+				t.Errorf("%s: newTranscoder returned an error: %v", test.name, err)
+				continue
+			}
+			got, err := ioutil.ReadAll(tr)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("%s (one byte %v): got error %v, want error %v", test.name, oneByte, err, test.wantErr)
+			}
+			if string(got) != test.want {
+				t.Errorf("%s (one byte %v): got %q, want %q", test.name, oneByte, got, test.want)
+			}
+		}
+	}
+	if _, err := newTranscoder(ioutil.NopCloser(strings.NewReader("")), "x-unknown", InvalidReplace); err == nil {
+		t.Error("newTranscoder got no error for an unsupported charset")
+	}
+}
+
+func TestMetadataCharset(t *testing.T) {
+	tests := []struct {
+		m    Metadata
+		want string
+	}{
+		{Metadata{"Content-Type": {"text/plain; charset=windows-1251"}, "Content-Encoding": {"ISO-8859-1"}}, "windows-1251"},
+		{Metadata{"Content-Type": {"text/plain"}, "Content-Encoding": {"Shift_JIS"}}, "Shift_JIS"},
+		{Metadata{"Content-Type": {"application/pdf"}}, ""},
+	}
+	for _, test := range tests {
+		if got := test.m.Charset(); got != test.want {
+			t.Errorf("%v.Charset() got %q, want %q", test.m, got, test.want)
+		}
+	}
+}
+
+func TestNewUTF8Reader(t *testing.T) {
+	r, err := NewUTF8Reader(strings.NewReader("\xcf\xf0\xe8\xe2\xe5\xf2"), "windows-1251", InvalidReplace)
+	if err != nil {
+		t.Fatalf("NewUTF8Reader returned an error: %v", err)
+	}
+	if got, _ := ioutil.ReadAll(r); string(got) != "Привет" {
+		t.Errorf("NewUTF8Reader got %q, want %q", got, "Привет")
+	}
+}
+
+func TestWithUTF8(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+		w.Write([]byte("na\xefve"))
+	}))
+	defer ts.Close()
+
+	got, err := NewClient(nil, ts.URL, WithUTF8(InvalidReplace)).Parse(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	if want := "naïve"; got != want {
+		t.Errorf("Parse got %q, want %q", got, want)
+	}
+}
+
+func TestWithUTF8Fallback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("\x82\xa0"))
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, ts.URL, WithUTF8(InvalidReplace))
+	m := Metadata{"Content-Type": {"text/plain"}, "Content-Encoding": {"Shift_JIS"}}
+	got, err := c.Parse(ContextWithCharset(context.Background(), m.Charset()), nil)
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	if want := "あ"; got != want {
+		t.Errorf("Parse with the charset of %v got %q, want %q", m, got, want)
+	}
+	if got, _ := c.Parse(context.Background(), nil); got != "��" {
+		t.Errorf("Parse without a charset got %q, want %q", got, "��")
+	}
+}
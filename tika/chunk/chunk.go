@@ -0,0 +1,271 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package chunk splits text extracted by Apache Tika into bounded chunks
+// suitable for search indexing or language model ingestion.
+//
+// Chunks never span a page or heading boundary, so every Chunk records the
+// page and heading its text came from. Page and heading information is taken
+// from the XHTML produced by Tika: pages are <div class="page"> elements and
+// headings are <h1> through <h6> elements.
+package chunk
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-tika/tika"
+)
+
+// Options bound the size of each Chunk. A zero limit means no limit.
+type Options struct {
+	// MaxBytes is the maximum length of the Text of a Chunk in bytes.
+	MaxBytes int
+	// MaxTokens is the maximum number of tokens in the Text of a Chunk.
+	// Tokens are approximated by whitespace separated words.
+	MaxTokens int
+}
+
+// A Chunk is a piece of the text of a document.
+type Chunk struct {
+	// Text is the text of the chunk. Paragraphs are separated by newlines.
+	Text string
+	// Path is the embedded resource path of the document the chunk came
+	// from, or "" for the container document. See
+	// tika.XTIKAEmbeddedResourcePath.
+	Path string
+	// Page is the 1-based page number the chunk came from, or 0 if the
+	// document has no pages.
+	Page int
+	// Heading is the text of the most recent heading before the chunk, or ""
+	// if there is none.
+	Heading string
+}
+
+// block is a paragraph of text with its provenance.
+type block struct {
+	text    string
+	page    int
+	heading string
+}
+
+// Text splits plain text into chunks. Paragraphs are separated by blank lines.
+func Text(s string, opts Options) []Chunk {
+	var blocks []block
+	for _, p := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n\n") {
+		if p = normalize(p); p != "" {
+			blocks = append(blocks, block{text: p})
+		}
+	}
+	return pack(blocks, opts)
+}
+
+// XHTML splits the XHTML output of Tika, as returned by Client.Parse with an
+// Accept header of text/html or in the content of Client.MetaRecursive, into
+// chunks.
+func XHTML(r io.Reader, opts Options) ([]Chunk, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	var (
+		blocks  []block
+		buf     strings.Builder
+		page    int
+		heading string
+		inHead  bool
+		skip    int
+	)
+	flush := func() {
+		if t := normalize(buf.String()); t != "" {
+			blocks = append(blocks, block{text: t, page: page, heading: heading})
+		}
+		buf.Reset()
+	}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case skip > 0 || name == "head" || name == "script" || name == "style":
+				skip++
+			case name == "div" && hasClass(t, "page"):
+				flush()
+				page++
+			case isHeading(name):
+				flush()
+				inHead = true
+			case isBlock(name):
+				flush()
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case skip > 0:
+				skip--
+			case isHeading(name) && inHead:
+				heading = normalize(buf.String())
+				inHead = false
+				flush()
+			case isBlock(name):
+				flush()
+			}
+		case xml.CharData:
+			if skip == 0 {
+				buf.Write(t)
+			}
+		}
+	}
+	flush()
+	return pack(blocks, opts), nil
+}
+
+// Documents splits the documents returned by Client.MetaRecursive into
+// chunks, setting the Path of each Chunk. Content in XHTML form is split with
+// XHTML and other content with Text.
+func Documents(docs []tika.Metadata, opts Options) ([]Chunk, error) {
+	var chunks []Chunk
+	for _, doc := range docs {
+		content := strings.Join(doc[tika.XTIKAContent], "\n\n")
+		var cs []Chunk
+		if strings.HasPrefix(strings.TrimSpace(content), "<") {
+			var err error
+			if cs, err = XHTML(strings.NewReader(content), opts); err != nil {
+				return nil, err
+			}
+		} else {
+			cs = Text(content, opts)
+		}
+		path := doc.Get(tika.XTIKAEmbeddedResourcePath)
+		for i := range cs {
+			cs[i].Path = path
+		}
+		chunks = append(chunks, cs...)
+	}
+	return chunks, nil
+}
+
+// pack combines consecutive blocks with the same provenance into chunks no
+// larger than opts allows, splitting blocks which are too large on their own.
+func pack(blocks []block, opts Options) []Chunk {
+	var (
+		chunks []Chunk
+		cur    *Chunk
+		tokens int
+	)
+	for _, b := range blocks {
+		for _, piece := range split(b.text, opts) {
+			n := len(strings.Fields(piece))
+			if cur != nil && (cur.Page != b.page || cur.Heading != b.heading || !opts.fits(len(cur.Text)+1+len(piece), tokens+n)) {
+				chunks = append(chunks, *cur)
+				cur = nil
+			}
+			if cur == nil {
+				cur = &Chunk{Text: piece, Page: b.page, Heading: b.heading}
+				tokens = n
+				continue
+			}
+			cur.Text += "\n" + piece
+			tokens += n
+		}
+	}
+	if cur != nil {
+		chunks = append(chunks, *cur)
+	}
+	return chunks
+}
+
+// split splits text into pieces no larger than opts allows, breaking between
+// words where possible.
+func split(text string, opts Options) []string {
+	if opts.fits(len(text), len(strings.Fields(text))) {
+		return []string{text}
+	}
+	var pieces []string
+	var cur string
+	var n int
+	for _, w := range strings.Fields(text) {
+		if cur != "" && opts.fits(len(cur)+1+len(w), n+1) {
+			cur += " " + w
+			n++
+			continue
+		}
+		if cur != "" {
+			pieces = append(pieces, cur)
+		}
+		cur, n = w, 1
+		for opts.MaxBytes > 0 && len(cur) > opts.MaxBytes {
+			i := opts.MaxBytes
+			for i > 0 && !utf8.RuneStart(cur[i]) {
+				i--
+			}
+			if i == 0 {
+				_, i = utf8.DecodeRuneInString(cur)
+			}
+			pieces = append(pieces, cur[:i])
+			cur = cur[i:]
+		}
+	}
+	if cur != "" {
+		pieces = append(pieces, cur)
+	}
+	return pieces
+}
+
+// fits reports whether text of the given size is within the limits of o.
+func (o Options) fits(bytes, tokens int) bool {
+	return (o.MaxBytes <= 0 || bytes <= o.MaxBytes) && (o.MaxTokens <= 0 || tokens <= o.MaxTokens)
+}
+
+// normalize collapses runs of whitespace in s to single spaces.
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func hasClass(e xml.StartElement, class string) bool {
+	for _, a := range e.Attr {
+		if a.Name.Local == "class" {
+			for _, c := range strings.Fields(a.Value) {
+				if c == class {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isHeading(name string) bool {
+	return len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6'
+}
+
+func isBlock(name string) bool {
+	switch name {
+	case "p", "div", "li", "td", "th", "tr", "pre", "blockquote", "br", "table", "ul", "ol":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chunk
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tika/tika"
+)
+
+const xhtml = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>Ignored</title><meta name="x" content="y"/></head>
+<body>
+<div class="page"><h1>Intro</h1><p>One two three.</p><p>Four&amp;five.</p></div>
+<div class="page"><p>Six seven.</p><h2>Details</h2><p>Eight.</p></div>
+</body></html>`
+
+func TestXHTML(t *testing.T) {
+	got, err := XHTML(strings.NewReader(xhtml), Options{})
+	if err != nil {
+		t.Fatalf("XHTML returned an error: %v", err)
+	}
+	want := []Chunk{
+		{Text: "Intro\nOne two three.\nFour&five.", Page: 1, Heading: "Intro"},
+		{Text: "Six seven.", Page: 2, Heading: "Intro"},
+		{Text: "Details\nEight.", Page: 2, Heading: "Details"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("XHTML got %+v, want %+v", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts Options
+		want []string
+	}{
+		{
+			name: "no limit",
+			in:   "a b\n\nc d",
+			want: []string{"a b\nc d"},
+		},
+		{
+			name: "tokens",
+			in:   "a b c\n\nd e",
+			opts: Options{MaxTokens: 3},
+			want: []string{"a b c", "d e"},
+		},
+		{
+			name: "split paragraph",
+			in:   "a b c d e",
+			opts: Options{MaxTokens: 2},
+			want: []string{"a b", "c d", "e"},
+		},
+		{
+			name: "bytes",
+			in:   "abc de\n\nf",
+			opts: Options{MaxBytes: 4},
+			want: []string{"abc", "de\nf"},
+		},
+		{
+			name: "long word",
+			in:   "aébcdef",
+			opts: Options{MaxBytes: 2},
+			want: []string{"a", "é", "bc", "de", "f"},
+		},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, c := range Text(test.in, test.opts) {
+			got = append(got, c.Text)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Text(%s) got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	docs := []tika.Metadata{
+		{tika.XTIKAContent: {xhtml}},
+		{tika.XTIKAContent: {"embedded text"}, tika.XTIKAEmbeddedResourcePath: {"/a.txt"}},
+	}
+	got, err := Documents(docs, Options{})
+	if err != nil {
+		t.Fatalf("Documents returned an error: %v", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("Documents got %d chunks, want 4", len(got))
+	}
+	want := Chunk{Text: "embedded text", Path: "/a.txt"}
+	if got[3] != want {
+		t.Errorf("Documents got %+v, want %+v", got[3], want)
+	}
+}
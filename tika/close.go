@@ -0,0 +1,195 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrClientClosed is returned by the methods of a Client after Close or
+// Shutdown has been called.
+var ErrClientClosed = errors.New("tika: client closed")
+
+// DefaultCloseTimeout is how long Close waits for requests in flight.
+const DefaultCloseTimeout = 30 * time.Second
+
+// inflight tracks the requests in flight of a Client, so it can be shut down
+// without truncating them, or abort them all with CancelAll.
+type inflight struct {
+	mu      sync.Mutex
+	next    int
+	cancels map[int]context.CancelFunc // cancels holds a function per request.
+	closed  bool
+	idle    chan struct{} // idle is closed when no request is left after closing.
+}
+
+// add registers a new request made with ctx, or returns ErrClientClosed. It
+// returns a context for the request, canceled by cancelAll, and a function
+// canceling it and unregistering the request, which may be called several
+// times.
+func (f *inflight) add(ctx context.Context) (context.Context, func(), error) {
+	if f == nil {
+		return ctx, func() {}, nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, nil, ErrClientClosed
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	if f.cancels == nil {
+		f.cancels = make(map[int]context.CancelFunc)
+	}
+	id := f.next
+	f.next++
+	f.cancels[id] = cancel
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			cancel()
+			f.done(id)
+		})
+	}, nil
+}
+
+// done unregisters the request with the given id.
+func (f *inflight) done(id int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.cancels, id)
+	if f.closed && len(f.cancels) == 0 {
+		close(f.idle)
+	}
+}
+
+// close stops new requests and returns a channel closed once none is in
+// flight.
+func (f *inflight) close() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		f.idle = make(chan struct{})
+		if len(f.cancels) == 0 {
+			close(f.idle)
+		}
+	}
+	return f.idle
+}
+
+// cancelAll cancels the contexts of the requests in flight and returns how
+// many there were. The requests stay registered until their bodies are
+// closed.
+func (f *inflight) cancelAll() int {
+	if f == nil {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, cancel := range f.cancels {
+		cancel()
+	}
+	return len(f.cancels)
+}
+
+// trackedBody is a response body that unregisters its request when closed.
+// Reads failing because the request was canceled return the context error,
+// so that callers can check for it with errors.Is.
+type trackedBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	release func()
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.ctx.Err() != nil {
+		err = b.ctx.Err()
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// cancelBody is a request body which stops reading from the input once the
+// request is canceled, so that the caller can reuse or close the input as
+// soon as the call returns, even if the transport is still sending it.
+type cancelBody struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (b *cancelBody) Read(p []byte) (int, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return b.ReadCloser.Read(p)
+}
+
+// CancelAll aborts every request of c in flight, as if their contexts had
+// been canceled: uploads and downloads stop, and the calls, or reads of their
+// responses, return errors matching context.Canceled. Unlike Shutdown, c
+// remains usable for new requests. CancelAll returns the number of requests
+// aborted; their resources are released once their responses are closed,
+// which the methods of c returning strings do themselves.
+func (c *Client) CancelAll() int {
+	return c.inflight.cancelAll()
+}
+
+// Shutdown gracefully shuts c down: new calls fail with ErrClientClosed, and
+// Shutdown waits until the requests in flight are done, that is until their
+// responses have been read and closed, or until ctx is done. It then closes
+// the idle connections of the transports c created itself, for FetchURL,
+// WithProxy, or WithConnectionRefresh; those of the http.Client passed to
+// NewClient, which may be shared, such as http.DefaultClient, are left
+// open. If ctx is done first, Shutdown returns ctx.Err() and the requests
+// still in flight are not interrupted.
+func (c *Client) Shutdown(ctx context.Context) error {
+	if c.inflight == nil {
+		return nil
+	}
+	var err error
+	select {
+	case <-c.inflight.close():
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+	c.fetchMu.Lock()
+	if c.fetchClient != nil {
+		c.fetchClient.CloseIdleConnections()
+	}
+	c.fetchMu.Unlock()
+	return err
+}
+
+// Close is like Shutdown, waiting at most DefaultCloseTimeout.
+func (c *Client) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCloseTimeout)
+	defer cancel()
+	return c.Shutdown(ctx)
+}
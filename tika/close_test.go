@@ -0,0 +1,233 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		io.Copy(w, r.Body)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+
+	parsed := make(chan error, 1)
+	go func() {
+		got, err := c.Parse(context.Background(), strings.NewReader("in flight"))
+		if err == nil && got != "in flight" {
+			err = errors.New("got " + got)
+		}
+		parsed <- err
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown with a request in flight got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := c.Parse(context.Background(), strings.NewReader("new")); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Parse after Shutdown got error %v, want %v", err, ErrClientClosed)
+	}
+	if _, err := c.Do(context.Background(), "GET", "/version", nil, nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Do after Shutdown got error %v, want %v", err, ErrClientClosed)
+	}
+
+	close(release)
+	if err := <-parsed; err != nil {
+		t.Errorf("Parse in flight got error: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close got error: %v", err)
+	}
+}
+
+func TestShutdownDo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "body")
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	resp, err := c.Do(context.Background(), "GET", "/version", nil, nil)
+	if err != nil {
+		t.Fatalf("Do got error: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- c.Shutdown(context.Background()) }()
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown returned %v before the response was closed", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	resp.Body.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown got error: %v", err)
+	}
+}
+
+func TestShutdownSharedClient(t *testing.T) {
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "1.0")
+	}))
+	ts.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	for _, opts := range [][]Option{nil, {WithProxy(ProxyConfig{})}, {WithConnectionRefresh(time.Hour)}} {
+		atomic.StoreInt32(&conns, 0)
+		hc := &http.Client{Transport: &http.Transport{}}
+		shared := NewClient(hc, ts.URL)
+		if _, err := shared.Version(context.Background()); err != nil {
+			t.Fatalf("Version got error: %v", err)
+		}
+		c := NewClient(hc, ts.URL, opts...)
+		if _, err := c.Version(context.Background()); err != nil {
+			t.Fatalf("Version got error: %v", err)
+		}
+		if err := c.Close(); err != nil {
+			t.Errorf("Close got error: %v", err)
+		}
+		if _, err := shared.Version(context.Background()); err != nil {
+			t.Fatalf("Version got error: %v", err)
+		}
+		// The shared transport has one connection, and the clones of
+		// WithProxy and WithConnectionRefresh another one.
+		want := int32(1 + len(opts))
+		if got := atomic.LoadInt32(&conns); got != want {
+			t.Errorf("%d options: server got %d connections after closing a Client sharing the http.Client, want %d", len(opts), got, want)
+		}
+	}
+}
+
+// endlessReader returns zeros forever, counting its reads.
+type endlessReader struct {
+	reads int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	atomic.AddInt64(&r.reads, 1)
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestCancelAll(t *testing.T) {
+	entered := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Upload") != "" {
+			// Read the endless upload until the client gives up.
+			entered <- "upload"
+			io.Copy(ioutil.Discard, r.Body)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != "download" {
+			w.Write(b)
+			return
+		}
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		entered <- "download"
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+
+	if n := c.CancelAll(); n != 0 {
+		t.Errorf("CancelAll with no request in flight = %d, want 0", n)
+	}
+
+	input := &endlessReader{}
+	uploaded := make(chan error, 1)
+	go func() {
+		_, err := c.ParseWithHeader(context.Background(), input, http.Header{"X-Upload": {"1"}})
+		uploaded <- err
+	}()
+	body, err := c.ParseReader(context.Background(), strings.NewReader("download"))
+	if err != nil {
+		t.Fatalf("ParseReader got error: %v", err)
+	}
+	defer body.Close()
+	buf := make([]byte, len("partial"))
+	if _, err := io.ReadFull(body, buf); err != nil {
+		t.Fatalf("reading the response got error: %v", err)
+	}
+	<-entered
+	<-entered
+
+	if n := c.CancelAll(); n != 2 {
+		t.Errorf("CancelAll with two requests in flight = %d, want 2", n)
+	}
+	if err := <-uploaded; !errors.Is(err, context.Canceled) {
+		t.Errorf("upload after CancelAll got error %v, want %v", err, context.Canceled)
+	}
+	reads := atomic.LoadInt64(&input.reads)
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt64(&input.reads); got != reads {
+		t.Errorf("input read %d more times after the upload was canceled", got-reads)
+	}
+	if _, err := body.Read(buf); !errors.Is(err, context.Canceled) {
+		t.Errorf("reading the response after CancelAll got error %v, want %v", err, context.Canceled)
+	}
+	body.Close()
+
+	// The client remains usable.
+	if _, err := c.Parse(context.Background(), strings.NewReader("after")); err != nil {
+		t.Errorf("Parse after CancelAll got error: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close got error: %v", err)
+	}
+}
+
+func TestCanceledContextError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Parse(ctx, &endlessReader{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Parse past its deadline got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close got error: %v", err)
+	}
+}
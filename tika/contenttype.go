@@ -0,0 +1,114 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// WithContentTypeCheck makes the Client reject a successful response whose
+// Content-Type does not match the Accept header of the request with a
+// *ContentTypeError. Methods decoding JSON, such as MetaRecursive, accept
+// only application/json, so an HTML page returned by a proxy in front of the
+// server fails with a descriptive error instead of a JSON syntax error.
+// Requests without an Accept header, and responses of Do, are not checked.
+func WithContentTypeCheck() Option {
+	return func(c *Client) {
+		c.checkContentType = true
+	}
+}
+
+// ContentTypeError is returned by a Client created with WithContentTypeCheck
+// when a response has an unexpected Content-Type.
+type ContentTypeError struct {
+	// ContentType is the Content-Type of the response, or "" if it had none.
+	ContentType string
+	// Accept is the Accept header of the request.
+	Accept string
+	// Message is the start of the body of the response, which often tells
+	// what answered instead of the server.
+	Message string
+	// RequestID is the RequestIDHeader sent with the request.
+	RequestID string
+}
+
+func (e *ContentTypeError) Error() string {
+	msg := fmt.Sprintf("unexpected response content type %q, want %s", e.ContentType, e.Accept)
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+// checkContentType returns a *ContentTypeError, and closes the body, if the
+// Content-Type of resp does not match the Accept header of its request.
+func checkContentType(resp *http.Response) error {
+	accept := resp.Request.Header.Get("Accept")
+	if accept == "" {
+		return nil
+	}
+	ct := resp.Header.Get("Content-Type")
+	if mediaTypeAccepted(ct, accept) {
+		return nil
+	}
+	defer resp.Body.Close()
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorMessage))
+	return &ContentTypeError{
+		ContentType: ct,
+		Accept:      accept,
+		Message:     string(msg),
+		RequestID:   resp.Request.Header.Get(RequestIDHeader),
+	}
+}
+
+// mediaTypeAccepted reports whether the media type of contentType matches
+// one of the media ranges of accept, such as "text/*" or "*/*".
+func mediaTypeAccepted(contentType, accept string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, r := range strings.Split(accept, ",") {
+		r, _, err := mime.ParseMediaType(r)
+		if err != nil {
+			continue
+		}
+		if r == "*/*" || r == mt || strings.HasSuffix(r, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(r, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptJSON returns a copy of header accepting application/json, unless it
+// already sets an Accept header.
+func acceptJSON(header http.Header) http.Header {
+	if header.Get("Accept") != "" {
+		return header
+	}
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Accept", "application/json")
+	return header
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMediaTypeAccepted(t *testing.T) {
+	tests := []struct {
+		contentType, accept string
+		want                bool
+	}{
+		{"application/json", "application/json", true},
+		{"application/json; charset=UTF-8", "application/json", true},
+		{"text/html", "application/json", false},
+		{"text/html; charset=utf-8", "text/plain, text/*;q=0.5", true},
+		{"application/x-tar", "*/*", true},
+		{"", "application/json", false},
+		{"textual/plain", "text/*", false},
+	}
+	for _, test := range tests {
+		if got := mediaTypeAccepted(test.contentType, test.accept); got != test.want {
+			t.Errorf("mediaTypeAccepted(%q, %q) = %v, want %v", test.contentType, test.accept, got, test.want)
+		}
+	}
+}
+
+func TestWithContentTypeCheck(t *testing.T) {
+	var contentType string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if strings.HasPrefix(contentType, "text/html") {
+			w.Write([]byte("<html>Proxy login required</html>"))
+			return
+		}
+		w.Write([]byte(`[{"Content-Type": ["text/plain"]}]`))
+	}))
+	defer ts.Close()
+
+	contentType = "text/html"
+	_, err := NewClient(nil, ts.URL).MetaRecursive(context.Background(), strings.NewReader("x"))
+	if err == nil || !strings.Contains(err.Error(), "invalid character '<'") {
+		t.Errorf("MetaRecursive without WithContentTypeCheck got error %v, want a JSON syntax error", err)
+	}
+
+	c := NewClient(nil, ts.URL, WithContentTypeCheck())
+	_, err = c.MetaRecursive(context.Background(), strings.NewReader("x"))
+	var cerr *ContentTypeError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("MetaRecursive got error %v, want a *ContentTypeError", err)
+	}
+	if cerr.ContentType != "text/html" || cerr.Accept != "application/json" || !strings.Contains(cerr.Message, "Proxy login") {
+		t.Errorf("MetaRecursive got %+v", cerr)
+	}
+
+	contentType = "application/json; charset=UTF-8"
+	if _, err := c.MetaRecursive(context.Background(), strings.NewReader("x")); err != nil {
+		t.Errorf("MetaRecursive with a JSON response got error: %v", err)
+	}
+	// Requests without an Accept header are not checked.
+	contentType = "text/html"
+	if _, err := c.Parse(context.Background(), strings.NewReader("x")); err != nil {
+		t.Errorf("Parse got error: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/custom/resource" || r.Method != "POST" || r.Header.Get("X-Test") != "yes" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Echo-ID", r.Header.Get(RequestIDHeader))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("custom:" + string(b)))
+	}))
+	defer ts.Close()
+
+	s := &Stats{}
+	c := NewClient(nil, ts.URL, WithStats(s), WithMaxResponseBytes(10))
+	ctx := ContextWithRequestID(context.Background(), "id-1")
+	resp, err := c.Do(ctx, "POST", "/custom/resource", strings.NewReader("body"), http.Header{"X-Test": {"yes"}})
+	if err != nil {
+		t.Fatalf("Do returned an error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Do got status %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if got := resp.Header.Get("X-Echo-ID"); got != "id-1" {
+		t.Errorf("Do sent request ID %q, want %q", got, "id-1")
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if !errors.Is(err, ErrResponseTooLarge) || string(b) != "custom:bod" {
+		t.Errorf("Do body got %q, %v, want %q, %v", b, err, "custom:bod", ErrResponseTooLarge)
+	}
+	if snap := s.Snapshot(); snap.Requests != 1 || snap.BytesSent != 4 {
+		t.Errorf("Do recorded %d requests, %d bytes sent, want 1, 4", snap.Requests, snap.BytesSent)
+	}
+}
+
+func TestDoCircuitBreaker(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, ts.URL, WithCircuitBreaker(1, time.Minute))
+	resp, err := c.Do(context.Background(), "GET", "/", nil, nil)
+	if err != nil {
+		t.Fatalf("Do returned an error: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := c.Do(context.Background(), "GET", "/", nil, nil); err != ErrCircuitOpen {
+		t.Errorf("Do got error %v after a 500, want ErrCircuitOpen", err)
+	}
+}
@@ -25,7 +25,8 @@ have a server that is already running, and you don't have the Server
 JAR already downloaded, you can download one. The caller is responsible
 for removing the file when no longer needed.
 
-Version is a custom type, and should be passed as such. There are constants in the code for these.
+Version is a custom type, and should be passed as such. There are constants in the code for these,
+SupportedVersions lists them, and ParseVersion resolves strings such as "1.21" or "latest".
 The following example downloads version 1.21 to the named JAR in the
 current working directory.
 
@@ -0,0 +1,146 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// An Email is a parsed email message. See ParseEmail.
+type Email struct {
+	From    string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	Subject string
+	// Date is the date the message was sent, or the zero time if it is
+	// unknown.
+	Date time.Time
+	// Text is the plain text body of the message. If the message has no
+	// plain text part, it is the text of the HTML body.
+	Text string
+	// HTML is the XHTML rendering, by Tika, of the HTML body of the message,
+	// or "" if there is none.
+	HTML string
+	// Attachments are the files attached to the message.
+	Attachments []Attachment
+	// Metadata is the metadata of the message itself.
+	Metadata Metadata
+}
+
+// An Attachment is a file attached to an Email.
+type Attachment struct {
+	// Name is the file name of the attachment.
+	Name string
+	// MIMEType is the detected MIME type of the attachment.
+	MIMEType string
+	// Path is the embedded resource path of the attachment, which can be
+	// used to find it in the results of Unpack or MetaRecursive.
+	Path string
+	// Text is the extracted text of the attachment.
+	Text string
+	// Metadata is the metadata of the attachment.
+	Metadata Metadata
+}
+
+// ParseEmail parses an email message, such as an RFC 822 .eml or an Outlook
+// .msg file, in a single request. Only direct attachments of the message are
+// returned; files nested inside attachments are available from
+// MetaRecursive.
+func (c *Client) ParseEmail(ctx context.Context, input io.Reader) (*Email, error) {
+	var docs []Metadata
+	err := c.MetaRecursiveStream(ctx, input, "html", func(m Metadata) error {
+		docs = append(docs, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	e := &Email{}
+	if len(docs) == 0 {
+		return e, nil
+	}
+	m := docs[0]
+	e.Metadata = m
+	e.From = firstOf(m, "Message-From", "dc:creator")
+	e.To = m["Message-To"]
+	e.Cc = m["Message-Cc"]
+	e.Bcc = m["Message-Bcc"]
+	e.Subject = firstOf(m, "dc:title", "subject")
+	e.Date = parseTime(firstOf(m, "dcterms:created", "Creation-Date"))
+
+	var text string
+	for _, d := range docs[1:] {
+		path := d.Get(XTIKAEmbeddedResourcePath)
+		if strings.Count(path, "/") != 1 {
+			continue
+		}
+		mimeType := baseMIMEType(d.Get("Content-Type"))
+		name := d.Get("resourceName")
+		switch {
+		case name == "" && mimeType == "text/plain" && text == "":
+			if text, err = d.Text(); err != nil {
+				return nil, err
+			}
+		case name == "" && mimeType == "text/html" && e.HTML == "":
+			e.HTML = d.Get(XTIKAContent)
+		default:
+			t, err := d.Text()
+			if err != nil {
+				return nil, err
+			}
+			e.Attachments = append(e.Attachments, Attachment{Name: name, MIMEType: mimeType, Path: path, Text: t, Metadata: d})
+		}
+	}
+	switch {
+	case text != "":
+		e.Text = text
+	case e.HTML != "":
+		if e.Text, err = XHTMLToText(strings.NewReader(e.HTML)); err != nil {
+			return nil, err
+		}
+	default:
+		if e.Text, err = m.Text(); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// firstOf returns the first value of the first of keys present in m.
+func firstOf(m Metadata, keys ...string) string {
+	for _, k := range keys {
+		if v := m.Get(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseTime parses a date in one of the formats Tika uses, returning the
+// zero time if s cannot be parsed.
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
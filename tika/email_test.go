@@ -0,0 +1,78 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseEmail(t *testing.T) {
+	var gotPath string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprint(w, `[
+			{"Content-Type":"message/rfc822","Message-From":"Ann <ann@example.com>","Message-To":["bob@example.com","cy@example.com"],
+			 "Message-Cc":"dee@example.com","dc:title":"Hello","dcterms:created":"2017-06-01T10:00:00Z",
+			 "X-TIKA:content":"<html><body><p>Hi Bob</p></body></html>"},
+			{"Content-Type":"text/html; charset=UTF-8","X-TIKA:embedded_resource_path":"/embedded-1",
+			 "X-TIKA:content":"<html><body><p><b>Hi</b> Bob</p></body></html>"},
+			{"Content-Type":"application/pdf","resourceName":"report.pdf","X-TIKA:embedded_resource_path":"/report.pdf",
+			 "X-TIKA:content":"<html><body><p>Report</p></body></html>"},
+			{"Content-Type":"image/png","resourceName":"chart.png","X-TIKA:embedded_resource_path":"/report.pdf/chart.png"}
+		]`)
+	}))
+	defer ts.Close()
+
+	e, err := NewClient(nil, ts.URL).ParseEmail(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ParseEmail returned an error: %v", err)
+	}
+	if gotPath != "/rmeta/html" {
+		t.Errorf("ParseEmail requested %q, want /rmeta/html", gotPath)
+	}
+	if e.From != "Ann <ann@example.com>" || e.Subject != "Hello" {
+		t.Errorf("ParseEmail got From %q, Subject %q", e.From, e.Subject)
+	}
+	if want := []string{"bob@example.com", "cy@example.com"}; !reflect.DeepEqual(e.To, want) {
+		t.Errorf("ParseEmail got To %q, want %q", e.To, want)
+	}
+	if want := []string{"dee@example.com"}; !reflect.DeepEqual(e.Cc, want) {
+		t.Errorf("ParseEmail got Cc %q, want %q", e.Cc, want)
+	}
+	if want := time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC); !e.Date.Equal(want) {
+		t.Errorf("ParseEmail got Date %v, want %v", e.Date, want)
+	}
+	if e.Text != "Hi Bob" {
+		t.Errorf("ParseEmail got Text %q, want %q", e.Text, "Hi Bob")
+	}
+	if e.HTML == "" {
+		t.Error("ParseEmail got no HTML body")
+	}
+	if len(e.Attachments) != 1 {
+		t.Fatalf("ParseEmail got %d attachments, want 1", len(e.Attachments))
+	}
+	a := e.Attachments[0]
+	if a.Name != "report.pdf" || a.MIMEType != "application/pdf" || a.Path != "/report.pdf" || a.Text != "Report" {
+		t.Errorf("ParseEmail got attachment %+v", a)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+)
+
+// A DocResult is a parsed document passed to an Emitter.
+type DocResult struct {
+	// Name identifies the document, for example the Name of its Input.
+	Name string
+	// Content is the extracted text of the document.
+	Content string
+	// Metadata is the metadata of the document, if any.
+	Metadata Metadata
+}
+
+// An Emitter receives parsed documents, for example to store or index them.
+// Implementations are in the emit package.
+type Emitter interface {
+	Emit(ctx context.Context, r DocResult) error
+}
+
+// EmitterFunc adapts a function to an Emitter.
+type EmitterFunc func(ctx context.Context, r DocResult) error
+
+// Emit calls f(ctx, r).
+func (f EmitterFunc) Emit(ctx context.Context, r DocResult) error {
+	return f(ctx, r)
+}
+
+// Emit parses every Input produced by inputs and passes each document to e.
+// Emit stops and returns the first error encountered while opening, parsing,
+// or emitting a document. Emit calls e from a single goroutine. Documents
+// have Metadata only if b.Metadata is true. Skipped
+// inputs, duplicates, and the Results of a dry run are not emitted.
+func (b *Batch) Emit(ctx context.Context, inputs func(yield func(Input) bool), e Emitter) error {
+	var emitErr error
+	err := b.Run(ctx, inputs, func(r Result, err error) bool {
+		if err == nil && !r.Skipped && r.DuplicateOf == "" && !b.DryRun {
+			err = e.Emit(ctx, DocResult{Name: r.Name, Content: r.Content, Metadata: r.Metadata})
+		}
+		if err != nil {
+			emitErr = fmt.Errorf("%s: %w", r.Name, err)
+			return false
+		}
+		return true
+	})
+	if emitErr != nil {
+		return emitErr
+	}
+	return err
+}
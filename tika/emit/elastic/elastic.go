@@ -0,0 +1,256 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package elastic indexes documents parsed by Apache Tika in Elasticsearch or
+// OpenSearch using the bulk API.
+//
+// An Indexer buffers Documents and sends them in bulk requests. A Batch with
+// Metadata set provides the metadata indexed using the FieldMap:
+//
+//	ix := &elastic.Indexer{URL: "http://localhost:9200", Index: "docs"}
+//	b := &tika.Batch{Client: c, Metadata: true}
+//	err := b.Run(ctx, tika.Walk(dir), func(r tika.Result, err error) bool {
+//		if err != nil {
+//			log.Print(err)
+//			return true
+//		}
+//		if err := ix.Add(ctx, elastic.FromResult(r)); err != nil {
+//			log.Print(err)
+//		}
+//		return true
+//	})
+//	...
+//	err = ix.Flush(ctx)
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/go-tika/tika"
+)
+
+// A Document is a parsed document to be indexed.
+type Document struct {
+	// ID is the document ID. If ID is empty, the server assigns one.
+	ID string
+	// Content is the extracted text of the document.
+	Content string
+	// Metadata is the metadata of the document.
+	Metadata tika.Metadata
+}
+
+// FromResult returns a Document for a tika.Result, using its Name as the ID.
+// The Result has Metadata only if it comes from a tika.Batch with Metadata
+// set.
+func FromResult(r tika.Result) Document {
+	return Document{ID: r.Name, Content: r.Content, Metadata: r.Metadata}
+}
+
+// ContentField is the field the Content of a Document is indexed in.
+const ContentField = "content"
+
+// DefaultFieldMap maps common Tika metadata keys to index field names.
+var DefaultFieldMap = map[string]string{
+	"Content-Type":             "content_type",
+	"Content-Length":           "content_length",
+	"Content-Language":         "language",
+	"dc:title":                 "title",
+	"dc:creator":               "author",
+	"dc:subject":               "subject",
+	"dc:description":           "description",
+	"dcterms:created":          "created",
+	"dcterms:modified":         "modified",
+	"meta:keyword":             "keywords",
+	"xmpTPg:NPages":            "pages",
+	"resourceName":             "filename",
+	"X-TIKA:parse_time_millis": "parse_time_millis",
+}
+
+// Source returns the indexed fields of doc. Metadata keys are renamed using
+// fields, and keys not in fields are omitted. Keys with a single value are
+// indexed as a string and keys with several values as an array.
+func Source(doc Document, fields map[string]string) map[string]interface{} {
+	src := map[string]interface{}{ContentField: doc.Content}
+	for k, v := range doc.Metadata {
+		name, ok := fields[k]
+		if !ok || len(v) == 0 {
+			continue
+		}
+		if len(v) == 1 {
+			src[name] = v[0]
+		} else {
+			src[name] = v
+		}
+	}
+	return src
+}
+
+// WriteBulk writes a bulk request body indexing docs into index to w. If
+// fields is nil, DefaultFieldMap is used.
+func WriteBulk(w io.Writer, index string, fields map[string]string, docs ...Document) error {
+	if fields == nil {
+		fields = DefaultFieldMap
+	}
+	enc := json.NewEncoder(w)
+	for _, doc := range docs {
+		action := map[string]string{"_index": index}
+		if doc.ID != "" {
+			action["_id"] = doc.ID
+		}
+		if err := enc.Encode(map[string]interface{}{"index": action}); err != nil {
+			return err
+		}
+		if err := enc.Encode(Source(doc, fields)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// An ItemError describes a Document the server failed to index.
+type ItemError struct {
+	ID     string
+	Status int
+	Reason string
+}
+
+// BulkError is returned by Flush when some Documents could not be indexed.
+type BulkError struct {
+	Items []ItemError
+}
+
+func (e *BulkError) Error() string {
+	if len(e.Items) == 0 {
+		return "bulk request failed"
+	}
+	first := e.Items[0]
+	return fmt.Sprintf("%d documents failed to index, first %q: %d %s", len(e.Items), first.ID, first.Status, first.Reason)
+}
+
+// An Indexer sends Documents to an Elasticsearch or OpenSearch server in bulk
+// requests. It is safe for concurrent use.
+type Indexer struct {
+	// URL is the base URL of the server, for example http://localhost:9200.
+	URL string
+	// Index is the name of the index documents are added to.
+	Index string
+	// FieldMap maps metadata keys to index field names. If FieldMap is nil,
+	// DefaultFieldMap is used.
+	FieldMap map[string]string
+	// BatchSize is the number of Documents sent in each bulk request. If
+	// BatchSize is less than or equal to 0, 500 is used.
+	BatchSize int
+	// HTTPClient is used to make requests. If HTTPClient is nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	pending []Document
+}
+
+// Add buffers doc, sending a bulk request once BatchSize Documents are
+// buffered.
+func (ix *Indexer) Add(ctx context.Context, doc Document) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.pending = append(ix.pending, doc)
+	size := ix.BatchSize
+	if size <= 0 {
+		size = 500
+	}
+	if len(ix.pending) < size {
+		return nil
+	}
+	return ix.flush(ctx)
+}
+
+// Flush sends any buffered Documents. It must be called once all Documents
+// have been added.
+func (ix *Indexer) Flush(ctx context.Context) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.flush(ctx)
+}
+
+func (ix *Indexer) flush(ctx context.Context) error {
+	if len(ix.pending) == 0 {
+		return nil
+	}
+	docs := ix.pending
+	ix.pending = nil
+
+	var body bytes.Buffer
+	if err := WriteBulk(&body, ix.Index, ix.FieldMap, docs...); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(ix.URL, "/")+"/_bulk", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	client := ix.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bulk request: %s", resp.Status)
+	}
+
+	var r struct {
+		Errors bool
+		Items  []map[string]struct {
+			ID     string `json:"_id"`
+			Status int
+			Error  struct {
+				Type   string
+				Reason string
+			}
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("decoding bulk response: %v", err)
+	}
+	if !r.Errors {
+		return nil
+	}
+	e := &BulkError{}
+	for _, item := range r.Items {
+		for _, res := range item {
+			if res.Status < 200 || res.Status > 299 {
+				e.Items = append(e.Items, ItemError{ID: res.ID, Status: res.Status, Reason: res.Error.Reason})
+			}
+		}
+	}
+	return e
+}
+
+// Emit adds r to ix, so an Indexer can be used as a tika.Emitter. Flush must
+// still be called once every document has been emitted.
+func (ix *Indexer) Emit(ctx context.Context, r tika.DocResult) error {
+	return ix.Add(ctx, Document{ID: r.Name, Content: r.Content, Metadata: r.Metadata})
+}
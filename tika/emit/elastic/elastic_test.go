@@ -0,0 +1,158 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tika/tika"
+)
+
+func TestWriteBulk(t *testing.T) {
+	doc := Document{
+		ID:      "a.pdf",
+		Content: "hello",
+		Metadata: tika.Metadata{
+			"dc:creator":   {"A", "B"},
+			"Content-Type": {"application/pdf"},
+			"unmapped":     {"x"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteBulk(&buf, "docs", nil, doc, Document{Content: "no id"}); err != nil {
+		t.Fatalf("WriteBulk returned an error: %v", err)
+	}
+	want := `{"index":{"_id":"a.pdf","_index":"docs"}}
+{"author":["A","B"],"content":"hello","content_type":"application/pdf"}
+{"index":{"_index":"docs"}}
+{"content":"no id"}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteBulk got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" {
+			t.Errorf("request path got %q, want /_bulk", r.URL.Path)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/x-ndjson" {
+			t.Errorf("request Content-Type got %q, want application/x-ndjson", got)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, string(b))
+		fmt.Fprint(w, `{"errors":false,"items":[]}`)
+	}))
+	defer ts.Close()
+
+	ix := &Indexer{URL: ts.URL, Index: "docs", BatchSize: 2}
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := ix.Add(ctx, Document{Content: "x"}); err != nil {
+			t.Fatalf("Add returned an error: %v", err)
+		}
+	}
+	if len(requests) != 1 {
+		t.Errorf("Add sent %d requests, want 1", len(requests))
+	}
+	if err := ix.Flush(ctx); err != nil {
+		t.Fatalf("Flush returned an error: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Flush sent %d requests, want 2", len(requests))
+	}
+	if n := strings.Count(requests[1], "\n"); n != 2 {
+		t.Errorf("second request has %d lines, want 2", n)
+	}
+}
+
+func TestFromResultBatch(t *testing.T) {
+	tikaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"dc:title": ["Report"], "Content-Type": ["application/pdf"], "X-TIKA:content": ["hello"]}]`)
+	}))
+	defer tikaServer.Close()
+	var body string
+	esServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		fmt.Fprint(w, `{"errors":false,"items":[]}`)
+	}))
+	defer esServer.Close()
+
+	ix := &Indexer{URL: esServer.URL, Index: "docs"}
+	b := &tika.Batch{Client: tika.NewClient(nil, tikaServer.URL), Metadata: true}
+	ctx := context.Background()
+	input := tika.Input{Name: "a.pdf", Open: func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("%PDF")), nil
+	}}
+	err := b.Run(ctx, tika.Inputs(input), func(r tika.Result, err error) bool {
+		if err != nil {
+			t.Errorf("Run got error: %v", err)
+			return true
+		}
+		if err := ix.Add(ctx, FromResult(r)); err != nil {
+			t.Errorf("Add returned an error: %v", err)
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	if err := ix.Flush(ctx); err != nil {
+		t.Fatalf("Flush returned an error: %v", err)
+	}
+	want := `{"index":{"_id":"a.pdf","_index":"docs"}}
+{"content":"hello","content_type":"application/pdf","title":"Report"}
+`
+	if body != want {
+		t.Errorf("bulk body got\n%s\nwant\n%s", body, want)
+	}
+}
+
+func TestIndexerItemErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errors":true,"items":[
+			{"index":{"_id":"a","status":201}},
+			{"index":{"_id":"b","status":400,"error":{"type":"mapper_parsing_exception","reason":"bad field"}}}
+		]}`)
+	}))
+	defer ts.Close()
+
+	ix := &Indexer{URL: ts.URL, Index: "docs"}
+	ix.Add(context.Background(), Document{ID: "a"})
+	ix.Add(context.Background(), Document{ID: "b"})
+	err := ix.Flush(context.Background())
+	var be *BulkError
+	if !errors.As(err, &be) {
+		t.Fatalf("Flush got error %v, want a *BulkError", err)
+	}
+	want := []ItemError{{ID: "b", Status: 400, Reason: "bad field"}}
+	if len(be.Items) != 1 || be.Items[0] != want[0] {
+		t.Errorf("BulkError.Items got %+v, want %+v", be.Items, want)
+	}
+}
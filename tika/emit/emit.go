@@ -0,0 +1,198 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package emit provides tika.Emitter implementations which write parsed
+// documents to files, NDJSON streams, and SQL databases.
+package emit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/go-tika/tika"
+)
+
+// Files writes the content of each document to a plain text file.
+type Files struct {
+	// Dir is the directory files are written under. The file of a document
+	// is Dir joined with the document Name and Ext, so documents named by
+	// relative paths keep their directory structure.
+	Dir string
+	// Ext is appended to the name of every file. If Ext is empty, ".txt" is
+	// used.
+	Ext string
+}
+
+// Emit writes the content of r to its file, creating directories as needed.
+func (f *Files) Emit(ctx context.Context, r tika.DocResult) error {
+	name, err := f.path(r.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, []byte(r.Content), 0644)
+}
+
+// path returns the file name for the document named name, which must not
+// escape Dir.
+func (f *Files) path(name string) (string, error) {
+	ext := f.Ext
+	if ext == "" {
+		ext = ".txt"
+	}
+	// Cleaning a rooted path removes any leading "..", so the result stays
+	// under Dir.
+	rel := path.Clean("/" + filepath.ToSlash(strings.TrimPrefix(name, filepath.VolumeName(name))))[1:]
+	if rel == "" {
+		return "", fmt.Errorf("invalid document name %q", name)
+	}
+	return filepath.Join(f.Dir, filepath.FromSlash(rel)+ext), nil
+}
+
+// NDJSON writes each document as a line of JSON with name, content, and
+// metadata fields. It is safe for concurrent use.
+type NDJSON struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewNDJSON returns an NDJSON that writes to w.
+func NewNDJSON(w io.Writer) *NDJSON {
+	return &NDJSON{enc: json.NewEncoder(w)}
+}
+
+// record is the JSON form of a tika.DocResult.
+type record struct {
+	Name     string        `json:"name"`
+	Content  string        `json:"content"`
+	Metadata tika.Metadata `json:"metadata,omitempty"`
+}
+
+// Emit writes r as a line of JSON.
+func (n *NDJSON) Emit(ctx context.Context, r tika.DocResult) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.enc.Encode(record{r.Name, r.Content, r.Metadata})
+}
+
+// A Dialect is the SQL dialect of a database, which determines the column
+// types, identifier quoting, placeholders, and upsert statement of SQL.
+type Dialect int
+
+// Dialects supported by SQL.
+const (
+	SQLite Dialect = iota
+	MySQL
+	PostgreSQL
+)
+
+// SQL inserts each document as a row of a table with name, content, and
+// metadata columns. Metadata is stored as JSON text, or NULL for documents
+// without metadata, such as those of a tika.Batch without Metadata set.
+// SQL does not depend on a driver: register one for the Dialect, for
+// example a SQLite one, and open DB with it.
+type SQL struct {
+	DB *sql.DB
+	// Dialect is the dialect of DB. The zero Dialect is SQLite.
+	Dialect Dialect
+	// Table is the name of the table, optionally qualified by a schema
+	// name, made of letters, digits, and underscores. If Table is empty,
+	// "documents" is used.
+	Table string
+}
+
+// identifier matches the unquoted table names SQL accepts.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// table returns the quoted table name.
+func (s *SQL) table() (string, error) {
+	name := s.Table
+	if name == "" {
+		name = "documents"
+	}
+	if !identifier.MatchString(name) {
+		return "", fmt.Errorf("invalid table name %q", name)
+	}
+	q := `"`
+	if s.Dialect == MySQL {
+		q = "`"
+	}
+	return q + strings.Replace(name, ".", q+"."+q, 1) + q, nil
+}
+
+// CreateTable creates the table if it does not exist.
+func (s *SQL) CreateTable(ctx context.Context) error {
+	table, err := s.table()
+	if err != nil {
+		return err
+	}
+	// MySQL cannot index TEXT columns without a prefix length. 768
+	// characters of utf8mb4 are the longest InnoDB key.
+	columns := "name TEXT PRIMARY KEY, content TEXT, metadata TEXT"
+	if s.Dialect == MySQL {
+		columns = "name VARCHAR(768) PRIMARY KEY, content LONGTEXT, metadata LONGTEXT"
+	}
+	_, err = s.DB.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, columns))
+	return err
+}
+
+// Emit inserts r, replacing any existing row with the same name.
+func (s *SQL) Emit(ctx context.Context, r tika.DocResult) error {
+	table, err := s.table()
+	if err != nil {
+		return err
+	}
+	var meta interface{}
+	if r.Metadata != nil {
+		b, err := json.Marshal(r.Metadata)
+		if err != nil {
+			return err
+		}
+		meta = string(b)
+	}
+	query := fmt.Sprintf("REPLACE INTO %s (name, content, metadata) VALUES (?, ?, ?)", table)
+	if s.Dialect == PostgreSQL {
+		query = fmt.Sprintf("INSERT INTO %s (name, content, metadata) VALUES ($1, $2, $3) "+
+			"ON CONFLICT (name) DO UPDATE SET content = EXCLUDED.content, metadata = EXCLUDED.metadata", table)
+	}
+	_, err = s.DB.ExecContext(ctx, query, r.Name, r.Content, meta)
+	return err
+}
+
+// Multi returns an Emitter which passes every document to each of emitters in
+// turn, stopping at the first error.
+func Multi(emitters ...tika.Emitter) tika.Emitter {
+	return tika.EmitterFunc(func(ctx context.Context, r tika.DocResult) error {
+		for _, e := range emitters {
+			if err := e.Emit(ctx, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package emit
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tika/tika"
+)
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f := &Files{Dir: dir}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"a.pdf", "a.pdf.txt"},
+		{"sub/b.doc", "sub/b.doc.txt"},
+		{"/abs/c", "abs/c.txt"},
+		{"../../escape", "escape.txt"},
+	}
+	for _, test := range tests {
+		if err := f.Emit(ctx, tika.DocResult{Name: test.name, Content: test.name}); err != nil {
+			t.Errorf("Emit(%q) returned an error: %v", test.name, err)
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(test.want)))
+		if err != nil {
+			t.Errorf("Emit(%q) did not write %q: %v", test.name, test.want, err)
+			continue
+		}
+		if string(b) != test.name {
+			t.Errorf("Emit(%q) wrote %q, want %q", test.name, b, test.name)
+		}
+	}
+	if err := f.Emit(ctx, tika.DocResult{Name: "/"}); err == nil {
+		t.Error("Emit with an empty name got no error, want an error")
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	n := NewNDJSON(&buf)
+	ctx := context.Background()
+	n.Emit(ctx, tika.DocResult{Name: "a", Content: "A", Metadata: tika.Metadata{"k": {"v"}}})
+	n.Emit(ctx, tika.DocResult{Name: "b", Content: "B"})
+	want := `{"name":"a","content":"A","metadata":{"k":["v"]}}
+{"name":"b","content":"B"}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("NDJSON wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	var a, b bytes.Buffer
+	errEmit := errors.New("failed")
+	failing := tika.EmitterFunc(func(context.Context, tika.DocResult) error { return errEmit })
+	m := Multi(NewNDJSON(&a), failing, NewNDJSON(&b))
+	if err := m.Emit(context.Background(), tika.DocResult{Name: "a"}); err != errEmit {
+		t.Errorf("Multi got error %v, want %v", err, errEmit)
+	}
+	if a.Len() == 0 || b.Len() != 0 {
+		t.Errorf("Multi wrote %d and %d bytes, want the first emitter only", a.Len(), b.Len())
+	}
+}
+
+// recordingDriver is a database/sql driver which records executed statements.
+type recordingDriver struct {
+	execs []string
+	args  [][]driver.NamedValue
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c recordingConn) Close() error                        { return nil }
+func (c recordingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.execs = append(c.d.execs, query)
+	c.d.args = append(c.d.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func TestSQL(t *testing.T) {
+	d := &recordingDriver{}
+	sql.Register("emit-recording", d)
+	db, err := sql.Open("emit-recording", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		dialect    Dialect
+		table      string
+		wantCreate string
+		wantEmit   string
+	}{
+		{
+			SQLite, "docs",
+			`CREATE TABLE IF NOT EXISTS "docs" (name TEXT PRIMARY KEY, content TEXT, metadata TEXT)`,
+			`REPLACE INTO "docs" (name, content, metadata) VALUES (?, ?, ?)`,
+		},
+		{
+			MySQL, "db.docs",
+			"CREATE TABLE IF NOT EXISTS `db`.`docs` (name VARCHAR(768) PRIMARY KEY, content LONGTEXT, metadata LONGTEXT)",
+			"REPLACE INTO `db`.`docs` (name, content, metadata) VALUES (?, ?, ?)",
+		},
+		{
+			PostgreSQL, "",
+			`CREATE TABLE IF NOT EXISTS "documents" (name TEXT PRIMARY KEY, content TEXT, metadata TEXT)`,
+			`INSERT INTO "documents" (name, content, metadata) VALUES ($1, $2, $3) ON CONFLICT (name) DO UPDATE SET content = EXCLUDED.content, metadata = EXCLUDED.metadata`,
+		},
+	}
+	ctx := context.Background()
+	for _, test := range tests {
+		d.execs, d.args = nil, nil
+		s := &SQL{DB: db, Dialect: test.dialect, Table: test.table}
+		if err := s.CreateTable(ctx); err != nil {
+			t.Fatalf("CreateTable returned an error: %v", err)
+		}
+		if err := s.Emit(ctx, tika.DocResult{Name: "a", Content: "A", Metadata: tika.Metadata{"k": {"v"}}}); err != nil {
+			t.Fatalf("Emit returned an error: %v", err)
+		}
+		if len(d.execs) != 2 {
+			t.Fatalf("got %d statements, want 2", len(d.execs))
+		}
+		if d.execs[0] != test.wantCreate {
+			t.Errorf("CreateTable (dialect %d) executed %q, want %q", test.dialect, d.execs[0], test.wantCreate)
+		}
+		if d.execs[1] != test.wantEmit {
+			t.Errorf("Emit (dialect %d) executed %q, want %q", test.dialect, d.execs[1], test.wantEmit)
+		}
+		var got []interface{}
+		for _, a := range d.args[1] {
+			got = append(got, a.Value)
+		}
+		want := []interface{}{"a", "A", `{"k":["v"]}`}
+		for i := range want {
+			if i >= len(got) || got[i] != want[i] {
+				t.Errorf("Emit args got %v, want %v", got, want)
+				break
+			}
+		}
+	}
+
+	for _, table := range []string{"docs; DROP TABLE users", `a"b`, "a.b.c", "1docs"} {
+		s := &SQL{DB: db, Table: table}
+		if err := s.CreateTable(ctx); err == nil {
+			t.Errorf("CreateTable with table %q got no error, want an error", table)
+		}
+		if err := s.Emit(ctx, tika.DocResult{Name: "a"}); err == nil {
+			t.Errorf("Emit with table %q got no error, want an error", table)
+		}
+	}
+}
+
+// memoryDriver is a database/sql driver keeping tables in memory. It
+// understands the statements of SQL: CREATE TABLE IF NOT EXISTS, REPLACE
+// INTO, and INSERT INTO, with or without ON CONFLICT.
+type memoryDriver struct {
+	tables map[string]map[string][]driver.Value
+}
+
+func (d *memoryDriver) Open(string) (driver.Conn, error) { return memoryConn{d}, nil }
+
+type memoryConn struct{ d *memoryDriver }
+
+func (c memoryConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c memoryConn) Close() error                        { return nil }
+func (c memoryConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c memoryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	f := strings.Fields(query)
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS "):
+		if c.d.tables[f[5]] == nil {
+			c.d.tables[f[5]] = map[string][]driver.Value{}
+		}
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(query, "REPLACE INTO "), strings.HasPrefix(query, "INSERT INTO "):
+		rows := c.d.tables[f[2]]
+		if rows == nil {
+			return nil, fmt.Errorf("no such table: %s", f[2])
+		}
+		name := args[0].Value.(string)
+		if _, ok := rows[name]; ok && f[0] == "INSERT" && !strings.Contains(query, " ON CONFLICT (name) DO UPDATE ") {
+			return nil, fmt.Errorf("duplicate key %q", name)
+		}
+		rows[name] = []driver.Value{args[1].Value, args[2].Value}
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unsupported statement %q", query)
+}
+
+func TestSQLUpsert(t *testing.T) {
+	d := &memoryDriver{tables: map[string]map[string][]driver.Value{}}
+	sql.Register("emit-memory", d)
+	db, err := sql.Open("emit-memory", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, dialect := range []Dialect{SQLite, MySQL, PostgreSQL} {
+		s := &SQL{DB: db, Dialect: dialect, Table: fmt.Sprintf("docs%d", dialect)}
+		if err := s.Emit(ctx, tika.DocResult{Name: "a"}); err == nil {
+			t.Errorf("Emit (dialect %d) before CreateTable got no error, want an error", dialect)
+		}
+		if err := s.CreateTable(ctx); err != nil {
+			t.Fatalf("CreateTable (dialect %d) returned an error: %v", dialect, err)
+		}
+		// Creating the table again keeps its rows.
+		docs := []tika.DocResult{
+			{Name: "a", Content: "old", Metadata: tika.Metadata{"k": {"v"}}},
+			{Name: "b", Content: "B"},
+			{Name: "a", Content: "new", Metadata: tika.Metadata{"k": {"w"}}},
+		}
+		for _, doc := range docs {
+			if err := s.CreateTable(ctx); err != nil {
+				t.Fatalf("CreateTable (dialect %d) returned an error: %v", dialect, err)
+			}
+			if err := s.Emit(ctx, doc); err != nil {
+				t.Fatalf("Emit (dialect %d) of %s returned an error: %v", dialect, doc.Name, err)
+			}
+		}
+		table, _ := s.table()
+		want := map[string][]driver.Value{
+			"a": {"new", `{"k":["w"]}`},
+			"b": {"B", nil},
+		}
+		if got := d.tables[table]; !reflect.DeepEqual(got, want) {
+			t.Errorf("table %s (dialect %d) holds %v, want %v", table, dialect, got, want)
+		}
+	}
+}
+
+func TestBatchEmitMetadata(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"dc:title": ["Report"], "X-TIKA:content": ["hello"]}]`)
+	}))
+	defer ts.Close()
+	var buf bytes.Buffer
+	b := &tika.Batch{Client: tika.NewClient(nil, ts.URL), Metadata: true}
+	input := tika.Input{Name: "a", Open: func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("A")), nil
+	}}
+	if err := b.Emit(context.Background(), tika.Inputs(input), NewNDJSON(&buf)); err != nil {
+		t.Fatalf("Emit returned an error: %v", err)
+	}
+	want := `{"name":"a","content":"hello","metadata":{"dc:title":["Report"]}}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Batch.Emit to NDJSON wrote\n%s\nwant\n%s", got, want)
+	}
+}
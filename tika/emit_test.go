@@ -0,0 +1,52 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestBatchEmit(t *testing.T) {
+	ts := echoServer()
+	defer ts.Close()
+	b := &Batch{Client: NewClient(nil, ts.URL), Workers: 2}
+
+	var got []DocResult
+	e := EmitterFunc(func(ctx context.Context, r DocResult) error {
+		got = append(got, r)
+		return nil
+	})
+	inputs := Inputs(stringInput("a", "A"), stringInput("b", "B"))
+	if err := b.Emit(context.Background(), inputs, e); err != nil {
+		t.Fatalf("Emit returned an error: %v", err)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].Name < got[j].Name })
+	want := []DocResult{{Name: "a", Content: "A"}, {Name: "b", Content: "B"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Emit got %+v, want %+v", got, want)
+	}
+
+	errEmit := errors.New("emit failed")
+	e = EmitterFunc(func(ctx context.Context, r DocResult) error { return errEmit })
+	if err := b.Emit(context.Background(), inputs, e); !errors.Is(err, errEmit) {
+		t.Errorf("Emit got error %v, want %v", err, errEmit)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// A URLProvider returns the URL of the server to send a request to, such as
+// one instance of a fleet found with service discovery. See WithURLProvider.
+type URLProvider func(ctx context.Context) (string, error)
+
+// WithURLProvider makes the Client ask p for the server URL before every
+// request instead of using the URL passed to NewClient, which may then be
+// empty, so the Client follows topology changes without being recreated. p
+// is called concurrently and should be fast, for example by caching the
+// result of a lookup. An error from p fails the request with an error
+// matching ErrServerUnavailable.
+func WithURLProvider(p URLProvider) Option {
+	return func(c *Client) {
+		c.urlProvider = p
+	}
+}
+
+// WithConnectionRefresh closes the idle connections of the Client at most
+// every interval, so that new connections resolve the server host name
+// again. Without it, a Client pointed at a DNS name balancing a fleet of
+// servers keeps using the addresses resolved when its connections were
+// opened. Connections in use are closed once they become idle. As with
+// WithProxy, the Client uses a copy of the http.Client passed to NewClient
+// whose transport is a clone of the original one, so the connections of
+// other users of a shared http.Client, such as http.DefaultClient, are left
+// open.
+//
+// If the http.Client has a Transport other than an *http.Transport, every
+// request fails with an error.
+func WithConnectionRefresh(interval time.Duration) Option {
+	return func(c *Client) {
+		if _, err := c.ownTransport(); err != nil {
+			if c.configErr == nil {
+				c.configErr = fmt.Errorf("cannot refresh connections: %v", err)
+			}
+			return
+		}
+		c.refresh = &connRefresh{interval: interval, last: time.Now().UnixNano()}
+	}
+}
+
+// connRefresh implements WithConnectionRefresh.
+type connRefresh struct {
+	interval time.Duration
+	last     int64 // Unix time of the last refresh, in nanoseconds.
+}
+
+// due reports whether the idle connections must be closed now. It reports
+// true once per interval, even when called concurrently.
+func (r *connRefresh) due(now time.Time) bool {
+	last := atomic.LoadInt64(&r.last)
+	if now.UnixNano()-last < int64(r.interval) {
+		return false
+	}
+	return atomic.CompareAndSwapInt64(&r.last, last, now.UnixNano())
+}
+
+// baseURL returns the URL to send a request to, without the path prefix.
+func (c *Client) baseURL(ctx context.Context) (string, error) {
+	if c.refresh != nil && c.refresh.due(time.Now()) {
+		c.transport.CloseIdleConnections()
+	}
+	if c.urlProvider == nil {
+		return c.url, nil
+	}
+	u, err := c.urlProvider(ctx)
+	if err != nil {
+		return "", unavailableError{fmt.Errorf("could not get server URL: %v", err)}
+	}
+	return strings.TrimSuffix(u, "/"), nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithURLProvider(t *testing.T) {
+	servers := make([]*httptest.Server, 2)
+	for i := range servers {
+		i := i
+		servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "server %d", i)
+		}))
+		defer servers[i].Close()
+	}
+	var n int32
+	c := NewClient(nil, "", WithURLProvider(func(context.Context) (string, error) {
+		i := atomic.AddInt32(&n, 1) - 1
+		if i == 2 {
+			return "", errors.New("discovery down")
+		}
+		return servers[i].URL + "/", nil
+	}))
+	for i := 0; i < 2; i++ {
+		got, err := c.Version(context.Background())
+		if want := fmt.Sprintf("server %d", i); err != nil || got != want {
+			t.Errorf("Version #%d = %q, %v, want %q", i, got, err, want)
+		}
+	}
+	if _, err := c.Version(context.Background()); !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("Version with a failing provider got error %v, want ErrServerUnavailable", err)
+	}
+}
+
+func TestWithConnectionRefresh(t *testing.T) {
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "1.0")
+	}))
+	ts.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	tests := []struct {
+		opts []Option
+		want int32
+	}{
+		{nil, 1},
+		{[]Option{WithConnectionRefresh(time.Nanosecond)}, 3},
+	}
+	for _, test := range tests {
+		atomic.StoreInt32(&conns, 0)
+		c := NewClient(&http.Client{Transport: &http.Transport{}}, ts.URL, test.opts...)
+		for i := 0; i < 3; i++ {
+			if _, err := c.Version(context.Background()); err != nil {
+				t.Fatalf("Version got error: %v", err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if got := atomic.LoadInt32(&conns); got != test.want {
+			t.Errorf("%d options: server got %d connections, want %d", len(test.opts), got, test.want)
+		}
+	}
+
+	c := NewClient(nil, ts.URL, WithConnectionRefresh(time.Minute))
+	if c.httpClient == http.DefaultClient || c.transport == nil || c.transport == http.DefaultTransport {
+		t.Errorf("WithConnectionRefresh with a nil http.Client uses the shared http.DefaultClient, want a clone")
+	}
+	c = NewClient(&http.Client{Transport: struct{ http.RoundTripper }{http.DefaultTransport}}, ts.URL, WithConnectionRefresh(time.Minute))
+	if _, err := c.Version(context.Background()); err == nil {
+		t.Errorf("WithConnectionRefresh with a custom RoundTripper got no error, want one")
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parser classes which add enrichment metadata when enabled in the server
+// configuration.
+const (
+	NamedEntityParserClass = "org.apache.tika.parser.ner.NamedEntityParser"
+	GeoParserClass         = "org.apache.tika.parser.geo.topic.GeoParser"
+)
+
+// ErrUnsupported is returned when the server does not have the parser a
+// method needs.
+var ErrUnsupported = errors.New("not supported by server")
+
+// Has reports whether p or any of its children is the parser class.
+func (p *Parser) Has(class string) bool {
+	if p.Name == class {
+		return true
+	}
+	for i := range p.Children {
+		if p.Children[i].Has(class) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasParser reports whether the server has the parser class.
+func (c *Client) HasParser(ctx context.Context, class string) (bool, error) {
+	p, err := c.Parsers(ctx)
+	if err != nil {
+		return false, err
+	}
+	return p.Has(class), nil
+}
+
+// An Entity is a named entity recognized in the text of a document.
+type Entity struct {
+	// Type is the kind of entity, such as PERSON, LOCATION, or
+	// ORGANIZATION.
+	Type string
+	// Text is the entity as it appears in the document.
+	Text string
+	// Offsets are the byte offsets of every occurrence of Text in the
+	// extracted text of the document. Tika does not report offsets, so they
+	// are found by searching the text.
+	Offsets []int
+}
+
+// nerPrefix is the prefix of the metadata keys added by the
+// NamedEntityParser.
+const nerPrefix = "NER_"
+
+// Entities returns the named entities recognized in input by the server's
+// NamedEntityParser, sorted by Type and Text. If the server finds no entities
+// and does not have the parser, the error wraps ErrUnsupported.
+func (c *Client) Entities(ctx context.Context, input io.Reader) ([]Entity, error) {
+	m, err := c.container(ctx, input, "/rmeta/text", nil)
+	if err != nil {
+		return nil, err
+	}
+	content := m.Get(XTIKAContent)
+	var es []Entity
+	for k, vs := range m {
+		if !strings.HasPrefix(k, nerPrefix) {
+			continue
+		}
+		for _, v := range vs {
+			es = append(es, Entity{Type: strings.TrimPrefix(k, nerPrefix), Text: v, Offsets: offsets(content, v)})
+		}
+	}
+	if len(es) == 0 {
+		if err := c.require(ctx, NamedEntityParserClass); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(es, func(i, j int) bool {
+		if es[i].Type != es[j].Type {
+			return es[i].Type < es[j].Type
+		}
+		return es[i].Text < es[j].Text
+	})
+	return es, nil
+}
+
+// A GeoLocation is a place mentioned in a document, resolved by the
+// GeoTopicParser.
+type GeoLocation struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+// GeoTopics returns the places mentioned in input, as resolved by the
+// server's GeoTopicParser. The most relevant place is first. The server must
+// be configured with a gazetteer; if it finds no places and does not have
+// the parser, the error wraps ErrUnsupported.
+func (c *Client) GeoTopics(ctx context.Context, input io.Reader) ([]GeoLocation, error) {
+	m, err := c.container(ctx, input, "/rmeta/text", http.Header{"Content-Type": {"application/geotopic"}})
+	if err != nil {
+		return nil, err
+	}
+	var locs []GeoLocation
+	add := func(prefix, suffix string) bool {
+		name := m.Get(prefix + "_NAME" + suffix)
+		if name == "" {
+			return false
+		}
+		l := GeoLocation{Name: name}
+		l.Latitude, _ = strconv.ParseFloat(m.Get(prefix+"_LATITUDE"+suffix), 64)
+		l.Longitude, _ = strconv.ParseFloat(m.Get(prefix+"_LONGITUDE"+suffix), 64)
+		locs = append(locs, l)
+		return true
+	}
+	add("Geographic", "")
+	for i := 1; add("Optional", strconv.Itoa(i)); i++ {
+	}
+	if len(locs) == 0 {
+		if err := c.require(ctx, GeoParserClass); err != nil {
+			return nil, err
+		}
+	}
+	return locs, nil
+}
+
+// container returns the metadata of the container document from a recursive
+// metadata request, redacted if c has a redactor.
+func (c *Client) container(ctx context.Context, input io.Reader, path string, header http.Header) (Metadata, error) {
+	body, err := c.call(ctx, input, "PUT", path, acceptJSON(header))
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var docs []Metadata
+	if err := json.NewDecoder(body).Decode(&docs); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 || docs[0] == nil {
+		return Metadata{}, nil
+	}
+	if c.redactor != nil {
+		return docs[0].Redact(c.redactor), nil
+	}
+	return docs[0], nil
+}
+
+// require returns an error wrapping ErrUnsupported if the server does not
+// have the parser class.
+func (c *Client) require(ctx context.Context, class string) error {
+	ok, err := c.HasParser(ctx, class)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s: %w", class, ErrUnsupported)
+	}
+	return nil
+}
+
+// offsets returns the byte offsets of every occurrence of sub in s.
+func offsets(s, sub string) []int {
+	var r []int
+	if sub == "" {
+		return r
+	}
+	for i := 0; ; {
+		j := strings.Index(s[i:], sub)
+		if j < 0 {
+			return r
+		}
+		r = append(r, i+j)
+		i += j + len(sub)
+	}
+}
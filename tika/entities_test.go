@@ -0,0 +1,85 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// enrichServer returns rmeta for path /rmeta/text and the parsers tree with
+// the given parser class.
+func enrichServer(rmeta, parser string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rmeta/text":
+			if r.Header.Get("Content-Type") == "application/geotopic" {
+				fmt.Fprint(w, `[{"Geographic_NAME":"Paris","Geographic_LATITUDE":"48.85","Geographic_LONGITUDE":"2.35",`+
+					`"Optional_NAME1":"Lyon","Optional_LATITUDE1":"45.75","Optional_LONGITUDE1":"4.85"}]`)
+				return
+			}
+			fmt.Fprint(w, rmeta)
+		case "/parsers/details":
+			fmt.Fprintf(w, `{"name":"org.apache.tika.parser.DefaultParser","children":[{"name":%q}]}`, parser)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestEntities(t *testing.T) {
+	ts := enrichServer(`[{"X-TIKA:content":"Ann met Bob. Ann left.","NER_PERSON":["Bob","Ann"],"NER_ORGANIZATION":"ACME"}]`, NamedEntityParserClass)
+	defer ts.Close()
+	got, err := NewClient(nil, ts.URL).Entities(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Entities returned an error: %v", err)
+	}
+	want := []Entity{
+		{Type: "ORGANIZATION", Text: "ACME"},
+		{Type: "PERSON", Text: "Ann", Offsets: []int{0, 13}},
+		{Type: "PERSON", Text: "Bob", Offsets: []int{8}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Entities got %+v, want %+v", got, want)
+	}
+}
+
+func TestEntitiesUnsupported(t *testing.T) {
+	ts := enrichServer(`[{"X-TIKA:content":"text"}]`, "org.apache.tika.parser.txt.TXTParser")
+	defer ts.Close()
+	if _, err := NewClient(nil, ts.URL).Entities(context.Background(), nil); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Entities got error %v, want ErrUnsupported", err)
+	}
+}
+
+func TestGeoTopics(t *testing.T) {
+	ts := enrichServer("", GeoParserClass)
+	defer ts.Close()
+	got, err := NewClient(nil, ts.URL).GeoTopics(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GeoTopics returned an error: %v", err)
+	}
+	want := []GeoLocation{{"Paris", 48.85, 2.35}, {"Lyon", 45.75, 4.85}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GeoTopics got %+v, want %+v", got, want)
+	}
+}
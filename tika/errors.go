@@ -0,0 +1,74 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by the errors returned from Client methods. Use
+// errors.Is to check for them:
+//
+//	if errors.Is(err, tika.ErrEncrypted) {
+//	    // Ask the user for a password.
+//	}
+var (
+	// ErrUnsupportedMediaType matches a 415 response: the server has no
+	// parser for the input.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrUnprocessable matches a 422 response: the parser failed, for
+	// example because the input is corrupt.
+	ErrUnprocessable = errors.New("unprocessable document")
+	// ErrServerUnavailable matches 502, 503, and 504 responses and
+	// failures to reach the server at all.
+	ErrServerUnavailable = errors.New("server unavailable")
+	// ErrEncrypted matches errors caused by an encrypted document that
+	// could not be decrypted.
+	ErrEncrypted = errors.New("encrypted document")
+)
+
+// maxErrorMessage is the maximum length of ClientError.Message.
+const maxErrorMessage = 4 << 10
+
+// Is reports whether e matches target, one of the sentinel errors.
+func (e ClientError) Is(target error) bool {
+	switch target {
+	case ErrUnsupportedMediaType:
+		return e.StatusCode == http.StatusUnsupportedMediaType
+	case ErrUnprocessable:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrServerUnavailable:
+		return e.StatusCode == http.StatusBadGateway || e.StatusCode == http.StatusServiceUnavailable || e.StatusCode == http.StatusGatewayTimeout
+	case ErrEncrypted:
+		return strings.Contains(e.Message, "EncryptedDocumentException")
+	}
+	return false
+}
+
+// unavailableError wraps an error reaching the server so that it matches
+// ErrServerUnavailable.
+type unavailableError struct {
+	err error
+}
+
+func (e unavailableError) Error() string { return e.err.Error() }
+
+func (e unavailableError) Unwrap() error { return e.err }
+
+func (e unavailableError) Is(target error) bool { return target == ErrServerUnavailable }
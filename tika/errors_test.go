@@ -0,0 +1,80 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   []error
+	}{
+		{http.StatusUnsupportedMediaType, "", []error{ErrUnsupportedMediaType}},
+		{http.StatusUnprocessableEntity, "java.io.IOException", []error{ErrUnprocessable}},
+		{http.StatusUnprocessableEntity, "org.apache.tika.exception.EncryptedDocumentException: Unable to process: document is encrypted", []error{ErrUnprocessable, ErrEncrypted}},
+		{http.StatusServiceUnavailable, "", []error{ErrServerUnavailable}},
+		{http.StatusInternalServerError, "", nil},
+	}
+	all := []error{ErrUnsupportedMediaType, ErrUnprocessable, ErrServerUnavailable, ErrEncrypted}
+	for _, test := range tests {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			fmt.Fprint(w, test.body)
+		}))
+		_, err := NewClient(nil, ts.URL).Parse(context.Background(), nil)
+		ts.Close()
+
+		var ce ClientError
+		if !errors.As(err, &ce) || ce.StatusCode != test.status || ce.Message != test.body {
+			t.Errorf("Parse(%d) got error %#v, want a ClientError with the status and body", test.status, err)
+		}
+		for _, target := range all {
+			want := false
+			for _, w := range test.want {
+				want = want || w == target
+			}
+			if got := errors.Is(err, target); got != want {
+				t.Errorf("errors.Is(Parse(%d), %v) = %v, want %v", test.status, target, got, want)
+			}
+		}
+	}
+}
+
+func TestServerUnavailable(t *testing.T) {
+	ts := httptest.NewServer(nil)
+	url := ts.URL
+	ts.Close()
+	_, err := NewClient(nil, url).Parse(context.Background(), nil)
+	if !errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("Parse of a closed server got %v, want ErrServerUnavailable", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewClient(nil, url).Parse(ctx, nil)
+	if errors.Is(err, ErrServerUnavailable) {
+		t.Errorf("Parse with a canceled context got %v, want not ErrServerUnavailable", err)
+	}
+}
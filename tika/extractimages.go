@@ -0,0 +1,201 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// PDFExtractInlineImagesHeader is the header enabling the extraction of the
+// images drawn on the pages of PDF documents, which Tika otherwise ignores.
+const PDFExtractInlineImagesHeader = "X-Tika-PDFextractInlineImages"
+
+// An ExtractedImage is an image embedded in a document, as returned by
+// ExtractImages.
+type ExtractedImage struct {
+	// Name is the name of the image within the document, such as
+	// "image0.png".
+	Name string
+	// ResourcePath is the X-TIKA:embedded_resource_path of the image, such
+	// as "/image0.png", or "" if Tika reported no metadata for it.
+	ResourcePath string
+	// MIMEType is the type of the image, without parameters.
+	MIMEType string
+	// Data is the content of the image, or nil if the server returned
+	// metadata but no content for it.
+	Data []byte
+	// Page is the number of the page the image is drawn on, starting at 1,
+	// or 0 if it is unknown, as for formats without pages.
+	Page int
+	// Position is the position of the image among the images of its page,
+	// or of the document if Page is 0, starting at 0, or -1 if the image is
+	// not referenced from the text, as for attachments.
+	Position int
+	// Width and Height are the dimensions of the image in pixels, or 0 if
+	// they are unknown.
+	Width  int
+	Height int
+	// Metadata is the metadata Tika extracted from the image.
+	Metadata Metadata
+}
+
+// ExtractImages returns the images embedded in input, with their content
+// and their page and position in the document, for layout analysis. Images
+// inline in PDF pages are extracted with PDFExtractInlineImagesHeader, in
+// addition to any header passed.
+//
+// input is sent twice, once to get the metadata of the images and their
+// location in the XHTML content of the document, and once to get their
+// content with Unpack. The images are returned in the order Tika reports
+// them, and are held in memory.
+func (c *Client) ExtractImages(ctx context.Context, input io.ReadSeeker, header http.Header) ([]ExtractedImage, error) {
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(PDFExtractInlineImagesHeader, "true")
+
+	var (
+		images    []ExtractedImage
+		locations map[string]imageLocation
+	)
+	byName := map[string]int{}
+	err := c.metaRecursiveStream(ctx, input, "html", header, func(m Metadata) error {
+		p := m.Get(XTIKAEmbeddedResourcePath)
+		if p == "" {
+			locations = imageLocations(strings.NewReader(m.Get(XTIKAContent)))
+			return nil
+		}
+		mimeType := baseMIMEType(m.Get("Content-Type"))
+		if !strings.HasPrefix(mimeType, "image/") {
+			return nil
+		}
+		name := m.Get("resourceName")
+		if name == "" {
+			name = path.Base(p)
+		}
+		info := m.Image()
+		delete(m, XTIKAContent)
+		byName[name] = len(images)
+		images = append(images, ExtractedImage{
+			Name:         name,
+			ResourcePath: p,
+			MIMEType:     mimeType,
+			Width:        info.Width,
+			Height:       info.Height,
+			Metadata:     m,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := input.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	err = c.unpack(ctx, input, header, func(f EmbeddedFile) error {
+		if !strings.HasPrefix(f.MIMEType, "image/") {
+			return nil
+		}
+		data, err := ioutil.ReadAll(f.Content)
+		if err != nil {
+			return err
+		}
+		name := path.Base(f.Name)
+		if i, ok := byName[name]; ok && images[i].Data == nil {
+			images[i].Data = data
+			return nil
+		}
+		byName[name] = len(images)
+		images = append(images, ExtractedImage{Name: name, MIMEType: f.MIMEType, Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range images {
+		images[i].Position = -1
+		if l, ok := locations[images[i].Name]; ok {
+			images[i].Page, images[i].Position = l.page, l.position
+		}
+	}
+	return images, nil
+}
+
+// imageLocation is where an image is referenced in the XHTML content of a
+// document.
+type imageLocation struct {
+	page, position int
+}
+
+// imageLocations returns the location of the first reference to each
+// embedded image in the XHTML read from r, by image name. Tika references
+// embedded images as <img src="embedded:name">, and wraps each page in a
+// <div class="page">. Errors in the XHTML end the scan.
+func imageLocations(r io.Reader) map[string]imageLocation {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	locations := map[string]imageLocation{}
+	page, position := 0, 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return locations
+		}
+		t, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(t.Name.Local) {
+		case "div":
+			if attr(t.Attr, "class") == "page" {
+				page++
+				position = 0
+			}
+		case "img":
+			src := attr(t.Attr, "src")
+			if !strings.HasPrefix(src, "embedded:") {
+				continue
+			}
+			name := path.Base(strings.TrimPrefix(src, "embedded:"))
+			if _, ok := locations[name]; !ok {
+				locations[name] = imageLocation{page, position}
+			}
+			position++
+		}
+	}
+}
+
+// attr returns the value of the attribute name in attrs, or "".
+func attr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
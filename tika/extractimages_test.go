@@ -0,0 +1,106 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractImages(t *testing.T) {
+	const xhtml = `<html><body>` +
+		`<div class="page"><p>One</p><img src="embedded:image0.png" alt="image0.png"/></div>` +
+		`<div class="page"><img src="embedded:image1.jpg"/><p>Two</p><img src="embedded:image0.png"/></div>` +
+		`</body></html>`
+	docs := []Metadata{
+		{"Content-Type": {"application/pdf"}, XTIKAContent: {xhtml}},
+		{"Content-Type": {"image/png"}, XTIKAEmbeddedResourcePath: {"/image0.png"}, "resourceName": {"image0.png"}, "tiff:ImageWidth": {"10"}, "tiff:ImageLength": {"20"}, XTIKAContent: {"<html/>"}},
+		{"Content-Type": {"image/jpeg"}, XTIKAEmbeddedResourcePath: {"/image1.jpg"}},
+		{"Content-Type": {"text/plain"}, XTIKAEmbeddedResourcePath: {"/notes.txt"}},
+	}
+	files := []struct{ name, content string }{
+		{"image0.png", "png data"},
+		{"notes.txt", "notes"},
+		{"image1.jpg", "jpeg data"},
+		{"attached.gif", "gif data"},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(PDFExtractInlineImagesHeader) != "true" || r.Header.Get("X-Test") != "1" {
+			t.Errorf("%s got headers %v, want %s and X-Test", r.URL.Path, r.Header, PDFExtractInlineImagesHeader)
+		}
+		switch r.URL.Path {
+		case "/rmeta/html":
+			json.NewEncoder(w).Encode(docs)
+		case "/unpack":
+			tw := tar.NewWriter(w)
+			for _, f := range files {
+				tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content)), Typeflag: tar.TypeReg})
+				tw.Write([]byte(f.content))
+			}
+			tw.Close()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, ts.URL)
+	got, err := c.ExtractImages(context.Background(), strings.NewReader("pdf"), http.Header{"X-Test": {"1"}})
+	if err != nil {
+		t.Fatalf("ExtractImages got error: %v", err)
+	}
+	want := []ExtractedImage{
+		{
+			Name:         "image0.png",
+			ResourcePath: "/image0.png",
+			MIMEType:     "image/png",
+			Data:         []byte("png data"),
+			Page:         1,
+			Position:     0,
+			Width:        10,
+			Height:       20,
+			Metadata:     Metadata{"Content-Type": {"image/png"}, XTIKAEmbeddedResourcePath: {"/image0.png"}, "resourceName": {"image0.png"}, "tiff:ImageWidth": {"10"}, "tiff:ImageLength": {"20"}},
+		},
+		{
+			Name:         "image1.jpg",
+			ResourcePath: "/image1.jpg",
+			MIMEType:     "image/jpeg",
+			Data:         []byte("jpeg data"),
+			Page:         2,
+			Position:     0,
+			Metadata:     Metadata{"Content-Type": {"image/jpeg"}, XTIKAEmbeddedResourcePath: {"/image1.jpg"}},
+		},
+		{Name: "attached.gif", MIMEType: "image/gif", Data: []byte("gif data"), Position: -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractImages got\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestImageLocations(t *testing.T) {
+	got := imageLocations(strings.NewReader(`<div><img src="embedded:a.png"><img src="http://example.com/b.png"><IMG SRC="embedded:dir/c.png"></div>`))
+	want := map[string]imageLocation{"a.png": {0, 0}, "c.png": {0, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("imageLocations got %v, want %v", got, want)
+	}
+}
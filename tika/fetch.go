@@ -0,0 +1,177 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"syscall"
+	"time"
+)
+
+// DefaultMaxURLBytes is the size limit of FetchURL when maxBytes is not
+// positive.
+const DefaultMaxURLBytes = 100 << 20
+
+// ErrURLTooLarge is returned, wrapped, when a document fetched by FetchURL is
+// larger than its limit.
+var ErrURLTooLarge = errors.New("URL document too large")
+
+// A URLDocument is a document fetched by FetchURL. Reading it streams the
+// response body, so the document is never held in memory or on disk in full.
+// It must be closed.
+type URLDocument struct {
+	io.ReadCloser
+	// URL is the URL the document was fetched from, after redirects.
+	URL string
+	// ContentType is the Content-Type of the response, or "" if it had none.
+	ContentType string
+	// Name is the last element of the path of URL, or the filename of the
+	// Content-Disposition of the response if it had one.
+	Name string
+	// Length is the Content-Length of the response, or -1 if unknown.
+	Length int64
+}
+
+// Header returns a header hinting the server with the Content-Type and Name
+// of d, for methods taking a header such as ParseWithHeader.
+func (d *URLDocument) Header() http.Header {
+	h := http.Header{}
+	if d.ContentType != "" {
+		h.Set("Content-Type", d.ContentType)
+	}
+	if d.Name != "" {
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": d.Name}))
+	}
+	return h
+}
+
+// FetchURL fetches the document at rawURL in the client, to stream it to the
+// server with any method, returning an error wrapping ErrURLTooLarge from
+// Read after maxBytes, or DefaultMaxURLBytes if maxBytes <= 0, have been
+// read. Unlike ParseURL, the server needs no network access and no fileUrl
+// feature. rawURL and every redirect are checked against the URLPolicy of c,
+// set with WithURLPolicy, and unless it allows private addresses,
+// connections to them are refused, so DNS rebinding cannot bypass the check.
+func (c *Client) FetchURL(ctx context.Context, rawURL string, maxBytes int64) (*URLDocument, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxURLBytes
+	}
+	p := c.urlPolicy
+	if p == nil {
+		p = &URLPolicy{}
+	}
+	hc := c.fetcher(p)
+	if err := p.Check(ctx, rawURL); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: %s", rawURL, resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s has %d bytes, limit %d", ErrURLTooLarge, rawURL, resp.ContentLength, maxBytes)
+	}
+	d := &URLDocument{
+		ReadCloser:  &limitedBody{resp.Body, maxBytes, fmt.Errorf("%w: %s has more than %d bytes", ErrURLTooLarge, rawURL, maxBytes)},
+		URL:         resp.Request.URL.String(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Name:        path.Base(resp.Request.URL.Path),
+		Length:      resp.ContentLength,
+	}
+	if d.Name == "/" || d.Name == "." {
+		d.Name = ""
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		d.Name = path.Base(params["filename"])
+	}
+	return d, nil
+}
+
+// DetectURL fetches the document at rawURL like FetchURL and detects its MIME
+// type like Detect, returning the detected type and the Content-Type the
+// document was served with, which may differ. Only the name of the document
+// is sent as a hint, so the server cannot be misled by a wrong Content-Type.
+func (c *Client) DetectURL(ctx context.Context, rawURL string, maxBytes int64) (mimeType, contentType string, err error) {
+	d, err := c.FetchURL(ctx, rawURL, maxBytes)
+	if err != nil {
+		return "", "", err
+	}
+	defer d.Close()
+	h := d.Header()
+	h.Del("Content-Type")
+	mimeType, err = c.callString(ctx, d, "PUT", "/detect/stream", h)
+	if err != nil {
+		return "", "", err
+	}
+	return mimeType, d.ContentType, nil
+}
+
+// fetcher returns the client of FetchURL, creating it from p on first use.
+func (c *Client) fetcher(p *URLPolicy) *http.Client {
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+	if c.fetchClient == nil {
+		c.fetchClient = p.httpClient()
+	}
+	return c.fetchClient
+}
+
+// httpClient returns a client checking redirects against p and, unless p
+// allows private addresses, refusing to connect to them.
+func (p *URLPolicy) httpClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !p.AllowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || privateIP(ip) {
+				return fmt.Errorf("%w: connection to private address %s", ErrURLDenied, host)
+			}
+			return nil
+		}
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: t,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return p.Check(req.Context(), req.URL.String())
+		},
+	}
+}
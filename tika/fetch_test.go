@@ -0,0 +1,191 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFetchURL(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/docs/report.pdf", http.StatusFound)
+		case "/docs/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4 report"))
+		case "/attachment":
+			w.Header().Set("Content-Disposition", `attachment; filename="notes.txt"`)
+			w.Write([]byte("notes"))
+		case "/stream":
+			w.(http.Flusher).Flush() // No Content-Length.
+			w.Write([]byte(strings.Repeat("x", 100)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer origin.Close()
+	c := NewClient(nil, "", WithURLPolicy(URLPolicy{AllowPrivate: true}))
+
+	tests := []struct {
+		path        string
+		want        string
+		wantName    string
+		wantType    string
+		wantURLPath string
+	}{
+		{"/docs/report.pdf", "%PDF-1.4 report", "report.pdf", "application/pdf", "/docs/report.pdf"},
+		{"/redirect", "%PDF-1.4 report", "report.pdf", "application/pdf", "/docs/report.pdf"},
+		{"/attachment", "notes", "notes.txt", "text/plain; charset=utf-8", "/attachment"},
+	}
+	for _, test := range tests {
+		d, err := c.FetchURL(context.Background(), origin.URL+test.path, 0)
+		if err != nil {
+			t.Errorf("FetchURL(%s) got error: %v", test.path, err)
+			continue
+		}
+		b, err := ioutil.ReadAll(d)
+		d.Close()
+		if err != nil {
+			t.Errorf("FetchURL(%s) read got error: %v", test.path, err)
+		}
+		if string(b) != test.want {
+			t.Errorf("FetchURL(%s) got %q, want %q", test.path, b, test.want)
+		}
+		if d.Name != test.wantName {
+			t.Errorf("FetchURL(%s) Name got %q, want %q", test.path, d.Name, test.wantName)
+		}
+		if d.ContentType != test.wantType {
+			t.Errorf("FetchURL(%s) ContentType got %q, want %q", test.path, d.ContentType, test.wantType)
+		}
+		if want := origin.URL + test.wantURLPath; d.URL != want {
+			t.Errorf("FetchURL(%s) URL got %q, want %q", test.path, d.URL, want)
+		}
+	}
+
+	if _, err := c.FetchURL(context.Background(), origin.URL+"/missing", 0); err == nil {
+		t.Errorf("FetchURL(/missing) got no error, want one")
+	}
+	if _, err := c.FetchURL(context.Background(), origin.URL+"/docs/report.pdf", 4); !errors.Is(err, ErrURLTooLarge) {
+		t.Errorf("FetchURL with Content-Length over limit got error %v, want %v", err, ErrURLTooLarge)
+	}
+	d, err := c.FetchURL(context.Background(), origin.URL+"/stream", 10)
+	if err != nil {
+		t.Fatalf("FetchURL(/stream) got error: %v", err)
+	}
+	defer d.Close()
+	b, err := ioutil.ReadAll(d)
+	if !errors.Is(err, ErrURLTooLarge) {
+		t.Errorf("FetchURL(/stream) read got error %v, want %v", err, ErrURLTooLarge)
+	}
+	if len(b) != 10 {
+		t.Errorf("FetchURL(/stream) read %d bytes, want 10", len(b))
+	}
+}
+
+func TestFetchURLReusesConnections(t *testing.T) {
+	var conns int32
+	origin := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("doc"))
+	}))
+	origin.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	origin.Start()
+	defer origin.Close()
+
+	c := NewClient(nil, "", WithURLPolicy(URLPolicy{AllowPrivate: true}))
+	for i := 0; i < 3; i++ {
+		d, err := c.FetchURL(context.Background(), origin.URL, 0)
+		if err != nil {
+			t.Fatalf("FetchURL got error: %v", err)
+		}
+		ioutil.ReadAll(d)
+		d.Close()
+	}
+	if got := atomic.LoadInt32(&conns); got != 1 {
+		t.Errorf("3 FetchURL calls opened %d connections, want 1", got)
+	}
+}
+
+func TestFetchURLPolicy(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://127.0.0.1:1/", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	c := NewClient(nil, "")
+	if _, err := c.FetchURL(context.Background(), origin.URL, 0); !errors.Is(err, ErrURLDenied) {
+		t.Errorf("FetchURL(%s) got error %v, want %v", origin.URL, err, ErrURLDenied)
+	}
+	// Connections to private addresses are refused even if the URL was
+	// allowed, as after DNS rebinding.
+	p := &URLPolicy{}
+	if _, err := p.httpClient().Get(origin.URL); !errors.Is(err, ErrURLDenied) {
+		t.Errorf("Get(%s) got error %v, want %v", origin.URL, err, ErrURLDenied)
+	}
+	// Redirects are checked too.
+	c = NewClient(nil, "", WithURLPolicy(URLPolicy{AllowPrivate: true, Hosts: []string{origin.Listener.Addr().String()}}))
+	if _, err := c.FetchURL(context.Background(), origin.URL, 0); !errors.Is(err, ErrURLDenied) {
+		t.Errorf("FetchURL(%s) redirecting to another host got error %v, want %v", origin.URL, err, ErrURLDenied)
+	}
+}
+
+func TestDetectURL(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	defer origin.Close()
+	var gotBody, gotType, gotDisposition string
+	tika := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+		gotType = r.Header.Get("Content-Type")
+		gotDisposition = r.Header.Get("Content-Disposition")
+		w.Write([]byte("application/pdf"))
+	}))
+	defer tika.Close()
+
+	c := NewClient(nil, tika.URL, WithURLPolicy(URLPolicy{AllowPrivate: true}))
+	mimeType, contentType, err := c.DetectURL(context.Background(), origin.URL+"/file.pdf", 0)
+	if err != nil {
+		t.Fatalf("DetectURL got error: %v", err)
+	}
+	if mimeType != "application/pdf" || contentType != "text/plain" {
+		t.Errorf("DetectURL got %q, %q, want %q, %q", mimeType, contentType, "application/pdf", "text/plain")
+	}
+	if gotBody != "%PDF-1.4" {
+		t.Errorf("DetectURL sent %q, want %q", gotBody, "%PDF-1.4")
+	}
+	if gotType != "" {
+		t.Errorf("DetectURL sent Content-Type %q, want none", gotType)
+	}
+	if want := `attachment; filename=file.pdf`; gotDisposition != want {
+		t.Errorf("DetectURL sent Content-Disposition %q, want %q", gotDisposition, want)
+	}
+}
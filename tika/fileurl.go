@@ -0,0 +1,187 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FileURLHeader is the header asking Tika Server to fetch the document to
+// parse from a URL instead of the request body. The server must be started
+// with the fileUrl feature enabled. See ParseURL.
+const FileURLHeader = "fileUrl"
+
+// ErrURLDenied is returned, wrapped, by ParseURL and URLPolicy.Check for URLs
+// the policy does not allow.
+var ErrURLDenied = errors.New("URL denied by policy")
+
+// A URLPolicy restricts the URLs a Client asks the server to fetch with
+// ParseURL. Fetching URLs chosen by users is a server-side request forgery
+// (SSRF) vector: the server could be made to read internal services, cloud
+// metadata endpoints, or local files. The zero URLPolicy allows http and
+// https URLs to public addresses only.
+//
+// Tika Server resolves the host again when fetching, so a DNS record changed
+// between the check and the fetch (DNS rebinding) defeats the check. For
+// untrusted URLs, fetching the content in the client and sending it, with
+// FetchURL, is safer.
+type URLPolicy struct {
+	// Schemes are the allowed URL schemes. If empty, http and https are
+	// allowed.
+	Schemes []string
+	// Hosts, if not empty, are the only allowed hosts, in the format of the
+	// NO_PROXY variable: host names, domain suffixes starting with a dot,
+	// IP addresses, or CIDR ranges, optionally followed by a port.
+	Hosts []string
+	// AllowPrivate allows hosts resolving to loopback, private, link-local,
+	// shared, multicast, or unspecified addresses.
+	AllowPrivate bool
+	// LookupIP resolves host names. If nil, net.DefaultResolver is used.
+	LookupIP func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// WithURLPolicy sets the URLPolicy checked by ParseURL and FetchURL,
+// instead of the zero URLPolicy.
+func WithURLPolicy(p URLPolicy) Option {
+	return func(c *Client) {
+		c.urlPolicy = &p
+	}
+}
+
+// Check returns an error wrapping ErrURLDenied if p does not allow rawURL,
+// resolving its host to check its addresses.
+func (p *URLPolicy) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLDenied, err)
+	}
+	schemes := p.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	if !containsFold(schemes, u.Scheme) {
+		return fmt.Errorf("%w: scheme %q not allowed", ErrURLDenied, u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: %q has no host", ErrURLDenied, rawURL)
+	}
+	if len(p.Hosts) > 0 && !parseNoProxy(strings.Join(p.Hosts, ",")).match(u) {
+		return fmt.Errorf("%w: host %s not allowed", ErrURLDenied, host)
+	}
+	if p.AllowPrivate {
+		return nil
+	}
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		lookup := p.LookupIP
+		if lookup == nil {
+			lookup = func(ctx context.Context, host string) ([]net.IP, error) {
+				addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+				ips := make([]net.IP, len(addrs))
+				for i, a := range addrs {
+					ips[i] = a.IP
+				}
+				return ips, err
+			}
+		}
+		if ips, err = lookup(ctx, host); err != nil {
+			return fmt.Errorf("%w: error resolving %s: %v", ErrURLDenied, host, err)
+		}
+	}
+	for _, ip := range ips {
+		if privateIP(ip) {
+			return fmt.Errorf("%w: %s resolves to private address %s", ErrURLDenied, host, ip)
+		}
+	}
+	return nil
+}
+
+// blockedNets are the special-purpose ranges privateIP rejects besides those
+// tested by the methods of net.IP.
+var blockedNets = []*net.IPNet{
+	// "This network", which many systems route to the local host.
+	{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+	// Shared address space for carrier-grade NAT, RFC 6598.
+	{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)},
+	// NAT64 well-known and local-use prefixes, RFC 6052 and RFC 8215,
+	// embedding IPv4 addresses that may be private.
+	{IP: net.ParseIP("64:ff9b::"), Mask: net.CIDRMask(96, 128)},
+	{IP: net.ParseIP("64:ff9b:1::"), Mask: net.CIDRMask(48, 128)},
+}
+
+// privateIP reports whether ip is not a public unicast address.
+func privateIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether ss contains s, ignoring case.
+func containsFold(ss []string, s string) bool {
+	for _, v := range ss {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseURL asks the server to fetch the document at fileURL and parse it,
+// returning its body like Parse. fileURL is first checked against the
+// URLPolicy of c, set with WithURLPolicy, which by default allows only http
+// and https URLs to public addresses; a denied URL is never sent to the
+// server. The same check applies to any request of c, except those made with
+// Do, setting the FileURLHeader.
+func (c *Client) ParseURL(ctx context.Context, fileURL string) (string, error) {
+	return c.ParseWithHeader(ctx, nil, http.Header{FileURLHeader: {fileURL}})
+}
+
+// checkFileURL checks the FileURLHeader of header, if any, against the
+// URLPolicy of c.
+func (c *Client) checkFileURL(ctx context.Context, header http.Header) error {
+	vs, ok := header[http.CanonicalHeaderKey(FileURLHeader)]
+	if !ok {
+		vs, ok = header[FileURLHeader]
+	}
+	if !ok {
+		return nil
+	}
+	p := c.urlPolicy
+	if p == nil {
+		p = &URLPolicy{}
+	}
+	for _, v := range vs {
+		if err := p.Check(ctx, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
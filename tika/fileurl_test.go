@@ -0,0 +1,114 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestURLPolicyCheck(t *testing.T) {
+	hosts := map[string][]net.IP{
+		"public.example.com":   {net.ParseIP("93.184.216.34")},
+		"internal.example.com": {net.ParseIP("10.0.0.5")},
+		"mixed.example.com":    {net.ParseIP("93.184.216.34"), net.ParseIP("127.0.0.1")},
+	}
+	lookup := func(_ context.Context, host string) ([]net.IP, error) {
+		if ips, ok := hosts[host]; ok {
+			return ips, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	tests := []struct {
+		policy  URLPolicy
+		url     string
+		allowed bool
+	}{
+		{URLPolicy{}, "https://public.example.com/doc.pdf", true},
+		{URLPolicy{}, "http://93.184.216.34:8080/doc.pdf", true},
+		{URLPolicy{}, "file:///etc/passwd", false},
+		{URLPolicy{}, "ftp://public.example.com/doc.pdf", false},
+		{URLPolicy{}, "https://internal.example.com/doc.pdf", false},
+		{URLPolicy{}, "https://mixed.example.com/doc.pdf", false},
+		{URLPolicy{}, "https://unknown.example.com/doc.pdf", false},
+		{URLPolicy{}, "http://127.0.0.1:9998/", false},
+		{URLPolicy{}, "http://[::1]/", false},
+		{URLPolicy{}, "http://169.254.169.254/latest/meta-data/", false},
+		{URLPolicy{}, "http://100.64.1.1/", false},
+		{URLPolicy{}, "http://0.0.0.0/", false},
+		{URLPolicy{}, "http://0.1.2.3/", false},
+		{URLPolicy{}, "http://[64:ff9b::7f00:1]/", false},
+		{URLPolicy{}, "http://[64:ff9b:1::a00:5]/", false},
+		{URLPolicy{}, "http://[2606:4700::1111]/", true},
+		{URLPolicy{}, "http://[::ffff:192.168.1.1]/", false},
+		{URLPolicy{}, "/relative/path", false},
+		{URLPolicy{}, "http://%zz", false},
+		{URLPolicy{AllowPrivate: true}, "https://internal.example.com/doc.pdf", true},
+		{URLPolicy{Schemes: []string{"s3"}, AllowPrivate: true}, "s3://bucket/doc.pdf", true},
+		{URLPolicy{Hosts: []string{".example.com"}}, "https://public.example.com/doc.pdf", true},
+		{URLPolicy{Hosts: []string{".example.com"}}, "https://93.184.216.34/doc.pdf", false},
+		{URLPolicy{Hosts: []string{"93.184.216.0/24"}}, "https://93.184.216.34/doc.pdf", true},
+		{URLPolicy{Hosts: []string{"public.example.com:443"}}, "http://public.example.com/doc.pdf", false},
+	}
+	for _, test := range tests {
+		p := test.policy
+		p.LookupIP = lookup
+		err := p.Check(context.Background(), test.url)
+		if test.allowed && err != nil {
+			t.Errorf("Check(%q) with %+v got error: %v", test.url, test.policy, err)
+		}
+		if !test.allowed && !errors.Is(err, ErrURLDenied) {
+			t.Errorf("Check(%q) with %+v got error %v, want %v", test.url, test.policy, err, ErrURLDenied)
+		}
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	var fetched []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := r.Header.Get(FileURLHeader)
+		fetched = append(fetched, u)
+		w.Write([]byte("content of " + u))
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, ts.URL)
+	if _, err := c.ParseURL(context.Background(), "http://127.0.0.1/admin"); !errors.Is(err, ErrURLDenied) {
+		t.Errorf("ParseURL of a loopback URL got error %v, want %v", err, ErrURLDenied)
+	}
+	h := http.Header{}
+	h.Set(FileURLHeader, "file:///etc/passwd")
+	if _, err := c.ParseWithHeader(context.Background(), nil, h); !errors.Is(err, ErrURLDenied) {
+		t.Errorf("ParseWithHeader with a file URL got error %v, want %v", err, ErrURLDenied)
+	}
+	if len(fetched) != 0 {
+		t.Errorf("denied URLs were sent to the server: %q", fetched)
+	}
+
+	c = NewClient(nil, ts.URL, WithURLPolicy(URLPolicy{Hosts: []string{"docs.internal"}, AllowPrivate: true}))
+	got, err := c.ParseURL(context.Background(), "http://docs.internal/a.pdf")
+	if want := "content of http://docs.internal/a.pdf"; err != nil || got != want {
+		t.Errorf("ParseURL of an allowed URL = %q, %v, want %q", got, err, want)
+	}
+	if _, err := c.ParseURL(context.Background(), "http://other.internal/a.pdf"); !errors.Is(err, ErrURLDenied) {
+		t.Errorf("ParseURL of a host not allowed got error %v, want %v", err, ErrURLDenied)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ServerJAREnv is the environment variable FindServerJAR checks first.
+const ServerJAREnv = "TIKA_SERVER_JAR"
+
+// ErrServerJARNotFound is returned by FindServerJAR when no Tika Server JAR is
+// installed.
+var ErrServerJARNotFound = errors.New("no Tika Server JAR found")
+
+// jarSearchDirs are the system directories searched by FindServerJAR, after
+// CacheDir. It is a variable so tests can replace it.
+var jarSearchDirs = []string{"/usr/share/java", "/usr/share/tika", "/usr/local/share/tika", "/opt/tika"}
+
+// FindServerJAR returns the path of an installed Tika Server JAR, so programs
+// can run without downloading one. It returns the file named by the
+// TIKA_SERVER_JAR environment variable if set, else the newest
+// tika-server-*.jar in CacheDir, else the newest in common system
+// directories such as /usr/share/java. Every candidate is checked to be a
+// Tika Server JAR. If none is found, FindServerJAR returns
+// ErrServerJARNotFound.
+func FindServerJAR() (string, error) {
+	if path := os.Getenv(ServerJAREnv); path != "" {
+		if err := validateServerJAR(path); err != nil {
+			return "", fmt.Errorf("%s: %v", ServerJAREnv, err)
+		}
+		return path, nil
+	}
+	var dirs []string
+	if dir, err := CacheDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, jarSearchDirs...)
+	for _, dir := range dirs {
+		if path := newestServerJAR(dir); path != "" {
+			return path, nil
+		}
+	}
+	return "", ErrServerJARNotFound
+}
+
+// newestServerJAR returns the valid tika-server-*.jar in dir with the highest
+// version, or "" if there is none.
+func newestServerJAR(dir string) string {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var best, bestVersion string
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasPrefix(name, "tika-server") || !strings.HasSuffix(name, cacheSuffix) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if validateServerJAR(path) != nil {
+			continue
+		}
+		v := versionRE.FindString(name)
+		if best == "" || compareVersions(v, bestVersion) > 0 {
+			best, bestVersion = path, v
+		}
+	}
+	return best
+}
+
+// validateServerJAR checks that path is a JAR whose main class is a Tika
+// Server.
+func validateServerJAR(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("not a JAR: %v", err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != "META-INF/MANIFEST.MF" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if strings.HasPrefix(line, "Main-Class:") && strings.Contains(line, "org.apache.tika.server") {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s is not a Tika Server JAR", path)
+	}
+	return fmt.Errorf("%s has no manifest", path)
+}
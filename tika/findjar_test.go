@@ -0,0 +1,88 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeJAR writes a JAR at path with the given Main-Class.
+func writeJAR(t *testing.T, path, mainClass string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("META-INF/MANIFEST.MF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("Manifest-Version: 1.0\r\nMain-Class: " + mainClass + "\r\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const serverMainClass = "org.apache.tika.server.TikaServerCli"
+
+func TestFindServerJAR(t *testing.T) {
+	cache, cleanup := withCacheDir(t)
+	defer cleanup()
+	system := t.TempDir()
+	old := jarSearchDirs
+	defer func() { jarSearchDirs = old }()
+	jarSearchDirs = []string{filepath.Join(system, "missing"), system}
+	t.Setenv(ServerJAREnv, "")
+
+	if _, err := FindServerJAR(); !errors.Is(err, ErrServerJARNotFound) {
+		t.Errorf("FindServerJAR with no JARs got error %v, want %v", err, ErrServerJARNotFound)
+	}
+
+	writeJAR(t, filepath.Join(system, "tika-server-1.9.jar"), serverMainClass)
+	writeJAR(t, filepath.Join(system, "tika-server-1.21.jar"), serverMainClass)
+	writeJAR(t, filepath.Join(system, "tika-server-1.30.jar"), "org.example.Main")
+	if got, err := FindServerJAR(); err != nil || got != filepath.Join(system, "tika-server-1.21.jar") {
+		t.Errorf("FindServerJAR = %q, %v, want the newest valid system JAR", got, err)
+	}
+
+	cached := filepath.Join(cache, "go-tika", "tika-server-1.19.jar")
+	writeJAR(t, cached, serverMainClass)
+	if got, err := FindServerJAR(); err != nil || got != cached {
+		t.Errorf("FindServerJAR = %q, %v, want the cached JAR %q", got, err, cached)
+	}
+
+	env := filepath.Join(t.TempDir(), "custom.jar")
+	writeJAR(t, env, serverMainClass)
+	t.Setenv(ServerJAREnv, env)
+	if got, err := FindServerJAR(); err != nil || got != env {
+		t.Errorf("FindServerJAR = %q, %v, want %s %q", got, err, ServerJAREnv, env)
+	}
+
+	writeJAR(t, env, "org.example.Main")
+	if _, err := FindServerJAR(); err == nil {
+		t.Errorf("FindServerJAR with an invalid %s got no error", ServerJAREnv)
+	}
+}
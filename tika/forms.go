@@ -0,0 +1,168 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Headers enabling the extraction of PDF form fields and annotations. See
+// FormFields.
+const (
+	PDFExtractAcroFormHeader   = "X-Tika-PDFextractAcroFormContent"
+	PDFExtractAnnotationHeader = "X-Tika-PDFextractAnnotationText"
+)
+
+// AnnotationKeyPrefix starts the keys of annotations in the result of
+// FormFields, followed by their number in the document, starting at 1, as
+// in "annotation:1".
+const AnnotationKeyPrefix = "annotation:"
+
+// FormOptions configures FormFields.
+type FormOptions struct {
+	// Annotations, if true, adds the text of the annotations of the
+	// document, such as comments, under keys starting with
+	// AnnotationKeyPrefix.
+	Annotations bool
+}
+
+// FormFields returns the fields of the AcroForm of the PDF document input,
+// mapping their names to their values, for harvesting filled forms. Fields
+// without a value map to "". The result is empty if the document has no
+// form.
+func (c *Client) FormFields(ctx context.Context, input io.Reader, opts FormOptions) (map[string]string, error) {
+	header := http.Header{
+		"Accept":                 {"text/html"},
+		PDFExtractAcroFormHeader: {"true"},
+	}
+	if opts.Annotations {
+		header.Set(PDFExtractAnnotationHeader, "true")
+	}
+	body, err := c.call(ctx, input, "PUT", "/tika", header)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	fields, err := ParseFormFields(body, opts)
+	if err != nil || c.redactor == nil {
+		return fields, err
+	}
+	for k, v := range fields {
+		fields[k] = c.redactor.Redact(v)
+	}
+	return fields, nil
+}
+
+// ParseFormFields returns the form fields in r, the XHTML content Tika
+// extracts from a PDF document, as described in FormFields. Tika writes the
+// fields as list items in a <div class="acroform">, each with a fieldName
+// attribute and the text "name: value", and annotations as
+// <div class="annotation">.
+func ParseFormFields(r io.Reader, opts FormOptions) (map[string]string, error) {
+	d := ocrDecoder(r)
+	fields := map[string]string{}
+	const (
+		none = iota
+		field
+		annotation
+	)
+	var (
+		in          int // in is what the current element is part of.
+		depth       int // depth is the depth of the element started in.
+		name        string
+		text        strings.Builder
+		annotations int
+		form        int // form is the depth of the acroform div, or 0.
+		level       int
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return fields, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			level++
+			if in != none {
+				continue
+			}
+			class := " " + attr(t.Attr, "class") + " "
+			switch el := strings.ToLower(t.Name.Local); {
+			case el == "div" && strings.Contains(class, " acroform "):
+				if form == 0 {
+					form = level
+				}
+			case el == "li" && form > 0:
+				in, depth = field, level
+				name = attr(t.Attr, "fieldName")
+				text.Reset()
+			case el == "div" && strings.Contains(class, " annotation ") && opts.Annotations:
+				in, depth = annotation, level
+				text.Reset()
+			}
+		case xml.CharData:
+			if in != none {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if level == form {
+				form = 0
+			}
+			if in != none && level == depth {
+				s := strings.TrimSpace(text.String())
+				switch in {
+				case field:
+					if n, v := fieldText(name, s); n != "" {
+						fields[n] = v
+					}
+				case annotation:
+					if s != "" {
+						annotations++
+						fields[AnnotationKeyPrefix+strconv.Itoa(annotations)] = s
+					}
+				}
+				in = none
+			}
+			level--
+		}
+	}
+}
+
+// fieldText returns the name and value of a form field item with the
+// fieldName attribute name, which may be empty, and the text s, usually
+// "name: value".
+func fieldText(name, s string) (string, string) {
+	if name == "" {
+		i := strings.Index(s, ":")
+		if i < 0 {
+			return s, ""
+		}
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	}
+	if strings.HasPrefix(s, name) {
+		s = strings.TrimPrefix(strings.TrimSpace(s[len(name):]), ":")
+	}
+	return name, strings.TrimSpace(s)
+}
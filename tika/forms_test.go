@@ -0,0 +1,97 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testFormXHTML = `<html xmlns="http://www.w3.org/1999/xhtml"><body>
+<div class="page"><p>Application form</p>
+<div class="annotation"><p>Please sign here</p></div>
+<div class="annotation"></div>
+</div>
+<div class="acroform"><ol>
+<li fieldName="Name"><p>Name: Jane Doe</p></li>
+<li fieldName="Email">Email: jane@example.com</li>
+<li fieldName="Comments">Comments: a: b</li>
+<li fieldName="Empty">Empty</li>
+<li>Subscribed: Yes</li>
+<li>Unnamed</li>
+</ol></div>
+<ol><li>Not: a field</li></ol>
+</body></html>`
+
+func TestParseFormFields(t *testing.T) {
+	fields := map[string]string{
+		"Name":       "Jane Doe",
+		"Email":      "jane@example.com",
+		"Comments":   "a: b",
+		"Empty":      "",
+		"Subscribed": "Yes",
+		"Unnamed":    "",
+	}
+	got, err := ParseFormFields(strings.NewReader(testFormXHTML), FormOptions{})
+	if err != nil {
+		t.Fatalf("ParseFormFields got error: %v", err)
+	}
+	if !reflect.DeepEqual(got, fields) {
+		t.Errorf("ParseFormFields got %v, want %v", got, fields)
+	}
+
+	got, err = ParseFormFields(strings.NewReader(testFormXHTML), FormOptions{Annotations: true})
+	if err != nil {
+		t.Fatalf("ParseFormFields with annotations got error: %v", err)
+	}
+	fields[AnnotationKeyPrefix+"1"] = "Please sign here"
+	if !reflect.DeepEqual(got, fields) {
+		t.Errorf("ParseFormFields with annotations got %v, want %v", got, fields)
+	}
+}
+
+func TestFormFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tika" || r.Header.Get("Accept") != "text/html" || r.Header.Get(PDFExtractAcroFormHeader) != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get(PDFExtractAnnotationHeader) == "true" {
+			w.Write([]byte(`<div class="annotation">Note</div>`))
+		}
+		w.Write([]byte(`<div class="acroform"><ol><li fieldName="Name">Name: Jane</li></ol></div>`))
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	tests := []struct {
+		opts FormOptions
+		want map[string]string
+	}{
+		{FormOptions{}, map[string]string{"Name": "Jane"}},
+		{FormOptions{Annotations: true}, map[string]string{"Name": "Jane", "annotation:1": "Note"}},
+	}
+	for _, test := range tests {
+		got, err := c.FormFields(context.Background(), strings.NewReader("pdf"), test.opts)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("FormFields(%+v) = %v, %v, want %v", test.opts, got, err, test.want)
+		}
+	}
+}
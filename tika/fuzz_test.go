@@ -0,0 +1,91 @@
+//go:build go1.18
+
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func FuzzMetadataUnmarshalJSON(f *testing.F) {
+	for _, s := range []string{
+		`{"a":"b"}`,
+		`{"a":["b","c"]}`,
+		`{"pages":3,"encrypted":false,"x":null}`,
+		`{"a":{"b":"c"}}`,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var m Metadata
+		if err := json.Unmarshal(b, &m); err != nil || m == nil {
+			return
+		}
+		// Decoded Metadata only holds strings, so it round trips.
+		out, err := json.Marshal(m)
+		if err != nil {
+			t.Fatalf("Marshal(%v) got error: %v", m, err)
+		}
+		var again Metadata
+		if err := json.Unmarshal(out, &again); err != nil {
+			t.Fatalf("Unmarshal(%s) got error: %v", out, err)
+		}
+		if !reflect.DeepEqual(m, again) {
+			t.Fatalf("round trip of %v got %v", m, again)
+		}
+	})
+}
+
+func FuzzDecodeRecursive(f *testing.F) {
+	for _, s := range []string{
+		`[]`,
+		`[{"X-TIKA:content":"a"},{"X-TIKA:content":["b"],"n":1}]`,
+		`[null]`,
+		`[{"a":1}`,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		decodeRecursive(bytes.NewReader(b), func(m Metadata) error {
+			if m == nil {
+				t.Fatal("decodeRecursive passed nil Metadata")
+			}
+			return nil
+		})
+	})
+}
+
+func FuzzServerInfo(f *testing.F) {
+	for _, s := range []string{
+		`{"name":"p","composite":true,"children":[{"name":"c","supportedTypes":["text/plain"]}]}`,
+		`{"text/plain":{"alias":["x"],"supertype":"application/octet-stream"}}`,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var p Parser
+		json.Unmarshal(b, &p)
+		var d Detector
+		json.Unmarshal(b, &d)
+		var mt map[string]MIMEType
+		json.Unmarshal(b, &mt)
+	})
+}
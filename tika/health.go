@@ -0,0 +1,78 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Ready returns nil if the server accepts requests, that is, if it responds
+// to a /version request before ctx is done. A server can be alive but not
+// ready, for example while the JVM starts.
+func (s *Server) Ready(ctx context.Context) error {
+	_, err := NewClient(nil, s.url).Version(ctx)
+	return err
+}
+
+// Alive reports whether the Java process of s is running, without making a
+// request: a server which is busy or still starting is alive. Alive always
+// reports true for an adopted server whose process ID is unknown, since it
+// cannot tell.
+func (s *Server) Alive() bool {
+	if s.adopted && s.proc == nil {
+		return true
+	}
+	pid := s.PID()
+	return pid > 0 && processAlive(pid)
+}
+
+// ReadyHandler returns an http.Handler for readiness probes, such as those
+// of Kubernetes, which responds 200 OK if Ready succeeds within timeout and
+// 503 Service Unavailable otherwise. If timeout is less than or equal to 0,
+// only the deadline of the probe request applies.
+func (s *Server) ReadyHandler(timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if err := s.Ready(ctx); err != nil {
+			http.Error(w, fmt.Sprintf("not ready: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// AliveHandler returns an http.Handler for liveness probes, such as those of
+// Kubernetes, which responds 200 OK if Alive reports true and 503 Service
+// Unavailable otherwise. Unlike a readiness probe, a slow server does not
+// fail it, so a busy server is not restarted.
+func (s *Server) AliveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.Alive() {
+			http.Error(w, "not alive", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestReadyAndAlive(t *testing.T) {
+	s := serviceServer(t, "10")
+	ready, alive := s.ReadyHandler(time.Second), s.AliveHandler()
+	probe := func(h http.Handler) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Code
+	}
+	if s.Alive() {
+		t.Error("Alive() before Start = true, want false")
+	}
+	if got := probe(alive); got != http.StatusServiceUnavailable {
+		t.Errorf("AliveHandler before Start got status %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	if err := s.StartAndWaitReady(context.Background(), ReadinessOptions{PollInterval: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	if !s.Alive() {
+		t.Error("Alive() after Start = false, want true")
+	}
+	if err := s.Ready(context.Background()); err != nil {
+		t.Errorf("Ready() got error: %v", err)
+	}
+	for name, h := range map[string]http.Handler{"ReadyHandler": ready, "AliveHandler": alive} {
+		if got := probe(h); got != http.StatusOK {
+			t.Errorf("%s got status %d, want %d", name, got, http.StatusOK)
+		}
+	}
+
+	// A process which exited without being waited for is not alive. Such
+	// zombies are only detected on Linux.
+	defer s.Stop()
+	if runtime.GOOS != "linux" {
+		return
+	}
+	s.cmd.Process.Kill()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Alive() {
+		if time.Now().After(deadline) {
+			t.Fatal("Alive() = true after the process was killed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReadyHandlerNotReady(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	s, err := Adopt(ts.URL, 0)
+	if err != nil {
+		t.Fatalf("Adopt got error: %v", err)
+	}
+	w := httptest.NewRecorder()
+	s.ReadyHandler(0).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("ReadyHandler got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if !s.Alive() {
+		t.Error("Alive() of an adopted server without a PID = false, want true")
+	}
+}
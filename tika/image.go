@@ -0,0 +1,150 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImageInfo is a typed view of the EXIF and XMP metadata Tika extracts from
+// images. Fields are zero if the metadata is missing or cannot be parsed.
+type ImageInfo struct {
+	// Make and Model identify the camera.
+	Make  string
+	Model string
+	// Width and Height are the dimensions of the image in pixels.
+	Width  int
+	Height int
+	// Orientation is the EXIF orientation, from 1 (normal) to 8.
+	Orientation int
+	// Taken is when the picture was taken. Tika reports it without a time
+	// zone, so it is in UTC unless the metadata contains an offset.
+	Taken time.Time
+	// HasLocation reports whether Latitude and Longitude are set.
+	HasLocation bool
+	// Latitude and Longitude are in decimal degrees, negative for south and
+	// west.
+	Latitude  float64
+	Longitude float64
+	// ExposureTime is the exposure time of the picture.
+	ExposureTime time.Duration
+	// FocalLength is the focal length of the lens in millimetres.
+	FocalLength float64
+}
+
+// Image returns the typed image metadata in m.
+func (m Metadata) Image() ImageInfo {
+	info := ImageInfo{
+		Make:        firstOf(m, "tiff:Make", "Make"),
+		Model:       firstOf(m, "tiff:Model", "Model"),
+		Width:       leadingInt(firstOf(m, "tiff:ImageWidth", "Image Width", "exif:PixelXDimension")),
+		Height:      leadingInt(firstOf(m, "tiff:ImageLength", "Image Height", "exif:PixelYDimension")),
+		Orientation: leadingInt(m.Get("tiff:Orientation")),
+		Taken:       parseTime(firstOf(m, "exif:DateTimeOriginal", "dcterms:created", "meta:creation-date")),
+		FocalLength: leadingFloat(firstOf(m, "exif:FocalLength", "Focal Length")),
+	}
+	if s := exposureSeconds(m.Get("exif:ExposureTime")); s > 0 {
+		info.ExposureTime = time.Duration(s * float64(time.Second))
+	}
+
+	lat, latOK := decimalDegrees(m.Get("geo:lat"), "")
+	long, longOK := decimalDegrees(m.Get("geo:long"), "")
+	if !latOK || !longOK {
+		lat, latOK = decimalDegrees(m.Get("GPS Latitude"), m.Get("GPS Latitude Ref"))
+		long, longOK = decimalDegrees(m.Get("GPS Longitude"), m.Get("GPS Longitude Ref"))
+	}
+	if latOK && longOK {
+		info.HasLocation = true
+		info.Latitude, info.Longitude = lat, long
+	}
+	return info
+}
+
+// numberRE matches a decimal number, with an optional exponent as in the
+// "5.0E-4" of Java's Double.toString.
+var numberRE = regexp.MustCompile(`-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?`)
+
+// fractionRE matches a fraction at the start of a string, such as the
+// "1/2000" of "1/2000 sec".
+var fractionRE = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*/\s*([0-9]+(?:\.[0-9]+)?)`)
+
+// leadingInt parses the integer at the start of s, such as "4000 pixels".
+func leadingInt(s string) int {
+	n, _ := strconv.Atoi(numberRE.FindString(strings.TrimSpace(s)))
+	return n
+}
+
+// leadingFloat parses the number at the start of s, such as "4.2 mm".
+func leadingFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(numberRE.FindString(strings.TrimSpace(s)), 64)
+	return f
+}
+
+// exposureSeconds parses an exposure time in seconds, such as "0.004",
+// "5.0E-4", or "1/2000 sec".
+func exposureSeconds(s string) float64 {
+	s = strings.TrimSpace(s)
+	if f := fractionRE.FindStringSubmatch(s); f != nil {
+		num, _ := strconv.ParseFloat(f[1], 64)
+		den, _ := strconv.ParseFloat(f[2], 64)
+		if den == 0 {
+			return 0
+		}
+		return num / den
+	}
+	return leadingFloat(s)
+}
+
+// decimalDegrees parses a coordinate in decimal degrees, such as "37.7749",
+// or in degrees, minutes, and seconds, such as `37° 46' 29.64"`. Minutes and
+// seconds have the sign of the degrees, so `-37° 30'` is -37.5. ref, if not
+// empty, is the hemisphere: "S" and "W" make the result negative.
+func decimalDegrees(s, ref string) (float64, bool) {
+	parts := numberRE.FindAllString(s, 3)
+	if len(parts) == 0 {
+		return 0, false
+	}
+	var v float64
+	sign := 1.0
+	if strings.HasPrefix(parts[0], "-") {
+		sign = -1
+	}
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, false
+		}
+		if i > 0 && f < 0 {
+			return 0, false
+		}
+		switch i {
+		case 0:
+			v = f
+		case 1:
+			v += sign * f / 60
+		case 2:
+			v += sign * f / 3600
+		}
+	}
+	if ref = strings.ToUpper(strings.TrimSpace(ref)); strings.HasPrefix(ref, "S") || strings.HasPrefix(ref, "W") {
+		v = -v
+	}
+	return v, true
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestImage(t *testing.T) {
+	m := Metadata{
+		"tiff:Make":             {"Canon"},
+		"tiff:Model":            {"Canon EOS 5D"},
+		"tiff:ImageWidth":       {"4000"},
+		"Image Height":          {"3000 pixels"},
+		"tiff:Orientation":      {"6"},
+		"exif:DateTimeOriginal": {"2017-06-01T10:20:30"},
+		"exif:ExposureTime":     {"0.004"},
+		"exif:FocalLength":      {"50.0"},
+		"GPS Latitude":          {`37° 46' 29.64"`},
+		"GPS Latitude Ref":      {"N"},
+		"GPS Longitude":         {`122° 25' 9.84"`},
+		"GPS Longitude Ref":     {"W"},
+	}
+	got := m.Image()
+	want := ImageInfo{
+		Make:         "Canon",
+		Model:        "Canon EOS 5D",
+		Width:        4000,
+		Height:       3000,
+		Orientation:  6,
+		Taken:        time.Date(2017, 6, 1, 10, 20, 30, 0, time.UTC),
+		HasLocation:  true,
+		Latitude:     37.7749,
+		Longitude:    -122.4194,
+		ExposureTime: 4 * time.Millisecond,
+		FocalLength:  50,
+	}
+	if math.Abs(got.Latitude-want.Latitude) > 1e-6 || math.Abs(got.Longitude-want.Longitude) > 1e-6 {
+		t.Errorf("Image got location %v, %v, want %v, %v", got.Latitude, got.Longitude, want.Latitude, want.Longitude)
+	}
+	got.Latitude, got.Longitude = want.Latitude, want.Longitude
+	if got != want {
+		t.Errorf("Image got %+v, want %+v", got, want)
+	}
+
+	// Decimal coordinates take precedence.
+	m = Metadata{"geo:lat": {"-33.8688"}, "geo:long": {"151.2093"}}
+	if got := m.Image(); !got.HasLocation || got.Latitude != -33.8688 || got.Longitude != 151.2093 {
+		t.Errorf("Image got location %v, %v, %v, want true, -33.8688, 151.2093", got.HasLocation, got.Latitude, got.Longitude)
+	}
+	if got := (Metadata{}).Image(); got != (ImageInfo{}) {
+		t.Errorf("Image of empty metadata got %+v, want zero", got)
+	}
+
+	exposures := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"5.0E-4", 500 * time.Microsecond},
+		{"1.0E-3 sec", time.Millisecond},
+		{"1/2000 sec", 500 * time.Microsecond},
+		{"1/4", 250 * time.Millisecond},
+		{"2", 2 * time.Second},
+		{"1/0", 0},
+	}
+	for _, test := range exposures {
+		if got := (Metadata{"exif:ExposureTime": {test.in}}).Image().ExposureTime; got != test.want {
+			t.Errorf("Image with ExposureTime %q got %v, want %v", test.in, got, test.want)
+		}
+	}
+
+	coordinates := []struct {
+		in, ref string
+		want    float64
+	}{
+		{`-37° 30' 36"`, "", -37.51},
+		{`-0° 30'`, "", -0.5},
+		{`37° 30' 36"`, "S", -37.51},
+		{`37° 30' 36"`, "", 37.51},
+	}
+	for _, test := range coordinates {
+		got, ok := decimalDegrees(test.in, test.ref)
+		if !ok || math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("decimalDegrees(%q, %q) = %v, %v, want %v", test.in, test.ref, got, ok, test.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package integration holds end-to-end tests of package tika against a real
+// Tika Server, parsing the sample documents of package tikatest/corpus. The
+// tests are gated by the integration build tag:
+//
+//	go test -tags integration ./tika/integration
+//
+// The server is the one at $TIKA_SERVER_URL if set. Otherwise a server is
+// started from the JAR found by tika.FindServerJAR, such as $TIKA_SERVER_JAR,
+// or from a JAR downloaded to tika.CacheDir, of version $TIKA_VERSION or the
+// latest supported version.
+//
+// The tests also serve as a template for the integration tests of programs
+// using package tika.
+package integration
@@ -0,0 +1,172 @@
+//go:build integration
+
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-tika/tika"
+	"github.com/google/go-tika/tika/tikatest/corpus"
+)
+
+// client is connected to the server used by every test.
+var client *tika.Client
+
+func TestMain(m *testing.M) {
+	stop, err := startServer()
+	if err != nil {
+		log.Fatalf("integration: %v", err)
+	}
+	r := m.Run()
+	stop()
+	os.Exit(r)
+}
+
+// startServer connects client to the server described in the package
+// documentation, returning a function stopping it.
+func startServer() (func(), error) {
+	if u := os.Getenv("TIKA_SERVER_URL"); u != "" {
+		c, err := tika.NewClientURL(nil, u)
+		if err != nil {
+			return nil, err
+		}
+		client = c
+		return func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	jar, err := tika.FindServerJAR()
+	if errors.Is(err, tika.ErrServerJARNotFound) {
+		v := tika.LatestVersion()
+		if env := os.Getenv("TIKA_VERSION"); env != "" {
+			if v, err = tika.ParseVersion(env); err != nil {
+				return nil, err
+			}
+		}
+		jar, err = tika.EnsureServer(ctx, v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no Tika Server JAR: %v", err)
+	}
+	s, err := tika.NewServer(jar, "0")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.StartAndWaitReady(ctx, tika.ReadinessOptions{Timeout: 2 * time.Minute}); err != nil {
+		return nil, err
+	}
+	client = tika.NewClient(nil, s.URL())
+	return func() { s.Shutdown(context.Background()) }, nil
+}
+
+// baseType returns mimeType without parameters.
+func baseType(mimeType string) string {
+	return strings.TrimSpace(strings.Split(mimeType, ";")[0])
+}
+
+func TestVersion(t *testing.T) {
+	v, err := client.Version(context.Background())
+	if err != nil {
+		t.Fatalf("Version got error: %v", err)
+	}
+	if !strings.Contains(v, "Tika") {
+		t.Errorf("Version = %q, want an Apache Tika version", v)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, d := range corpus.All() {
+		got, err := client.Detect(context.Background(), d.Reader())
+		if err != nil {
+			t.Errorf("Detect(%s) got error: %v", d.Name, err)
+			continue
+		}
+		if got != d.MIMEType {
+			t.Errorf("Detect(%s) = %q, want %q", d.Name, got, d.MIMEType)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, d := range corpus.All() {
+		if d.Text == "" {
+			// Images have no text without OCR; see TestMetaRecursive for
+			// archives.
+			continue
+		}
+		got, err := client.Parse(context.Background(), d.Reader())
+		if err != nil {
+			t.Errorf("Parse(%s) got error: %v", d.Name, err)
+			continue
+		}
+		if !strings.Contains(got, d.Text) {
+			t.Errorf("Parse(%s) = %q, want it to contain %q", d.Name, got, d.Text)
+		}
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	for _, d := range corpus.All() {
+		m, err := client.Metadata(context.Background(), d.Reader())
+		if err != nil {
+			t.Errorf("Metadata(%s) got error: %v", d.Name, err)
+			continue
+		}
+		if got := baseType(m.Get("Content-Type")); got != d.MIMEType {
+			t.Errorf("Metadata(%s) Content-Type = %q, want %q", d.Name, got, d.MIMEType)
+		}
+	}
+
+	m, err := client.Metadata(context.Background(), corpus.PDF.Reader())
+	if err != nil {
+		t.Fatalf("Metadata(%s) got error: %v", corpus.PDF.Name, err)
+	}
+	if got := m.Get("dc:title"); got != "PDF fixture" {
+		t.Errorf("Metadata(%s) dc:title = %q, want %q", corpus.PDF.Name, got, "PDF fixture")
+	}
+}
+
+func TestMetaRecursive(t *testing.T) {
+	for _, d := range corpus.All() {
+		if d.Embedded == "" {
+			continue
+		}
+		docs, err := client.MetaRecursive(context.Background(), d.Reader())
+		if err != nil {
+			t.Errorf("MetaRecursive(%s) got error: %v", d.Name, err)
+			continue
+		}
+		found := false
+		for _, doc := range docs[1:] {
+			if strings.Contains(tika.Metadata(doc).Get(tika.XTIKAContent), d.Embedded) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("MetaRecursive(%s) got %d documents, want an embedded document containing %q", d.Name, len(docs), d.Embedded)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+//go:build go1.23
+
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the Results of parsing every Input in inputs.
+// Per-input errors are yielded alongside the Result of that input. If ctx is
+// done, the final pair yielded has ctx.Err() as its error. See Run.
+func (b *Batch) All(ctx context.Context, inputs iter.Seq[Input]) iter.Seq2[Result, error] {
+	return func(yield func(Result, error) bool) {
+		stopped := false
+		err := b.Run(ctx, inputs, func(r Result, err error) bool {
+			if !yield(r, err) {
+				stopped = true
+			}
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(Result{}, err)
+		}
+	}
+}
+
+// ParseAllSeq returns an iterator over the Results of parsing every Input in
+// inputs one at a time. To parse inputs concurrently, use Batch.All.
+func (c *Client) ParseAllSeq(ctx context.Context, inputs iter.Seq[Input]) iter.Seq2[Result, error] {
+	b := &Batch{Client: c}
+	return b.All(ctx, inputs)
+}
+
+// WalkSeq is like Walk, but returns an iter.Seq.
+func WalkSeq(root string) iter.Seq[Input] {
+	return Walk(root)
+}
+
+// errStopSeq is used to stop MetaRecursiveStream when the consumer of
+// MetaRecursiveSeq stops iterating.
+var errStopSeq = errors.New("iteration stopped")
+
+// MetaRecursiveSeq is like MetaRecursiveStream, but returns an iterator over
+// the Metadata of each document. If an error occurs, it is yielded with nil
+// Metadata and iteration ends.
+func (c *Client) MetaRecursiveSeq(ctx context.Context, input io.Reader, contentType string) iter.Seq2[Metadata, error] {
+	return func(yield func(Metadata, error) bool) {
+		err := c.MetaRecursiveStream(ctx, input, contentType, func(m Metadata) error {
+			if !yield(m, nil) {
+				return errStopSeq
+			}
+			return nil
+		})
+		if err != nil && err != errStopSeq {
+			yield(nil, err)
+		}
+	}
+}
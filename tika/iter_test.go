@@ -0,0 +1,81 @@
+//go:build go1.23
+
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestParseAllSeq(t *testing.T) {
+	ts := echoServer()
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	inputs := Inputs(stringInput("a", "content a"), stringInput("b", "content b"))
+
+	var got []string
+	for r, err := range c.ParseAllSeq(context.Background(), inputs) {
+		if err != nil {
+			t.Fatalf("ParseAllSeq yielded an error: %v", err)
+		}
+		got = append(got, r.Content)
+	}
+	if want := []string{"content a", "content b"}; !slices.Equal(got, want) {
+		t.Errorf("ParseAllSeq got %v, want %v", got, want)
+	}
+
+	n := 0
+	for range c.ParseAllSeq(context.Background(), inputs) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("ParseAllSeq continued after break: got %d results", n)
+	}
+}
+
+func TestMetaRecursiveSeq(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"X-TIKA:content":"test 1"},{"X-TIKA:content":"test 2"}]`)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+
+	var got []string
+	for m, err := range c.MetaRecursiveSeq(context.Background(), nil, "text") {
+		if err != nil {
+			t.Fatalf("MetaRecursiveSeq yielded an error: %v", err)
+		}
+		got = append(got, m[XTIKAContent]...)
+		break
+	}
+	if want := []string{"test 1"}; !slices.Equal(got, want) {
+		t.Errorf("MetaRecursiveSeq got %v, want %v", got, want)
+	}
+
+	for _, err := range errorClient.MetaRecursiveSeq(context.Background(), nil, "text") {
+		if err == nil {
+			t.Error("MetaRecursiveSeq yielded no error, want an error")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// A Journal records the Inputs a Batch has processed, along with a hash of
+// their content, so an interrupted Batch can resume where it left off and
+// unchanged inputs are skipped when a Batch is run again. See Batch.Journal.
+//
+// A Journal is stored in a file with one JSON object per line. It is safe for
+// concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	f       *os.File
+	entries map[string]string
+}
+
+// journalEntry is a line of a Journal file.
+type journalEntry struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// OpenJournal opens the Journal stored at path, creating it if it does not
+// exist. Lines which cannot be decoded, such as a line truncated by a crash,
+// are ignored.
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{f: f, entries: map[string]string{}}
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		var e journalEntry
+		if json.Unmarshal(s.Bytes(), &e) == nil {
+			j.entries[e.Name] = e.SHA256
+		}
+	}
+	if err := s.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// Done reports whether the Input name has been recorded with the given
+// content hash.
+func (j *Journal) Done(name, hash string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	h, ok := j.entries[name]
+	return ok && h == hash
+}
+
+// Record records that the Input name with the given content hash has been
+// processed.
+func (j *Journal) Record(name, hash string) error {
+	b, err := json.Marshal(journalEntry{Name: name, SHA256: hash})
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	j.entries[name] = hash
+	return nil
+}
+
+// Close closes the Journal file.
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
+
+// hashInput returns the hex encoded SHA-256 hash of the content of input.
+func hashInput(input Input) (string, error) {
+	r, err := input.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
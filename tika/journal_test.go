@@ -0,0 +1,151 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal")
+
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal returned an error: %v", err)
+	}
+	if err := j.Record("a", "1"); err != nil {
+		t.Fatalf("Record returned an error: %v", err)
+	}
+	j.Close()
+
+	// Simulate a crash while writing a line.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"name":"b","sha`)
+	f.Close()
+
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal returned an error: %v", err)
+	}
+	defer j.Close()
+	if !j.Done("a", "1") {
+		t.Error("Done(a, 1) got false, want true")
+	}
+	if j.Done("a", "2") {
+		t.Error("Done(a, 2) got true, want false")
+	}
+	if j.Done("b", "") {
+		t.Error("Done(b) got true for a truncated line, want false")
+	}
+}
+
+func TestBatchJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	j, err := OpenJournal(filepath.Join(dir, "journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	ts := echoServer()
+	defer ts.Close()
+	b := &Batch{Client: NewClient(nil, ts.URL), Journal: j}
+
+	run := func(inputs ...Input) []Result {
+		var got []Result
+		err := b.Run(context.Background(), Inputs(inputs...), func(r Result, err error) bool {
+			if err != nil {
+				t.Errorf("Run got error for %s: %v", r.Name, err)
+			}
+			got = append(got, r)
+			return true
+		})
+		if err != nil {
+			t.Fatalf("Run returned an error: %v", err)
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i].Name < got[j].Name })
+		return got
+	}
+
+	run(stringInput("a", "A"), stringInput("b", "B"))
+
+	b.DryRun = true
+	got := run(stringInput("a", "A"), stringInput("b", "changed"), stringInput("c", "C"))
+	want := []Result{{Name: "a", Skipped: true, SkipReason: SkipUnchanged}, {Name: "b"}, {Name: "c"}}
+	if len(got) != len(want) {
+		t.Fatalf("dry run got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("dry run got %+v, want %+v", got[i], want[i])
+		}
+	}
+
+	b.DryRun = false
+	got = run(stringInput("a", "A"), stringInput("b", "changed"), stringInput("c", "C"))
+	want = []Result{{Name: "a", Skipped: true, SkipReason: SkipUnchanged}, {Name: "b", Content: "changed"}, {Name: "c", Content: "C"}}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("resumed run got %+v, want %+v", got[i], want[i])
+		}
+	}
+	if len(j.entries) != 3 {
+		t.Errorf("journal has %d entries, want 3", len(j.entries))
+	}
+	// Inputs skipped as too large or quarantined are not read to be hashed.
+	q, err := OpenQuarantine(filepath.Join(dir, "quarantine"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	q.crashes["poison"] = 2
+	b.Quarantine, b.MaxInputBytes = q, 4
+	opened := func() (io.ReadCloser, error) {
+		t.Error("Run opened an input skipped before hashing")
+		return nil, errors.New("opened")
+	}
+	got = run(Input{Name: "large", Size: 5, Open: opened}, Input{Name: "poison", Open: opened})
+	want = []Result{{Name: "large", Skipped: true, SkipReason: SkipTooLarge}, {Name: "poison", Skipped: true, SkipReason: SkipQuarantined}}
+	if len(got) != len(want) {
+		t.Fatalf("run of skipped inputs got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("run of skipped inputs got %+v, want %+v", got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import "sort"
+
+// DefaultKeyMap maps metadata keys used by some Tika versions to the
+// canonical key of the same property, based on the Dublin Core and XMP keys
+// used by Tika 2 and later. See WithCanonicalKeys.
+var DefaultKeyMap = map[string]string{
+	"Author":             "dc:creator",
+	"meta:author":        "dc:creator",
+	"creator":            "dc:creator",
+	"title":              "dc:title",
+	"subject":            "dc:subject",
+	"description":        "dc:description",
+	"Keywords":           "meta:keyword",
+	"Creation-Date":      "dcterms:created",
+	"meta:creation-date": "dcterms:created",
+	"created":            "dcterms:created",
+	"Last-Modified":      "dcterms:modified",
+	"Last-Save-Date":     "dcterms:modified",
+	"meta:save-date":     "dcterms:modified",
+	"modified":           "dcterms:modified",
+	"date":               "dcterms:modified",
+	"Last-Author":        "meta:last-author",
+	"Page-Count":         "xmpTPg:NPages",
+	"meta:page-count":    "xmpTPg:NPages",
+	"Word-Count":         "meta:word-count",
+	"Character Count":    "meta:character-count",
+	"Application-Name":   "extended-properties:Application",
+	"Company":            "extended-properties:Company",
+	"Content-Language":   "dc:language",
+	"language":           "dc:language",
+}
+
+// WithCanonicalKeys renames metadata keys using table, which maps a key to
+// its canonical name, so that output from servers of different versions uses
+// the same keys. If table is nil, DefaultKeyMap is used. It applies to the
+// Metadata returned by the Metadata and recursive metadata methods.
+func WithCanonicalKeys(table map[string]string) Option {
+	if table == nil {
+		table = DefaultKeyMap
+	}
+	return func(c *Client) {
+		c.keyMap = table
+	}
+}
+
+// CanonicalKeys returns m with its keys renamed using table, as described in
+// WithCanonicalKeys. When several keys map to the same canonical key, the
+// values of the canonical key come first, followed by the values of the
+// other keys in the order of their names, without duplicates. m is not
+// modified.
+func (m Metadata) CanonicalKeys(table map[string]string) Metadata {
+	if table == nil {
+		table = DefaultKeyMap
+	}
+	r := make(Metadata, len(m))
+	var aliases []string
+	for k, v := range m {
+		if _, ok := table[k]; ok {
+			aliases = append(aliases, k)
+			continue
+		}
+		r[k] = append([]string(nil), v...)
+	}
+	sort.Strings(aliases)
+	for _, k := range aliases {
+		to := table[k]
+		for _, v := range m[k] {
+			if !containsString(r[to], v) {
+				r[to] = append(r[to], v)
+			}
+		}
+	}
+	return r
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
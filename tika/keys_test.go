@@ -0,0 +1,82 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Metadata
+		want Metadata
+	}{
+		{
+			name: "Tika 1",
+			in:   Metadata{"Author": {"a"}, "meta:author": {"a"}, "Page-Count": {"3"}, "X": {"x"}},
+			want: Metadata{"dc:creator": {"a"}, "xmpTPg:NPages": {"3"}, "X": {"x"}},
+		},
+		{
+			name: "canonical first",
+			in:   Metadata{"dc:creator": {"b"}, "meta:author": {"a", "b"}},
+			want: Metadata{"dc:creator": {"b", "a"}},
+		},
+		{
+			name: "aliases in name order",
+			in:   Metadata{"meta:save-date": {"2"}, "Last-Modified": {"1"}},
+			want: Metadata{"dcterms:modified": {"1", "2"}},
+		},
+	}
+	for _, test := range tests {
+		if got := test.in.CanonicalKeys(nil); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("CanonicalKeys(%s) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWithCanonicalKeys(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/meta":
+			fmt.Fprint(w, `{"Author":"a"}`)
+		default:
+			fmt.Fprint(w, `[{"Author":"a"},{"Author":"b","X-TIKA:embedded_resource_path":"/b"}]`)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL, WithCanonicalKeys(map[string]string{"Author": "creator"}))
+	m, err := c.Metadata(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Metadata got error: %v", err)
+	}
+	if want := (Metadata{"creator": {"a"}}); !reflect.DeepEqual(m, want) {
+		t.Errorf("Metadata = %v, want %v", m, want)
+	}
+	docs, err := c.MetaRecursive(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("MetaRecursive got error: %v", err)
+	}
+	if len(docs) != 2 || docs[1]["creator"][0] != "b" {
+		t.Errorf("MetaRecursive = %v, want creator keys", docs)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// UnmarshalJSON decodes a JSON object of metadata, as returned by the /meta
+// and /rmeta endpoints. Each value may be a string or an array of strings.
+// Numbers and booleans, which some servers emit for some fields, are kept as
+// their JSON text, such as "42" or "true", and null values are dropped.
+// Nested objects and arrays are an error.
+func (m *Metadata) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		// null.
+		return nil
+	}
+	md := make(Metadata, len(raw))
+	for k, v := range raw {
+		v = bytes.TrimSpace(v)
+		if len(v) > 0 && v[0] == '[' {
+			var vs []json.RawMessage
+			if err := json.Unmarshal(v, &vs); err != nil {
+				return err
+			}
+			values := make([]string, 0, len(vs))
+			for _, e := range vs {
+				s, ok, err := scalarString(e)
+				if err != nil {
+					return fmt.Errorf("field %q: %v", k, err)
+				}
+				if ok {
+					values = append(values, s)
+				}
+			}
+			md[k] = values
+			continue
+		}
+		s, ok, err := scalarString(v)
+		if err != nil {
+			return fmt.Errorf("field %q: %v", k, err)
+		}
+		if ok {
+			md[k] = []string{s}
+		}
+	}
+	*m = md
+	return nil
+}
+
+// scalarString returns the string form of the JSON scalar v. It returns false
+// if v is null, and an error if v is an object or array.
+func scalarString(v json.RawMessage) (string, bool, error) {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return "", false, fmt.Errorf("empty value")
+	}
+	switch v[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return "", false, err
+		}
+		return s, true, nil
+	case 'n':
+		return "", false, nil
+	case 't', 'f', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		// json.RawMessage is already valid JSON, so this is a boolean or
+		// a number.
+		return string(v), true, nil
+	}
+	return "", false, fmt.Errorf("value %s has an unsupported type, expected a string or []string", v)
+}
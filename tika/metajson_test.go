@@ -0,0 +1,53 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMetadataUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Metadata
+		wantErr bool
+	}{
+		{in: `{"a":"b"}`, want: Metadata{"a": {"b"}}},
+		{in: `{"a":["b","c"]}`, want: Metadata{"a": {"b", "c"}}},
+		{in: `{"a":[]}`, want: Metadata{"a": {}}},
+		{in: `{"pages":3,"ratio":-1.5e3}`, want: Metadata{"pages": {"3"}, "ratio": {"-1.5e3"}}},
+		{in: `{"encrypted":false,"ok":true}`, want: Metadata{"encrypted": {"false"}, "ok": {"true"}}},
+		{in: `{"a":null,"b":["c",null,1]}`, want: Metadata{"b": {"c", "1"}}},
+		{in: `null`, want: nil},
+		{in: `{"a":{"b":"c"}}`, wantErr: true},
+		{in: `{"a":["b",["c"]]}`, wantErr: true},
+		{in: `["a"]`, wantErr: true},
+	}
+	for _, test := range tests {
+		var got Metadata
+		err := json.Unmarshal([]byte(test.in), &got)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("Unmarshal(%s) got error %v, want error %v", test.in, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// MetaKind is the JSON type of a MetaValue.
+type MetaKind int
+
+// Kinds of MetaValue.
+const (
+	MetaNull MetaKind = iota
+	MetaString
+	MetaNumber
+	MetaBool
+	MetaList
+)
+
+// A MetaValue is a metadata value with its JSON type, so values such as page
+// counts and image dimensions emitted as numbers keep their type. The zero
+// value is null.
+type MetaValue struct {
+	kind MetaKind
+	s    string // s is the string, or the text of the number.
+	b    bool
+	list []MetaValue
+}
+
+// StringValue returns a MetaValue holding s.
+func StringValue(s string) MetaValue { return MetaValue{kind: MetaString, s: s} }
+
+// NumberValue returns a MetaValue holding n.
+func NumberValue(n json.Number) MetaValue { return MetaValue{kind: MetaNumber, s: string(n)} }
+
+// BoolValue returns a MetaValue holding b.
+func BoolValue(b bool) MetaValue { return MetaValue{kind: MetaBool, b: b} }
+
+// ListValue returns a MetaValue holding vs.
+func ListValue(vs ...MetaValue) MetaValue { return MetaValue{kind: MetaList, list: vs} }
+
+// Kind returns the type of v.
+func (v MetaValue) Kind() MetaKind { return v.kind }
+
+// String returns v as a string: strings are returned as is, numbers and
+// booleans as their JSON text, null as "", and lists as their first element.
+func (v MetaValue) String() string {
+	switch v.kind {
+	case MetaString, MetaNumber:
+		return v.s
+	case MetaBool:
+		return strconv.FormatBool(v.b)
+	case MetaList:
+		if len(v.list) > 0 {
+			return v.list[0].String()
+		}
+	}
+	return ""
+}
+
+// Strings returns v as a list of strings, as in Metadata. Null values are
+// omitted.
+func (v MetaValue) Strings() []string {
+	switch v.kind {
+	case MetaNull:
+		return nil
+	case MetaList:
+		ss := make([]string, 0, len(v.list))
+		for _, e := range v.list {
+			if e.kind != MetaNull {
+				ss = append(ss, e.String())
+			}
+		}
+		return ss
+	}
+	return []string{v.String()}
+}
+
+// Float returns v as a float64. Strings holding a number, such as "3", are
+// converted, since most servers emit numbers as strings. It returns false if
+// v is not a number.
+func (v MetaValue) Float() (float64, bool) {
+	if v.kind != MetaNumber && v.kind != MetaString {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v.s, 64)
+	return f, err == nil
+}
+
+// Int is like Float, but for integers.
+func (v MetaValue) Int() (int64, bool) {
+	if v.kind != MetaNumber && v.kind != MetaString {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(v.s, 10, 64)
+	return i, err == nil
+}
+
+// Bool returns v as a bool. The strings "true" and "false" are converted. It
+// returns false if v is not a boolean.
+func (v MetaValue) Bool() (bool, bool) {
+	switch v.kind {
+	case MetaBool:
+		return v.b, true
+	case MetaString:
+		return v.s == "true", v.s == "true" || v.s == "false"
+	}
+	return false, false
+}
+
+// List returns the elements of v if it is a list, else nil.
+func (v MetaValue) List() []MetaValue {
+	return v.list
+}
+
+// MarshalJSON encodes v as its JSON type.
+func (v MetaValue) MarshalJSON() ([]byte, error) {
+	switch v.kind {
+	case MetaString:
+		return json.Marshal(v.s)
+	case MetaNumber:
+		return []byte(v.s), nil
+	case MetaBool:
+		return json.Marshal(v.b)
+	case MetaList:
+		if v.list == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(v.list)
+	}
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON decodes a JSON string, number, boolean, null, or array of
+// those. Objects are an error.
+func (v *MetaValue) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return fmt.Errorf("empty value")
+	}
+	switch b[0] {
+	case 'n':
+		*v = MetaValue{}
+	case '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*v = StringValue(s)
+	case 't', 'f':
+		var x bool
+		if err := json.Unmarshal(b, &x); err != nil {
+			return err
+		}
+		*v = BoolValue(x)
+	case '[':
+		var list []MetaValue
+		if err := json.Unmarshal(b, &list); err != nil {
+			return err
+		}
+		if list == nil {
+			list = []MetaValue{}
+		}
+		*v = ListValue(list...)
+	case '{':
+		return fmt.Errorf("value %s is an object, expected a string, number, boolean, or list", b)
+	default:
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return err
+		}
+		*v = NumberValue(n)
+	}
+	return nil
+}
+
+// TypedMetadata is like Metadata, but keeps the JSON type of every value.
+type TypedMetadata map[string]MetaValue
+
+// Get returns the value of key, or null if there is none.
+func (m TypedMetadata) Get(key string) MetaValue {
+	return m[key]
+}
+
+// Metadata converts m to Metadata, converting values to strings.
+func (m TypedMetadata) Metadata() Metadata {
+	md := make(Metadata, len(m))
+	for k, v := range m {
+		if v.kind != MetaNull {
+			md[k] = v.Strings()
+		}
+	}
+	return md
+}
+
+// MetaRecursiveTyped is like MetaRecursiveType, but keeps the JSON type of
+// every metadata value. The filters set with WithEmbeddedTypes apply, and
+// WithRedactor masks the strings, but WithTextOptions and WithStrictEmbedded
+// do not apply.
+func (c *Client) MetaRecursiveTyped(ctx context.Context, input io.Reader, contentType string) ([]TypedMetadata, error) {
+	path := "/rmeta"
+	if contentType != "" {
+		path = fmt.Sprintf("/rmeta/%s", contentType)
+	}
+	body, err := c.call(ctx, input, "PUT", path, jsonHeader)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var docs []TypedMetadata
+	if err := json.NewDecoder(body).Decode(&docs); err != nil {
+		return nil, err
+	}
+	r := docs[:0]
+	for _, d := range docs {
+		if d == nil {
+			d = TypedMetadata{}
+		}
+		if d.Get(XTIKAEmbeddedResourcePath).String() != "" && !c.wantEmbedded(d.Get("Content-Type").String()) {
+			continue
+		}
+		if c.redactor != nil {
+			d = redactTyped(d, c.redactor)
+		}
+		r = append(r, d)
+	}
+	return r, nil
+}
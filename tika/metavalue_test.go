@@ -0,0 +1,126 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMetaValueUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    MetaValue
+		wantErr bool
+	}{
+		{in: `"a"`, want: StringValue("a")},
+		{in: `3`, want: NumberValue("3")},
+		{in: `-1.5e3`, want: NumberValue("-1.5e3")},
+		{in: `true`, want: BoolValue(true)},
+		{in: `null`, want: MetaValue{}},
+		{in: `["a",1,false]`, want: ListValue(StringValue("a"), NumberValue("1"), BoolValue(false))},
+		{in: `[]`, want: ListValue()},
+		{in: `{"a":"b"}`, wantErr: true},
+	}
+	for _, test := range tests {
+		var got MetaValue
+		err := json.Unmarshal([]byte(test.in), &got)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("Unmarshal(%s) got error %v, want error %v", test.in, err, test.wantErr)
+			continue
+		}
+		if test.wantErr {
+			continue
+		}
+		if got.Kind() != test.want.Kind() || got.String() != test.want.String() || len(got.List()) != len(test.want.List()) {
+			t.Errorf("Unmarshal(%s) = %#v, want %#v", test.in, got, test.want)
+		}
+		out, err := json.Marshal(got)
+		if err != nil {
+			t.Errorf("Marshal(%#v) got error: %v", got, err)
+		} else if string(out) != test.in {
+			t.Errorf("Marshal(Unmarshal(%s)) = %s", test.in, out)
+		}
+	}
+}
+
+func TestMetaValueAccessors(t *testing.T) {
+	if i, ok := NumberValue("42").Int(); !ok || i != 42 {
+		t.Errorf("NumberValue(42).Int() = %d, %v, want 42, true", i, ok)
+	}
+	if i, ok := StringValue("7").Int(); !ok || i != 7 {
+		t.Errorf("StringValue(7).Int() = %d, %v, want 7, true", i, ok)
+	}
+	if _, ok := NumberValue("1.5").Int(); ok {
+		t.Error("NumberValue(1.5).Int() got ok, want not ok")
+	}
+	if f, ok := NumberValue("1.5").Float(); !ok || f != 1.5 {
+		t.Errorf("NumberValue(1.5).Float() = %v, %v, want 1.5, true", f, ok)
+	}
+	if _, ok := BoolValue(true).Float(); ok {
+		t.Error("BoolValue(true).Float() got ok, want not ok")
+	}
+	if b, ok := StringValue("false").Bool(); !ok || b {
+		t.Errorf(`StringValue("false").Bool() = %v, %v, want false, true`, b, ok)
+	}
+	if _, ok := StringValue("yes").Bool(); ok {
+		t.Error(`StringValue("yes").Bool() got ok, want not ok`)
+	}
+	l := ListValue(StringValue("a"), MetaValue{}, NumberValue("2"))
+	if got, want := l.Strings(), []string{"a", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Strings() = %v, want %v", got, want)
+	}
+}
+
+func TestMetaRecursiveTyped(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"xmpTPg:NPages":3,"encrypted":false,"dc:title":"t"},{"X-TIKA:embedded_resource_path":"/a.png","Content-Type":"image/png","tiff:ImageWidth":[640]}]`)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	docs, err := c.MetaRecursiveTyped(context.Background(), nil, "text")
+	if err != nil {
+		t.Fatalf("MetaRecursiveTyped got error: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("MetaRecursiveTyped returned %d documents, want 2", len(docs))
+	}
+	if v := docs[0].Get("xmpTPg:NPages"); v.Kind() != MetaNumber {
+		t.Errorf("page count has kind %v, want MetaNumber", v.Kind())
+	}
+	if w, ok := docs[1].Get("tiff:ImageWidth").List()[0].Int(); !ok || w != 640 {
+		t.Errorf("image width = %d, %v, want 640", w, ok)
+	}
+	want := Metadata{"xmpTPg:NPages": {"3"}, "encrypted": {"false"}, "dc:title": {"t"}}
+	if got := docs[0].Metadata(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Metadata() = %v, want %v", got, want)
+	}
+
+	c = NewClient(nil, ts.URL, WithEmbeddedTypes("application/pdf"))
+	docs, err = c.MetaRecursiveTyped(context.Background(), nil, "text")
+	if err != nil {
+		t.Fatalf("MetaRecursiveTyped got error: %v", err)
+	}
+	if len(docs) != 1 {
+		t.Errorf("MetaRecursiveTyped with a filter returned %d documents, want 1", len(docs))
+	}
+}
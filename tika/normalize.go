@@ -0,0 +1,104 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextOptions configure the normalization of extracted text. See
+// WithTextOptions.
+type TextOptions struct {
+	// StripControl removes control characters other than newline and tab.
+	StripControl bool
+	// CollapseWhitespace replaces runs of spaces and tabs with a single
+	// space, removes trailing spaces from lines, replaces runs of blank lines
+	// with a single blank line, and trims the text.
+	CollapseWhitespace bool
+	// RemoveSoftHyphens removes soft hyphens (U+00AD), which PDFs often
+	// contain at line breaks.
+	RemoveSoftHyphens bool
+	// NFC normalizes the text to Unicode Normalization Form C, composing
+	// characters that extraction left decomposed, such as "e" followed by a
+	// combining acute accent, so that equal text compares equal.
+	NFC bool
+	// Unicode, if not nil, is applied last to the text, for example to use
+	// another normalization form such as norm.NFKC.String from
+	// golang.org/x/text/unicode/norm.
+	Unicode func(string) string
+}
+
+// WithTextOptions normalizes the text returned by Parse, ParseWithHeader, and
+// in the XTIKAContent field of recursive results with o. Readers returned by
+// ParseReader are not normalized; use o.Apply on their content instead.
+func WithTextOptions(o TextOptions) Option {
+	return func(c *Client) {
+		c.textOptions = &o
+	}
+}
+
+// Apply returns s normalized according to o.
+func (o TextOptions) Apply(s string) string {
+	if o.StripControl || o.RemoveSoftHyphens {
+		s = strings.Map(func(r rune) rune {
+			if o.StripControl && unicode.IsControl(r) && r != '\n' && r != '\t' {
+				return -1
+			}
+			if o.RemoveSoftHyphens && r == '\u00ad' {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	if o.CollapseWhitespace {
+		s = collapseWhitespace(s)
+	}
+	if o.NFC {
+		s = norm.NFC.String(s)
+	}
+	if o.Unicode != nil {
+		s = o.Unicode(s)
+	}
+	return s
+}
+
+// collapseWhitespace implements TextOptions.CollapseWhitespace.
+func collapseWhitespace(s string) string {
+	b := &strings.Builder{}
+	blank := 0
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line = strings.Join(strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '\r' || r == '\u00a0'
+		}), " ")
+		if line == "" {
+			blank++
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+			if blank > 0 {
+				b.WriteByte('\n')
+			}
+		}
+		blank = 0
+		b.WriteString(line)
+	}
+	return b.String()
+}
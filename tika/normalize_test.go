@@ -0,0 +1,97 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTextOptionsApply(t *testing.T) {
+	in := "  Hyphen\u00adated \x00word\x07\t\there  \r\n\n\n\nnext  line\n\n"
+	tests := []struct {
+		name string
+		opts TextOptions
+		want string
+	}{
+		{"none", TextOptions{}, in},
+		{"control", TextOptions{StripControl: true}, "  Hyphen\u00adated word\t\there  \n\n\n\nnext  line\n\n"},
+		{"soft hyphens", TextOptions{RemoveSoftHyphens: true}, strings.Replace(in, "\u00ad", "", 1)},
+		{"whitespace", TextOptions{CollapseWhitespace: true}, "Hyphen\u00adated \x00word\x07 here\n\nnext line"},
+		{"all", TextOptions{StripControl: true, CollapseWhitespace: true, RemoveSoftHyphens: true, Unicode: strings.ToUpper}, "HYPHENATED WORD HERE\n\nNEXT LINE"},
+	}
+	for _, test := range tests {
+		if got := test.opts.Apply(in); got != test.want {
+			t.Errorf("Apply(%s) got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestTextOptionsNFC(t *testing.T) {
+	composed := "caf\u00e9 \u00c5ngstr\u00f6m"
+	decomposed := "cafe\u0301 A\u030angstro\u0308m"
+	tests := []struct {
+		name string
+		in   string
+		opts TextOptions
+		want string
+	}{
+		{"decomposed", decomposed, TextOptions{NFC: true}, composed},
+		{"composed", composed, TextOptions{NFC: true}, composed},
+		{"disabled", decomposed, TextOptions{}, decomposed},
+		// Compatibility characters, such as the "fi" ligature, are kept.
+		{"ligature", "\ufb01le", TextOptions{NFC: true}, "\ufb01le"},
+		{"then Unicode", decomposed, TextOptions{NFC: true, Unicode: strings.ToUpper}, strings.ToUpper(composed)},
+	}
+	for _, test := range tests {
+		if got := test.opts.Apply(test.in); got != test.want {
+			t.Errorf("Apply(%s) got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWithTextOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rmeta/text" {
+			fmt.Fprint(w, `[{"X-TIKA:content":"  a \n\n\n b "}]`)
+			return
+		}
+		fmt.Fprint(w, "  a \n\n\n b ")
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, ts.URL, WithTextOptions(TextOptions{CollapseWhitespace: true}))
+	want := "a\n\nb"
+	got, err := c.Parse(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Parse got %q, want %q", got, want)
+	}
+	docs, err := c.ParseRecursive(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ParseRecursive returned an error: %v", err)
+	}
+	if len(docs) != 1 || docs[0] != want {
+		t.Errorf("ParseRecursive got %q, want [%q]", docs, want)
+	}
+}
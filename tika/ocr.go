@@ -0,0 +1,355 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// OCROutputTypeHeader is the header choosing the output of the Tesseract OCR
+// parser. See OCROutput.
+const OCROutputTypeHeader = "X-Tika-OCRoutputType"
+
+// An OCRFormat is an output format of Tesseract with word positions.
+type OCRFormat string
+
+// OCR formats.
+const (
+	// OCRHOCR is hOCR, HTML with the positions and confidences of words in
+	// title attributes. Tika supports it out of the box.
+	OCRHOCR OCRFormat = "hocr"
+	// OCRALTO is ALTO XML. The server must be configured for it.
+	OCRALTO OCRFormat = "alto"
+)
+
+// OCROutput sets the OCROutputTypeHeader of header to format and returns
+// header. If header is nil, a new header is created.
+func OCROutput(header http.Header, format OCRFormat) http.Header {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(OCROutputTypeHeader, string(format))
+	return header
+}
+
+// A BBox is a bounding box in pixels of the OCRed image, from its top left
+// corner (X0, Y0) to its bottom right corner (X1, Y1).
+type BBox struct {
+	X0, Y0, X1, Y1 int
+}
+
+// An OCRWord is a word recognized by OCR.
+type OCRWord struct {
+	Text string
+	BBox BBox
+	// Confidence is the confidence of the OCR engine in Text, from 0 to 1,
+	// or -1 if it was not reported.
+	Confidence float64
+}
+
+// An OCRLine is a line of text recognized by OCR.
+type OCRLine struct {
+	BBox  BBox
+	Words []OCRWord
+}
+
+// An OCRPage is a page, or image, processed by OCR.
+type OCRPage struct {
+	// Number is the number of the page, starting at 1.
+	Number int
+	BBox   BBox
+	Lines  []OCRLine
+}
+
+// An OCRResult is the word-level output of OCR, as returned by OCR,
+// ParseHOCR, and ParseALTO.
+type OCRResult struct {
+	Pages []OCRPage
+}
+
+// Words returns every word of r, in reading order.
+func (r *OCRResult) Words() []OCRWord {
+	var words []OCRWord
+	for _, p := range r.Pages {
+		for _, l := range p.Lines {
+			words = append(words, l.Words...)
+		}
+	}
+	return words
+}
+
+// LowConfidence returns the words of r with a known confidence below
+// threshold, for review.
+func (r *OCRResult) LowConfidence(threshold float64) []OCRWord {
+	var words []OCRWord
+	for _, w := range r.Words() {
+		if w.Confidence >= 0 && w.Confidence < threshold {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// Text returns the text of r, with the words of a line separated by spaces,
+// one line per line, and pages separated by blank lines.
+func (r *OCRResult) Text() string {
+	var b strings.Builder
+	for i, p := range r.Pages {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, l := range p.Lines {
+			for j, w := range l.Words {
+				if j > 0 {
+					b.WriteString(" ")
+				}
+				b.WriteString(w.Text)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// OCR runs OCR on input, an image or a document with images, and returns
+// the words recognized with their positions and confidences. header, which
+// may be nil, is sent with the request, for example to choose the OCR
+// language; the OCROutputTypeHeader is set to format. If the Client was
+// created with WithRedactor, OCR returns ErrNotRedactable.
+func (c *Client) OCR(ctx context.Context, input io.Reader, format OCRFormat, header http.Header) (*OCRResult, error) {
+	if err := c.redactable(); err != nil {
+		return nil, err
+	}
+	header = OCROutput(header.Clone(), format)
+	header.Set("Accept", "text/html")
+	body, err := c.call(ctx, input, "PUT", "/tika", header)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	if format == OCRALTO {
+		return ParseALTO(body)
+	}
+	return ParseHOCR(body)
+}
+
+// ocrDecoder returns a lenient XML decoder for r, accepting HTML.
+func ocrDecoder(r io.Reader) *xml.Decoder {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	return d
+}
+
+// ParseHOCR parses the hOCR in r, which may be embedded in the XHTML output
+// of Tika. Pages are elements of class ocr_page, lines of class ocr_line,
+// ocr_caption, ocr_header, or ocr_textfloat, and words of class ocrx_word.
+// Words outside of a line are put in a line of their own.
+func ParseHOCR(r io.Reader) (*OCRResult, error) {
+	d := ocrDecoder(r)
+	res := &OCRResult{}
+	var (
+		stack []hocrKind
+		text  strings.Builder
+	)
+	current := func() *OCRPage {
+		if len(res.Pages) == 0 {
+			res.Pages = append(res.Pages, OCRPage{Number: 1})
+		}
+		return &res.Pages[len(res.Pages)-1]
+	}
+	inWord := func() bool {
+		return len(stack) > 0 && stack[len(stack)-1] == hocrWord
+	}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			kind := hocrOther
+			title := parseHOCRTitle(attr(t.Attr, "title"))
+			switch classes := " " + attr(t.Attr, "class") + " "; {
+			case strings.Contains(classes, " ocr_page "):
+				kind = hocrPage
+				res.Pages = append(res.Pages, OCRPage{Number: len(res.Pages) + 1, BBox: title.bbox})
+			case strings.Contains(classes, " ocr_line "), strings.Contains(classes, " ocr_caption "),
+				strings.Contains(classes, " ocr_header "), strings.Contains(classes, " ocr_textfloat "):
+				kind = hocrLine
+				p := current()
+				p.Lines = append(p.Lines, OCRLine{BBox: title.bbox})
+			case strings.Contains(classes, " ocrx_word "):
+				kind = hocrWord
+				p := current()
+				if len(p.Lines) == 0 || !inLine(stack) {
+					p.Lines = append(p.Lines, OCRLine{BBox: title.bbox})
+				}
+				l := &p.Lines[len(p.Lines)-1]
+				l.Words = append(l.Words, OCRWord{BBox: title.bbox, Confidence: title.confidence})
+				text.Reset()
+			}
+			if inWord() {
+				kind = hocrWord
+			}
+			stack = append(stack, kind)
+		case xml.CharData:
+			if inWord() {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			if !inWord() && len(res.Pages) > 0 {
+				p := current()
+				if len(p.Lines) > 0 {
+					l := &p.Lines[len(p.Lines)-1]
+					if n := len(l.Words); n > 0 && l.Words[n-1].Text == "" {
+						l.Words[n-1].Text = strings.TrimSpace(text.String())
+					}
+				}
+			}
+		}
+	}
+}
+
+// A hocrKind is the kind of an element of hOCR.
+type hocrKind int
+
+const (
+	hocrOther hocrKind = iota
+	hocrPage
+	hocrLine
+	hocrWord
+)
+
+// inLine reports whether stack holds a line element.
+func inLine(stack []hocrKind) bool {
+	for _, k := range stack {
+		if k == hocrLine {
+			return true
+		}
+	}
+	return false
+}
+
+// hocrTitle holds the properties of an hOCR title attribute.
+type hocrTitle struct {
+	bbox       BBox
+	confidence float64
+}
+
+// parseHOCRTitle parses an hOCR title attribute, such as
+// "bbox 10 20 30 40; x_wconf 93".
+func parseHOCRTitle(s string) hocrTitle {
+	t := hocrTitle{confidence: -1}
+	for _, prop := range strings.Split(s, ";") {
+		f := strings.Fields(prop)
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "bbox":
+			if len(f) == 5 {
+				t.bbox = BBox{atoi(f[1]), atoi(f[2]), atoi(f[3]), atoi(f[4])}
+			}
+		case "x_wconf":
+			if len(f) == 2 {
+				if c, err := strconv.ParseFloat(f[1], 64); err == nil {
+					t.confidence = c / 100
+				}
+			}
+		}
+	}
+	return t
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// ParseALTO parses the ALTO XML in r. Pages are Page elements, lines
+// TextLine elements, and words String elements, whose WC attribute is the
+// confidence.
+func ParseALTO(r io.Reader) (*OCRResult, error) {
+	d := ocrDecoder(r)
+	res := &OCRResult{}
+	current := func() *OCRPage {
+		if len(res.Pages) == 0 {
+			res.Pages = append(res.Pages, OCRPage{Number: 1})
+		}
+		return &res.Pages[len(res.Pages)-1]
+	}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch t.Name.Local {
+		case "Page":
+			n := len(res.Pages) + 1
+			if v, err := strconv.Atoi(attr(t.Attr, "PHYSICAL_IMG_NR")); err == nil && v > 0 {
+				n = v
+			}
+			res.Pages = append(res.Pages, OCRPage{Number: n, BBox: altoBBox(t.Attr)})
+		case "TextLine":
+			p := current()
+			p.Lines = append(p.Lines, OCRLine{BBox: altoBBox(t.Attr)})
+		case "String":
+			p := current()
+			if len(p.Lines) == 0 {
+				p.Lines = append(p.Lines, OCRLine{})
+			}
+			w := OCRWord{Text: attr(t.Attr, "CONTENT"), BBox: altoBBox(t.Attr), Confidence: -1}
+			if c, err := strconv.ParseFloat(attr(t.Attr, "WC"), 64); err == nil {
+				w.Confidence = c
+			}
+			l := &p.Lines[len(p.Lines)-1]
+			l.Words = append(l.Words, w)
+		}
+	}
+}
+
+// altoBBox returns the box of an ALTO element from its HPOS, VPOS, WIDTH,
+// and HEIGHT attributes, which may be fractional.
+func altoBBox(attrs []xml.Attr) BBox {
+	get := func(name string) int {
+		f, _ := strconv.ParseFloat(attr(attrs, name), 64)
+		return int(f + 0.5)
+	}
+	x, y := get("HPOS"), get("VPOS")
+	return BBox{x, y, x + get("WIDTH"), y + get("HEIGHT")}
+}
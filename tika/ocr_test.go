@@ -0,0 +1,132 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testHOCR = `<html xmlns="http://www.w3.org/1999/xhtml"><body>
+<div class="ocr_page" id="page_1" title="image &quot;unknown&quot;; bbox 0 0 640 480; ppageno 0">
+ <div class="ocr_carea" title="bbox 10 10 300 60">
+  <p class="ocr_par">
+   <span class="ocr_line" title="bbox 10 10 300 30; baseline 0 -5">
+    <span class="ocrx_word" title="bbox 10 10 80 30; x_wconf 96">Hello</span>
+    <span class="ocrx_word" title="bbox 90 10 300 30; x_wconf 41"><strong>w0rld</strong></span>
+   </span>
+   <span class="ocr_caption" title="bbox 10 40 100 60">
+    <span class="ocrx_word" title="bbox 10 40 100 60">caption</span>
+   </span>
+  </p>
+ </div>
+</div>
+<div class="ocr_page" title="bbox 0 0 640 480">
+ <span class="ocrx_word" title="bbox 1 2 3 4; x_wconf 88">alone</span>
+</div>
+</body></html>`
+
+var testOCRResult = &OCRResult{Pages: []OCRPage{
+	{Number: 1, BBox: BBox{0, 0, 640, 480}, Lines: []OCRLine{
+		{BBox: BBox{10, 10, 300, 30}, Words: []OCRWord{
+			{Text: "Hello", BBox: BBox{10, 10, 80, 30}, Confidence: 0.96},
+			{Text: "w0rld", BBox: BBox{90, 10, 300, 30}, Confidence: 0.41},
+		}},
+		{BBox: BBox{10, 40, 100, 60}, Words: []OCRWord{
+			{Text: "caption", BBox: BBox{10, 40, 100, 60}, Confidence: -1},
+		}},
+	}},
+	{Number: 2, BBox: BBox{0, 0, 640, 480}, Lines: []OCRLine{
+		{BBox: BBox{1, 2, 3, 4}, Words: []OCRWord{
+			{Text: "alone", BBox: BBox{1, 2, 3, 4}, Confidence: 0.88},
+		}},
+	}},
+}}
+
+func TestParseHOCR(t *testing.T) {
+	got, err := ParseHOCR(strings.NewReader(testHOCR))
+	if err != nil {
+		t.Fatalf("ParseHOCR got error: %v", err)
+	}
+	if !reflect.DeepEqual(got, testOCRResult) {
+		t.Errorf("ParseHOCR got\n%+v\nwant\n%+v", got, testOCRResult)
+	}
+	if want := "Hello w0rld\ncaption\n\nalone\n"; got.Text() != want {
+		t.Errorf("Text() = %q, want %q", got.Text(), want)
+	}
+	low := got.LowConfidence(0.9)
+	if len(low) != 2 || low[0].Text != "w0rld" || low[1].Text != "alone" {
+		t.Errorf("LowConfidence(0.9) = %+v, want w0rld and alone", low)
+	}
+}
+
+func TestParseALTO(t *testing.T) {
+	const alto = `<?xml version="1.0" encoding="UTF-8"?>
+<alto xmlns="http://www.loc.gov/standards/alto/ns-v3#">
+ <Layout>
+  <Page ID="page_0" PHYSICAL_IMG_NR="1" HEIGHT="480" WIDTH="640">
+   <PrintSpace>
+    <TextBlock>
+     <TextLine HPOS="10" VPOS="10" WIDTH="290" HEIGHT="20">
+      <String CONTENT="Hello" HPOS="10" VPOS="10" WIDTH="70" HEIGHT="20" WC="0.96"/>
+      <SP/>
+      <String CONTENT="w0rld" HPOS="90" VPOS="10" WIDTH="210" HEIGHT="20" WC="0.41"/>
+     </TextLine>
+     <TextLine HPOS="10" VPOS="40" WIDTH="90" HEIGHT="20">
+      <String CONTENT="caption" HPOS="10" VPOS="40" WIDTH="90" HEIGHT="20"/>
+     </TextLine>
+    </TextBlock>
+   </PrintSpace>
+  </Page>
+  <Page HEIGHT="480" WIDTH="640">
+   <TextLine HPOS="1" VPOS="2" WIDTH="2" HEIGHT="2">
+    <String CONTENT="alone" HPOS="1" VPOS="2" WIDTH="2" HEIGHT="2" WC="0.88"/>
+   </TextLine>
+  </Page>
+ </Layout>
+</alto>`
+	got, err := ParseALTO(strings.NewReader(alto))
+	if err != nil {
+		t.Fatalf("ParseALTO got error: %v", err)
+	}
+	if !reflect.DeepEqual(got, testOCRResult) {
+		t.Errorf("ParseALTO got\n%+v\nwant\n%+v", got, testOCRResult)
+	}
+}
+
+func TestOCR(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tika" || r.Header.Get(OCROutputTypeHeader) != "hocr" || r.Header.Get("Accept") != "text/html" || r.Header.Get("X-Tika-OCRLanguage") != "eng" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(testHOCR))
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	got, err := c.OCR(context.Background(), strings.NewReader("image"), OCRHOCR, http.Header{"X-Tika-OCRLanguage": {"eng"}})
+	if err != nil {
+		t.Fatalf("OCR got error: %v", err)
+	}
+	if !reflect.DeepEqual(got, testOCRResult) {
+		t.Errorf("OCR got\n%+v\nwant\n%+v", got, testOCRResult)
+	}
+}
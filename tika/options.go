@@ -0,0 +1,98 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// An Option configures a Client. Options are passed to NewClient.
+type Option func(*Client)
+
+// ErrResponseTooLarge is returned when a response from the Tika Server is
+// larger than the limit set with WithMaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// WithMaxResponseBytes limits the size of every response body to n bytes.
+// Reading beyond the limit fails with ErrResponseTooLarge. This protects
+// against documents that expand to gigabytes of text, such as decompressed
+// XML bombs.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// WithPathPrefix prepends prefix to the path of every request, for servers
+// reached through a gateway that exposes Tika under a path such as
+// /tika-server. Equivalently, the prefix can be included in the URL passed to
+// NewClient.
+func WithPathPrefix(prefix string) Option {
+	return func(c *Client) {
+		prefix = strings.Trim(prefix, "/")
+		if prefix != "" {
+			prefix = "/" + prefix
+		}
+		c.pathPrefix = prefix
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request to ua, for
+// example "indexer/1.4", so the requests of an application can be told apart
+// in the access logs of a shared server.
+func WithUserAgent(ua string) Option {
+	return WithHeader("User-Agent", ua)
+}
+
+// WithHeader adds a header sent with every request, such as an application
+// or tenant identifier used for per-client accounting. A header passed to a
+// method, such as ParseWithHeader, takes precedence over one set with
+// WithHeader.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, value)
+	}
+}
+
+// limitedBody is a response body that returns err, such as
+// ErrResponseTooLarge, once more than n bytes have been read.
+type limitedBody struct {
+	io.ReadCloser
+	n   int64
+	err error
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	// Read one byte more than allowed to detect bodies over the limit.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	if int64(n) > l.n {
+		n = int(l.n)
+		l.n = 0
+		return n, l.err
+	}
+	l.n -= int64(n)
+	return n, err
+}
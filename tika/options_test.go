@@ -0,0 +1,128 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		chunked  bool
+		max      int64
+		want     string
+		tooLarge bool
+	}{
+		{name: "under limit", body: "12345", max: 10, want: "12345"},
+		{name: "at limit", body: "12345", max: 5, want: "12345"},
+		{name: "content length over limit", body: "123456", max: 5, tooLarge: true},
+		{name: "chunked under limit", body: "12345", chunked: true, max: 5, want: "12345"},
+		{name: "chunked over limit", body: strings.Repeat("1", 100000), chunked: true, max: 5, tooLarge: true},
+	}
+	for _, test := range tests {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, test.body)
+			if test.chunked {
+				w.(http.Flusher).Flush()
+			}
+		}))
+		defer ts.Close()
+		c := NewClient(nil, ts.URL, WithMaxResponseBytes(test.max))
+		got, err := c.Parse(context.Background(), nil)
+		if test.tooLarge {
+			if err != ErrResponseTooLarge {
+				t.Errorf("Parse(%s) got error %v, want %v", test.name, err, ErrResponseTooLarge)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%s) got error: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Parse(%s) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWithPathPrefix(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer ts.Close()
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"", "/version"},
+		{"tika-server", "/tika-server/version"},
+		{"/tika-server/", "/tika-server/version"},
+		{"/a/b", "/a/b/version"},
+	}
+	for _, test := range tests {
+		c := NewClient(nil, ts.URL, WithPathPrefix(test.prefix))
+		got, err := c.Version(context.Background())
+		if err != nil {
+			t.Errorf("WithPathPrefix(%q) Version got error: %v", test.prefix, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("WithPathPrefix(%q) requested %q, want %q", test.prefix, got, test.want)
+		}
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL, WithUserAgent("indexer/1.4"), WithHeader("X-Tenant", "acme"))
+	if _, err := c.ParseWithHeader(context.Background(), strings.NewReader("x"), http.Header{"X-Tenant": {"other"}}); err != nil {
+		t.Fatalf("ParseWithHeader got error: %v", err)
+	}
+	if ua := got.Get("User-Agent"); ua != "indexer/1.4" {
+		t.Errorf("User-Agent = %q, want %q", ua, "indexer/1.4")
+	}
+	if tenant := got.Get("X-Tenant"); tenant != "other" {
+		t.Errorf("X-Tenant = %q, want the per-request value %q", tenant, "other")
+	}
+	if _, err := c.Version(context.Background()); err != nil {
+		t.Fatalf("Version got error: %v", err)
+	}
+	if tenant := got.Get("X-Tenant"); tenant != "acme" {
+		t.Errorf("X-Tenant = %q, want %q", tenant, "acme")
+	}
+}
+
+func TestNewClientInvalidURL(t *testing.T) {
+	for _, u := range []string{"", "localhost:9998", "/tika", "http://[::1"} {
+		c := NewClient(nil, u)
+		_, err := c.Version(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "invalid server URL") {
+			t.Errorf("NewClient(%q) Version got error %v, want an invalid server URL error", u, err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// An Action is what a Pipeline does with a document.
+type Action int
+
+// Actions a Pipeline can take.
+const (
+	// ActionParse parses the document with Parse.
+	ActionParse Action = iota
+	// ActionRecursive parses the document and all embedded documents with
+	// MetaRecursive.
+	ActionRecursive
+	// ActionSkip does not send the document to Tika.
+	ActionSkip
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionParse:
+		return "parse"
+	case ActionRecursive:
+		return "recursive"
+	case ActionSkip:
+		return "skip"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// A Rule configures how a Pipeline handles documents of a MIME type.
+type Rule struct {
+	// Action is the Action taken for matching documents.
+	Action Action
+	// Header is sent with the request made by Action, for example to
+	// configure OCR for images.
+	Header http.Header
+	// Handler, if not nil, handles matching documents instead of Action. It
+	// is passed the Pipeline's Client, the detected MIME type and the
+	// document.
+	Handler func(ctx context.Context, c *Client, mimeType string, input io.Reader) (*PipelineResult, error)
+	// Processors are applied to the text of matching documents after
+	// Pipeline.Processors.
+	Processors []TextProcessor
+	// Timeout, if greater than 0, is the maximum time to handle matching
+	// documents, overriding Pipeline.Timeouts.
+	Timeout time.Duration
+}
+
+// A PipelineResult is the outcome of processing a document with a Pipeline.
+type PipelineResult struct {
+	// MIMEType is the detected MIME type of the document, without
+	// parameters.
+	MIMEType string
+	// Action is the Action that was taken.
+	Action Action
+	// Content is the parsed body of the document, for ActionParse.
+	Content string
+	// Documents is the metadata of the document and all embedded
+	// documents, for ActionRecursive.
+	Documents []Metadata
+	// Warnings are the exceptions Tika recorded in Documents. A result with
+	// warnings may be missing the text of some embedded documents.
+	Warnings []ParseWarning
+	// Denied reports that the Pipeline's Policy denied the document, which
+	// was skipped.
+	Denied bool
+}
+
+// A Pipeline detects the MIME type of each document and then handles it
+// according to the Rule for that type. For example:
+//
+//	p := &tika.Pipeline{
+//	    Client: client,
+//	    Rules: map[string]tika.Rule{
+//	        "image/*":         {Header: ocrHeader},
+//	        "application/zip": {Action: tika.ActionRecursive},
+//	        "video/*":         {Action: tika.ActionSkip},
+//	    },
+//	}
+//	res, err := p.Process(context.Background(), f)
+type Pipeline struct {
+	// Client is used for every request.
+	Client *Client
+	// Rules maps MIME types to Rules. Keys are either full MIME types, such
+	// as "application/pdf", or a type with a wildcard subtype, such as
+	// "image/*". Full MIME types take precedence.
+	Rules map[string]Rule
+	// Default is the Rule for documents that match no other Rule.
+	Default Rule
+	// Processors are applied, in order, to the Content of every result and
+	// to the XTIKAContent field of its Documents, including results of
+	// Rule.Handler, after any TextProcessors of the Client.
+	Processors []TextProcessor
+	// Timeouts limits the time to handle documents by their detected MIME
+	// type, for example to DefaultTimeouts. Documents exceeding it fail with
+	// an error matching context.DeadlineExceeded. If Timeouts is nil, the
+	// table of the Client set with WithMIMETimeouts is used, if any.
+	Timeouts TimeoutTable
+	// Policy, if not nil, restricts the MIME types of the documents that
+	// are handled. Denied documents are never sent for parsing, whatever
+	// their Rule.
+	Policy *TypePolicy
+}
+
+// rule returns the Rule for mimeType.
+func (p *Pipeline) rule(mimeType string) Rule {
+	if r, ok := p.Rules[mimeType]; ok {
+		return r
+	}
+	if i := strings.Index(mimeType, "/"); i >= 0 {
+		if r, ok := p.Rules[mimeType[:i]+"/*"]; ok {
+			return r
+		}
+	}
+	return p.Default
+}
+
+// timeout returns the maximum time to handle documents of mimeType matching
+// r, or 0 if there is none.
+func (p *Pipeline) timeout(r Rule, mimeType string) time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	t := p.Timeouts
+	if t == nil {
+		t = p.Client.timeouts
+	}
+	return t.Timeout(mimeType)
+}
+
+// Process detects the MIME type of input, rewinds it, and handles it as
+// configured by the matching Rule. If the Client was created with WithStats,
+// the time taken is recorded under the detected MIME type.
+func (p *Pipeline) Process(ctx context.Context, input io.ReadSeeker) (*PipelineResult, error) {
+	start := time.Now()
+	var mimeType string
+	var err error
+	if p.Policy != nil {
+		mimeType, err = p.Client.DetectPrefix(ctx, input, p.Policy.DetectPrefix)
+	} else {
+		mimeType, err = p.Client.Detect(ctx, input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error detecting MIME type: %w", err)
+	}
+	mimeType = baseMIMEType(mimeType)
+	if p.Policy != nil {
+		if pattern, denied := p.Policy.denied(mimeType); denied {
+			if p.Policy.Audit != nil {
+				p.Policy.Audit(PolicyAudit{Time: time.Now(), MIMEType: mimeType, Pattern: pattern, Rejected: p.Policy.Reject})
+			}
+			if p.Policy.Reject {
+				return nil, fmt.Errorf("%s: %w", mimeType, ErrTypeDenied)
+			}
+			return &PipelineResult{MIMEType: mimeType, Action: ActionSkip, Denied: true}, nil
+		}
+	}
+	if _, err := input.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	r := p.rule(mimeType)
+	if d := p.timeout(r, mimeType); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	res, err := p.handle(ctx, r, mimeType, input)
+	if err == nil && res != nil {
+		ps := append(p.Processors[:len(p.Processors):len(p.Processors)], r.Processors...)
+		if err := res.process(ctx, ps); err != nil {
+			return nil, err
+		}
+	}
+	if err == nil && p.Client.stats != nil {
+		p.Client.stats.ObserveMIME(mimeType, time.Since(start))
+	}
+	return res, err
+}
+
+// handle handles input according to r.
+func (p *Pipeline) handle(ctx context.Context, r Rule, mimeType string, input io.Reader) (*PipelineResult, error) {
+	if r.Handler != nil {
+		return r.Handler(ctx, p.Client, mimeType, input)
+	}
+	res := &PipelineResult{MIMEType: mimeType, Action: r.Action}
+	switch r.Action {
+	case ActionParse:
+		content, err := p.Client.ParseWithHeader(ctx, input, r.Header)
+		if err != nil {
+			return nil, err
+		}
+		res.Content = content
+	case ActionRecursive:
+		err := p.Client.metaRecursiveStream(ctx, input, "text", r.Header, func(m Metadata) error {
+			res.Documents = append(res.Documents, m)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		res.Warnings = DocumentWarnings(res.Documents)
+	case ActionSkip:
+	default:
+		return nil, fmt.Errorf("unknown action %v", r.Action)
+	}
+	return res, nil
+}
+
+// process applies ps to the text of res.
+func (res *PipelineResult) process(ctx context.Context, ps []TextProcessor) error {
+	if len(ps) == 0 {
+		return nil
+	}
+	var err error
+	if res.Content != "" {
+		if res.Content, err = ProcessText(ctx, res.Content, ps...); err != nil {
+			return err
+		}
+	}
+	for _, d := range res.Documents {
+		for i, v := range d[XTIKAContent] {
+			if d[XTIKAContent][i], err = ProcessText(ctx, v, ps...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// baseMIMEType returns mimeType without parameters such as the charset.
+func baseMIMEType(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.TrimSpace(mimeType)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pipelineServer detects the MIME type of a request as its body, and echoes
+// the body and the X-Test header for parse requests.
+func pipelineServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/detect/stream":
+			fmt.Fprintf(w, "%s; charset=UTF-8", b)
+		case "/tika":
+			fmt.Fprintf(w, "%s%s", r.Header.Get("X-Test"), b)
+		case "/rmeta/text":
+			fmt.Fprintf(w, `[{"X-TIKA:content":%q}]`, b)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestPipeline(t *testing.T) {
+	ts := pipelineServer()
+	defer ts.Close()
+	p := &Pipeline{
+		Client: NewClient(nil, ts.URL),
+		Rules: map[string]Rule{
+			"image/*":         {Header: http.Header{"X-Test": {"ocr:"}}},
+			"image/gif":       {Action: ActionSkip},
+			"application/zip": {Action: ActionRecursive},
+		},
+		Default: Rule{
+			Handler: func(_ context.Context, _ *Client, mimeType string, input io.Reader) (*PipelineResult, error) {
+				b, err := ioutil.ReadAll(input)
+				return &PipelineResult{MIMEType: mimeType, Content: "custom:" + string(b)}, err
+			},
+		},
+	}
+	tests := []struct {
+		input string
+		want  *PipelineResult
+	}{
+		{"image/png", &PipelineResult{MIMEType: "image/png", Action: ActionParse, Content: "ocr:image/png"}},
+		{"image/gif", &PipelineResult{MIMEType: "image/gif", Action: ActionSkip}},
+		{"application/zip", &PipelineResult{
+			MIMEType:  "application/zip",
+			Action:    ActionRecursive,
+			Documents: []Metadata{{XTIKAContent: {"application/zip"}}},
+		}},
+		{"text/plain", &PipelineResult{MIMEType: "text/plain", Content: "custom:text/plain"}},
+	}
+	for _, test := range tests {
+		got, err := p.Process(context.Background(), strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("Process(%q) got error: %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Process(%q) = %+v, want %+v", test.input, got, test.want)
+		}
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+	p := &Pipeline{Client: errorClient}
+	if _, err := p.Process(context.Background(), strings.NewReader("")); err == nil {
+		t.Error("Process got no error, want an error")
+	}
+}
+
+func TestPipelineProcessors(t *testing.T) {
+	ts := pipelineServer()
+	defer ts.Close()
+	p := &Pipeline{
+		Client: NewClient(nil, ts.URL),
+		Rules: map[string]Rule{
+			"application/zip": {Action: ActionRecursive, Processors: []TextProcessor{Truncate(5)}},
+		},
+		Processors: []TextProcessor{upper},
+	}
+	tests := []struct {
+		input string
+		want  *PipelineResult
+	}{
+		{"text/plain", &PipelineResult{MIMEType: "text/plain", Content: "TEXT/PLAIN"}},
+		{"application/zip", &PipelineResult{
+			MIMEType:  "application/zip",
+			Action:    ActionRecursive,
+			Documents: []Metadata{{XTIKAContent: {"APPLI"}}},
+		}},
+	}
+	for _, test := range tests {
+		got, err := p.Process(context.Background(), strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("Process(%q) got error: %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Process(%q) got %+v, want %+v", test.input, got, test.want)
+		}
+	}
+}
+
+func TestPipelinePolicy(t *testing.T) {
+	// The server detects the MIME type of a request as its first line.
+	var parsed, detectBytes int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/detect/stream":
+			detectBytes = len(b)
+			fmt.Fprint(w, strings.SplitN(string(b), "\n", 2)[0])
+		case "/tika":
+			parsed++
+			w.Write(b)
+		}
+	}))
+	defer ts.Close()
+
+	var audits []PolicyAudit
+	policy := &TypePolicy{
+		Allow: []string{"text/*", "application/*"},
+		Deny:  []string{"application/x-msdownload", "application/x-sh"},
+		Audit: func(a PolicyAudit) { audits = append(audits, a) },
+	}
+	p := &Pipeline{Client: NewClient(nil, ts.URL), Policy: policy}
+	tests := []struct {
+		input   string
+		denied  bool
+		pattern string
+	}{
+		{input: "text/plain\nhello"},
+		{input: "application/x-msdownload\nMZ", denied: true, pattern: "application/x-msdownload"},
+		{input: "video/mp4\n....", denied: true},
+		{input: "application/pdf\n%PDF"},
+	}
+	for _, test := range tests {
+		parsed, audits = 0, nil
+		got, err := p.Process(context.Background(), strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("Process(%q) got error: %v", test.input, err)
+			continue
+		}
+		if got.Denied != test.denied || (parsed == 1) == test.denied {
+			t.Errorf("Process(%q) got Denied %v after %d parses, want Denied %v", test.input, got.Denied, parsed, test.denied)
+		}
+		if !test.denied {
+			continue
+		}
+		if got.Action != ActionSkip {
+			t.Errorf("Process(%q) got Action %v for a denied document, want %v", test.input, got.Action, ActionSkip)
+		}
+		if len(audits) != 1 || audits[0].MIMEType != got.MIMEType || audits[0].Pattern != test.pattern || audits[0].Rejected || audits[0].Time.IsZero() {
+			t.Errorf("Process(%q) audited %+v, want one record of %s matching %q", test.input, audits, got.MIMEType, test.pattern)
+		}
+	}
+
+	parsed = 0
+	large := "application/x-msdownload\n" + strings.Repeat("MZ", DefaultDetectPrefix)
+	if _, err := p.Process(context.Background(), strings.NewReader(large)); err != nil {
+		t.Errorf("Process of a large denied document got error: %v", err)
+	}
+	if detectBytes != DefaultDetectPrefix || parsed != 0 {
+		t.Errorf("Process of a large denied document sent %d bytes for detection and parsed %d times, want %d and 0", detectBytes, parsed, DefaultDetectPrefix)
+	}
+
+	policy.Reject = true
+	policy.DetectPrefix = 16
+	audits = nil
+	input := "application/x-sh\n" + strings.Repeat("#", 100)
+	if _, err := p.Process(context.Background(), strings.NewReader(input)); !errors.Is(err, ErrTypeDenied) {
+		t.Errorf("Process of a denied document with Reject got error %v, want %v", err, ErrTypeDenied)
+	}
+	if detectBytes != 16 {
+		t.Errorf("Process with DetectPrefix 16 sent %d bytes for detection, want 16", detectBytes)
+	}
+	if len(audits) != 1 || !audits[0].Rejected {
+		t.Errorf("Process with Reject audited %+v, want one rejected record", audits)
+	}
+}
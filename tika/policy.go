@@ -0,0 +1,73 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTypeDenied is returned, wrapped, by Pipeline.Process for documents
+// whose MIME type is denied by a TypePolicy with Reject set.
+var ErrTypeDenied = errors.New("MIME type denied by policy")
+
+// A TypePolicy restricts the MIME types a Pipeline sends to Tika for
+// parsing, for example to never send executables or media files, reducing
+// the attack surface of the server and wasted work. Patterns are MIME types,
+// types with a wildcard subtype, such as "video/*", or "*/*".
+type TypePolicy struct {
+	// Allow, if not empty, lists the only types that are processed.
+	Allow []string
+	// Deny lists types that are never processed, even if allowed.
+	Deny []string
+	// Reject, if true, makes Process fail with an error wrapping
+	// ErrTypeDenied for denied documents. Otherwise they are skipped, and
+	// their PipelineResult has Denied set.
+	Reject bool
+	// DetectPrefix is the number of bytes sent to detect the MIME type, as
+	// with Client.DetectPrefix, so that denied documents are never uploaded
+	// in full. If 0, DefaultDetectPrefix bytes are sent.
+	DetectPrefix int64
+	// Audit, if not nil, is called with a record of every denied document.
+	// It may be called concurrently by concurrent calls to Process.
+	Audit func(PolicyAudit)
+}
+
+// A PolicyAudit records a document denied by a TypePolicy.
+type PolicyAudit struct {
+	// Time is when the document was denied.
+	Time time.Time
+	// MIMEType is the detected MIME type of the document.
+	MIMEType string
+	// Pattern is the Deny pattern the type matched, or "" if the type
+	// matched no Allow pattern.
+	Pattern string
+	// Rejected reports whether Process returned an error, rather than
+	// skipping the document.
+	Rejected bool
+}
+
+// denied reports whether p denies mimeType, and the matching Deny pattern.
+func (p *TypePolicy) denied(mimeType string) (string, bool) {
+	if m := matchMIMEType(p.Deny, mimeType); m != "" {
+		return m, true
+	}
+	if len(p.Allow) > 0 && matchMIMEType(p.Allow, mimeType) == "" {
+		return "", true
+	}
+	return "", false
+}
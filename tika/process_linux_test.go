@@ -0,0 +1,88 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// exited reports whether the process with the given ID has exited, counting
+// zombies as exited.
+func exited(pid int) bool {
+	b, err := ioutil.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	// The state follows the command name, which is in parentheses.
+	fields := strings.Fields(string(b[strings.LastIndexByte(string(b), ')')+1:]))
+	return len(fields) == 0 || fields[0] == "Z"
+}
+
+func TestNewProcessGroup(t *testing.T) {
+	attr := &syscall.SysProcAttr{Setsid: true}
+	cmd := exec.Command("true")
+	cmd.SysProcAttr = attr
+	newProcessGroup(cmd)
+	if got := cmd.SysProcAttr; got.Setpgid || !got.Setsid || got.Pdeathsig != syscall.SIGKILL {
+		t.Errorf("newProcessGroup with Setsid got %+v, want Setsid and Pdeathsig only", got)
+	}
+	if attr.Pdeathsig != 0 {
+		t.Errorf("newProcessGroup modified the SysProcAttr of the command")
+	}
+
+	cmd = exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "fork", "sleep", "10")
+	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+	newProcessGroup(cmd)
+	if !cmd.SysProcAttr.Setpgid {
+		t.Errorf("newProcessGroup did not set Setpgid")
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		cmd.Process.Kill()
+		t.Fatalf("reading grandchild PID got error: %v", err)
+	}
+	grandchild, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		t.Fatalf("invalid grandchild PID %q", line)
+	}
+	if err := killProcessGroup(cmd.Process); err != nil {
+		t.Errorf("killProcessGroup got error: %v", err)
+	}
+	cmd.Wait()
+	for deadline := time.Now().Add(5 * time.Second); !exited(grandchild); {
+		if time.Now().After(deadline) {
+			syscall.Kill(grandchild, syscall.SIGKILL)
+			t.Fatalf("grandchild %d still running after killProcessGroup", grandchild)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
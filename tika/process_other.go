@@ -0,0 +1,64 @@
+//go:build !unix && !windows
+
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+const javaExecutable = "java"
+
+// killProcess kills p.
+func killProcess(p *os.Process) error {
+	return p.Kill()
+}
+
+// interruptProcess asks p to exit.
+func interruptProcess(p *os.Process) error {
+	return p.Signal(os.Interrupt)
+}
+
+// newProcessGroup does nothing: process groups are only supported on Unix
+// and Windows.
+func newProcessGroup(*exec.Cmd) {}
+
+// killProcessGroup kills p alone.
+func killProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// interruptProcessGroup asks p alone to exit.
+func interruptProcessGroup(p *os.Process) error {
+	return p.Signal(os.Interrupt)
+}
+
+// processAlive reports whether a process with the given ID can be found,
+// which does not tell whether it is running on every platform.
+func processAlive(pid int) bool {
+	_, err := os.FindProcess(pid)
+	return err == nil
+}
+
+// chownForProcess does nothing, since SysProcAttr cannot set the user of a
+// process.
+func chownForProcess(string, *syscall.SysProcAttr) error {
+	return nil
+}
@@ -0,0 +1,93 @@
+//go:build unix
+
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+const javaExecutable = "java"
+
+// killProcess kills p.
+func killProcess(p *os.Process) error {
+	return p.Kill()
+}
+
+// interruptProcess asks p to exit.
+func interruptProcess(p *os.Process) error {
+	return p.Signal(os.Interrupt)
+}
+
+// newProcessGroup makes cmd start in a new process group, so that
+// killProcessGroup also reaches the processes it forks, and, on Linux, be
+// killed if the current process dies. The SysProcAttr of cmd is copied, not
+// modified.
+func newProcessGroup(cmd *exec.Cmd) {
+	attr := &syscall.SysProcAttr{}
+	if cmd.SysProcAttr != nil {
+		a := *cmd.SysProcAttr
+		attr = &a
+	}
+	// A new session is also a new process group, and Setpgid fails for a
+	// session leader.
+	if !attr.Setsid && !attr.Setpgid {
+		attr.Setpgid = true
+	}
+	setDeathSignal(attr)
+	cmd.SysProcAttr = attr
+}
+
+// killProcessGroup kills the process group led by p, falling back to p alone
+// if p is not a group leader.
+func killProcessGroup(p *os.Process) error {
+	if err := syscall.Kill(-p.Pid, syscall.SIGKILL); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
+
+// interruptProcessGroup asks the process group led by p to exit, falling
+// back to p alone if p is not a group leader.
+func interruptProcessGroup(p *os.Process) error {
+	if err := syscall.Kill(-p.Pid, syscall.SIGINT); err != nil {
+		return p.Signal(os.Interrupt)
+	}
+	return nil
+}
+
+// processAlive reports whether the process with the given ID is running. A
+// process which exited but was not waited for yet is not running.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil && !processZombie(pid)
+}
+
+// chownForProcess makes dir owned by the user and group of attr.Credential,
+// if set, so that a process started with attr can write to it.
+func chownForProcess(dir string, attr *syscall.SysProcAttr) error {
+	if attr == nil || attr.Credential == nil {
+		return nil
+	}
+	return os.Chown(dir, int(attr.Credential.Uid), int(attr.Credential.Gid))
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+const javaExecutable = "java.exe"
+
+// killProcess kills p and all of its children. Process.Kill only terminates
+// p itself, which leaves the child JVM started by ChildMode running.
+func killProcess(p *os.Process) error {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).Run(); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
+
+// interruptProcess asks p and all of its children to exit. Windows does not
+// support sending os.Interrupt to another process, and taskkill without /F
+// fails for console processes such as java.exe, which have no window to
+// close; Server.Shutdown then kills them.
+func interruptProcess(p *os.Process) error {
+	return exec.Command("taskkill", "/T", "/PID", strconv.Itoa(p.Pid)).Run()
+}
+
+// newProcessGroup does nothing on Windows: killProcess and interruptProcess
+// already reach the whole process tree.
+func newProcessGroup(*exec.Cmd) {}
+
+// killProcessGroup kills p and all of its children.
+func killProcessGroup(p *os.Process) error {
+	return killProcess(p)
+}
+
+// interruptProcessGroup asks p and all of its children to exit.
+func interruptProcessGroup(p *os.Process) error {
+	return interruptProcess(p)
+}
+
+// processAlive reports whether the process with the given ID is running.
+// On Windows, FindProcess fails if there is no such process.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// chownForProcess does nothing on Windows, where SysProcAttr cannot set the
+// user of a process.
+func chownForProcess(string, *syscall.SysProcAttr) error {
+	return nil
+}
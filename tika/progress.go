@@ -0,0 +1,72 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import "io"
+
+// Progress describes how much of a transfer has completed.
+type Progress struct {
+	// Bytes is the number of bytes transferred so far.
+	Bytes int64
+	// Total is the total number of bytes to transfer, or -1 if unknown.
+	Total int64
+}
+
+// Percent returns the percentage of the transfer that has completed. The
+// boolean is false if the total size of the transfer is unknown.
+func (p Progress) Percent() (float64, bool) {
+	if p.Total < 0 {
+		return 0, false
+	}
+	if p.Total == 0 {
+		return 100, true
+	}
+	return float64(p.Bytes) / float64(p.Total) * 100, true
+}
+
+// ProgressReader returns a reader that reads from r and calls fn with the
+// Progress after every read. total is the expected size of r, or -1 if it is
+// unknown. Wrap the input of any Client method to report upload progress:
+//
+//	f, err := os.Open("large.pdf")
+//	...
+//	fi, err := f.Stat()
+//	...
+//	r := tika.ProgressReader(f, fi.Size(), func(p tika.Progress) {
+//	    if pct, ok := p.Percent(); ok {
+//	        fmt.Printf("\r%.0f%%", pct)
+//	    }
+//	})
+//	body, err := client.Parse(context.Background(), r)
+func ProgressReader(r io.Reader, total int64, fn func(Progress)) io.Reader {
+	return &progressReader{r: r, fn: fn, p: Progress{Total: total}}
+}
+
+type progressReader struct {
+	r  io.Reader
+	fn func(Progress)
+	p  Progress
+}
+
+func (pr *progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	if n > 0 {
+		pr.p.Bytes += int64(n)
+		pr.fn(pr.p)
+	}
+	return n, err
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProgressPercent(t *testing.T) {
+	tests := []struct {
+		p      Progress
+		want   float64
+		wantOK bool
+	}{
+		{Progress{Bytes: 5, Total: 10}, 50, true},
+		{Progress{Bytes: 0, Total: 0}, 100, true},
+		{Progress{Bytes: 5, Total: -1}, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := test.p.Percent()
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("%+v.Percent() = %v, %v, want %v, %v", test.p, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	ts := echoServer()
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+
+	input := "test value"
+	var last Progress
+	r := ProgressReader(strings.NewReader(input), int64(len(input)), func(p Progress) {
+		last = p
+	})
+	if _, err := c.Parse(context.Background(), r); err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	want := Progress{Bytes: int64(len(input)), Total: int64(len(input))}
+	if last != want {
+		t.Errorf("ProgressReader last reported %+v, want %+v", last, want)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNoProxyMatch(t *testing.T) {
+	l := parseNoProxy("localhost, .internal.example, corp.example:8080, 10.0.0.0/8, 192.168.1.5, [::1]:9998")
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://localhost:9998", true},
+		{"http://tika.internal.example", true},
+		{"http://internal.example", true},
+		{"http://notinternal.example", false},
+		{"http://corp.example:8080", true},
+		{"http://corp.example:9998", false},
+		{"http://10.1.2.3:9998", true},
+		{"http://192.168.1.5", true},
+		{"http://192.168.1.6", false},
+		{"http://[::1]:9998", true},
+		{"http://[::1]:80", false},
+		{"https://tika.example", false},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := l.match(u); got != test.want {
+			t.Errorf("match(%s) = %v, want %v", test.url, got, test.want)
+		}
+	}
+	if u, _ := url.Parse("http://anything"); !parseNoProxy("*").match(u) {
+		t.Error(`parseNoProxy("*") does not match everything`)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "proxied %s", r.URL)
+	}))
+	defer proxy.Close()
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "direct")
+	}))
+	defer direct.Close()
+
+	hc := &http.Client{}
+	c := NewClient(hc, "http://tika.example:9998", WithProxy(ProxyConfig{URL: proxy.URL}))
+	got, err := c.Version(context.Background())
+	if err != nil {
+		t.Fatalf("Version through the proxy got error: %v", err)
+	}
+	if want := "proxied http://tika.example:9998/version"; got != want {
+		t.Errorf("Version through the proxy = %q, want %q", got, want)
+	}
+	if hc.Transport != nil {
+		t.Error("WithProxy modified the http.Client passed to NewClient")
+	}
+
+	c = NewClient(hc, direct.URL, WithProxy(ProxyConfig{URL: proxy.URL, NoProxy: "127.0.0.1"}))
+	if got, err := c.Version(context.Background()); err != nil || got != "direct" {
+		t.Errorf("Version with NoProxy = %q, %v, want %q", got, err, "direct")
+	}
+
+	for _, bad := range []string{"ftp://proxy", "socks5://", "://"} {
+		c := NewClient(nil, direct.URL, WithProxy(ProxyConfig{URL: bad}))
+		if _, err := c.Version(context.Background()); err == nil || !strings.Contains(err.Error(), "proxy URL") {
+			t.Errorf("WithProxy(%q) Version got error %v, want an invalid proxy URL error", bad, err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// A Quarantine records poison Inputs: inputs that crash the server or
+// repeatedly make it time out. A Batch with a Quarantine skips quarantined
+// inputs, so a retried job does not take the server down again. See
+// Batch.Quarantine.
+//
+// A Quarantine is stored in a file with one JSON object per failure, so
+// failures are counted across runs. It is safe for concurrent use.
+type Quarantine struct {
+	// Dir, if not empty, is a directory receiving a copy of every
+	// quarantined input for offline analysis.
+	Dir string
+	// MaxTimeouts is the number of timeouts after which an input is
+	// quarantined. If less than or equal to 0, 2 is used.
+	MaxTimeouts int
+	// MaxCrashes is the number of crashes after which an input is
+	// quarantined. If less than or equal to 0, 2 is used, so that an input
+	// parsed while the server failed for another reason is retried.
+	MaxCrashes int
+
+	mu       sync.Mutex
+	f        *os.File
+	timeouts map[string]int
+	crashes  map[string]int
+}
+
+// quarantineEntry is a line of a Quarantine file.
+type quarantineEntry struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Reasons recorded in a Quarantine file.
+const (
+	quarantineCrash   = "crash"
+	quarantineTimeout = "timeout"
+)
+
+// OpenQuarantine opens the Quarantine stored at path, creating it if it does
+// not exist.
+func OpenQuarantine(path string) (*Quarantine, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	q := &Quarantine{f: f, timeouts: map[string]int{}, crashes: map[string]int{}}
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		var e quarantineEntry
+		if json.Unmarshal(s.Bytes(), &e) != nil {
+			continue
+		}
+		if e.Reason == quarantineCrash {
+			q.crashes[e.Name]++
+		} else {
+			q.timeouts[e.Name]++
+		}
+	}
+	if err := s.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *Quarantine) maxTimeouts() int {
+	if q.MaxTimeouts <= 0 {
+		return 2
+	}
+	return q.MaxTimeouts
+}
+
+func (q *Quarantine) maxCrashes() int {
+	if q.MaxCrashes <= 0 {
+		return 2
+	}
+	return q.MaxCrashes
+}
+
+// quarantined reports whether the Input name is quarantined. q.mu must be
+// held.
+func (q *Quarantine) quarantined(name string) bool {
+	return q.crashes[name] >= q.maxCrashes() || q.timeouts[name] >= q.maxTimeouts()
+}
+
+// Quarantined reports whether the Input name is quarantined.
+func (q *Quarantine) Quarantined(name string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.quarantined(name)
+}
+
+// Names returns the names of the quarantined Inputs.
+func (q *Quarantine) Names() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var names []string
+	seen := map[string]bool{}
+	for _, m := range []map[string]int{q.crashes, q.timeouts} {
+		for name := range m {
+			if !seen[name] && q.quarantined(name) {
+				names = append(names, name)
+				seen[name] = true
+			}
+		}
+	}
+	return names
+}
+
+// Crash records that input crashed the server, quarantining it once it has
+// crashed the server MaxCrashes times.
+func (q *Quarantine) Crash(input Input) error {
+	return q.record(input, quarantineCrash)
+}
+
+// Timeout records that input made the server time out, quarantining it
+// once it has timed out MaxTimeouts times.
+func (q *Quarantine) Timeout(input Input) error {
+	return q.record(input, quarantineTimeout)
+}
+
+func (q *Quarantine) record(input Input, reason string) error {
+	b, err := json.Marshal(quarantineEntry{Name: input.Name, Reason: reason})
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	was := q.quarantined(input.Name)
+	if _, err := q.f.Write(append(b, '\n')); err != nil {
+		q.mu.Unlock()
+		return err
+	}
+	if reason == quarantineCrash {
+		q.crashes[input.Name]++
+	} else {
+		q.timeouts[input.Name]++
+	}
+	now := q.quarantined(input.Name)
+	q.mu.Unlock()
+	if now && !was && q.Dir != "" {
+		return q.copy(input)
+	}
+	return nil
+}
+
+// copy copies the content of input to Dir.
+func (q *Quarantine) copy(input Input) error {
+	if err := os.MkdirAll(q.Dir, 0755); err != nil {
+		return err
+	}
+	r, err := input.Open()
+	if err != nil {
+		return fmt.Errorf("error copying %s to quarantine: %v", input.Name, err)
+	}
+	defer r.Close()
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(input.Name)
+	f, err := os.Create(filepath.Join(q.Dir, name))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("error copying %s to quarantine: %v", input.Name, err)
+	}
+	return f.Close()
+}
+
+// Close closes the Quarantine file.
+func (q *Quarantine) Close() error {
+	return q.f.Close()
+}
+
+// isCrash reports whether err means the server failed while parsing an
+// input: the request reached the server, but the connection was closed or
+// reset before the end of the response. Errors connecting to the server,
+// such as while it restarts, and error responses such as 503 are not
+// crashes, since they do not depend on the input.
+func isCrash(err error) bool {
+	var ce ClientError
+	if errors.As(err, &ce) {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "read"
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
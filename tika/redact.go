@@ -0,0 +1,229 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// ErrNotRedactable is returned by the methods of a Client created with
+// WithRedactor whose results cannot be masked.
+var ErrNotRedactable = errors.New("tika: result cannot be redacted")
+
+// A Redactor masks sensitive content, such as personal data, in extracted
+// text and metadata values. See WithRedactor.
+type Redactor interface {
+	Redact(s string) string
+}
+
+// RedactFunc adapts a function to a Redactor.
+type RedactFunc func(string) string
+
+// Redact returns f(s).
+func (f RedactFunc) Redact(s string) string {
+	return f(s)
+}
+
+// A Pattern is a kind of sensitive content masked by a RegexRedactor.
+type Pattern struct {
+	// Name names the kind of content, such as "email".
+	Name string
+	// Regexp matches the content.
+	Regexp *regexp.Regexp
+	// Valid, if not nil, reports whether a match is really sensitive, for
+	// example with a checksum, to reduce false positives.
+	Valid func(match string) bool
+	// Replacement replaces every valid match. If empty, "[REDACTED:Name]"
+	// is used.
+	Replacement string
+}
+
+// Built-in Patterns for common personal data. They favor precision over
+// recall: numbers in other formats are not masked.
+var (
+	// PatternSSN matches U.S. Social Security numbers written as
+	// 123-45-6789, excluding the never-assigned area numbers 000, 666, and
+	// 900-999 and group or serial numbers of all zeros.
+	PatternSSN = Pattern{
+		Name:   "ssn",
+		Regexp: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		Valid:  validSSN,
+	}
+	// PatternCreditCard matches payment card numbers of 13 to 19 digits,
+	// optionally grouped with spaces or dashes, which pass the Luhn check.
+	PatternCreditCard = Pattern{
+		Name:   "credit-card",
+		Regexp: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Valid:  luhn,
+	}
+	// PatternEmail matches email addresses.
+	PatternEmail = Pattern{
+		Name:   "email",
+		Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	}
+)
+
+// A RegexRedactor is a Redactor replacing the matches of its Patterns, in
+// order.
+type RegexRedactor struct {
+	Patterns []Pattern
+}
+
+// NewRegexRedactor returns a RegexRedactor for patterns, or for PatternSSN,
+// PatternCreditCard, and PatternEmail if there are none.
+func NewRegexRedactor(patterns ...Pattern) *RegexRedactor {
+	if len(patterns) == 0 {
+		patterns = []Pattern{PatternSSN, PatternCreditCard, PatternEmail}
+	}
+	return &RegexRedactor{Patterns: patterns}
+}
+
+// Redact returns s with the valid matches of every Pattern replaced.
+func (r *RegexRedactor) Redact(s string) string {
+	for _, p := range r.Patterns {
+		repl := p.Replacement
+		if repl == "" {
+			repl = "[REDACTED:" + p.Name + "]"
+		}
+		s = p.Regexp.ReplaceAllStringFunc(s, func(m string) string {
+			if p.Valid != nil && !p.Valid(m) {
+				return m
+			}
+			return repl
+		})
+	}
+	return s
+}
+
+// WithRedactor masks sensitive content with r in the text returned by Parse,
+// ParseWithHeader, ParseReader, ParseReaderWithHeader, Translate,
+// TranslateString, and TranslateReader, in the whole response of Meta,
+// MetaWithHeader, MetaField, and MetaFieldWithHeader, and in every value of
+// the Metadata returned by Metadata, MetaFields, MetaFieldJSON, and the
+// recursive methods, including their XTIKAContent. It also masks the values
+// of FormFields, the names and cells of Spreadsheet, the texts of Entities
+// and the names of GeoTopics, and the strings, but not the numbers, of
+// MetaRecursiveTyped. A Pipeline using the Client is redacted too, except
+// for the results of Rule.Handler. Redaction runs after WithTextOptions and
+// WithTextProcessors.
+//
+// Matches can span reads, so readers returned by ParseReader and
+// TranslateReader hold the whole response in memory when redacting. OCR,
+// whose words keep their positions on the page, and Unpack and Do, which
+// return the raw content, cannot be redacted and return ErrNotRedactable.
+// The data of ExtractImages is not redacted.
+func WithRedactor(r Redactor) Option {
+	return func(c *Client) {
+		c.redactor = r
+	}
+}
+
+// redactString returns s masked by the redactor of c, if any.
+func (c *Client) redactString(s string) string {
+	if c.redactor == nil {
+		return s
+	}
+	return c.redactor.Redact(s)
+}
+
+// redactBody returns body, the response to a call returning err, with its
+// content masked by the redactor of c, if any. The body is read into memory
+// and closed, as matches can span reads.
+func (c *Client) redactBody(body io.ReadCloser, err error) (io.ReadCloser, error) {
+	if err != nil || c.redactor == nil {
+		return body, err
+	}
+	defer body.Close()
+	s, err := readString(body)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(strings.NewReader(c.redactor.Redact(s))), nil
+}
+
+// redactable returns ErrNotRedactable if c has a redactor.
+func (c *Client) redactable() error {
+	if c.redactor != nil {
+		return ErrNotRedactable
+	}
+	return nil
+}
+
+// Redact returns a copy of m with every value masked by r.
+func (m Metadata) Redact(r Redactor) Metadata {
+	out := make(Metadata, len(m))
+	for k, vs := range m {
+		rvs := make([]string, len(vs))
+		for i, v := range vs {
+			rvs[i] = r.Redact(v)
+		}
+		out[k] = rvs
+	}
+	return out
+}
+
+// redactTyped returns a copy of m with every string masked by r.
+func redactTyped(m TypedMetadata, r Redactor) TypedMetadata {
+	out := make(TypedMetadata, len(m))
+	for k, v := range m {
+		out[k] = redactValue(v, r)
+	}
+	return out
+}
+
+// redactValue returns v with its strings masked by r.
+func redactValue(v MetaValue, r Redactor) MetaValue {
+	switch v.kind {
+	case MetaString:
+		return StringValue(r.Redact(v.s))
+	case MetaList:
+		list := make([]MetaValue, len(v.list))
+		for i, e := range v.list {
+			list[i] = redactValue(e, r)
+		}
+		return ListValue(list...)
+	}
+	return v
+}
+
+// validSSN reports whether s, formatted as 123-45-6789, is a possible Social
+// Security number.
+func validSSN(s string) bool {
+	area, group, serial := s[:3], s[4:6], s[7:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// luhn reports whether the digits of s pass the Luhn check.
+func luhn(s string) bool {
+	s = strings.NewReplacer(" ", "", "-", "").Replace(s)
+	sum := 0
+	for i := range s {
+		d := int(s[len(s)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegexRedactor(t *testing.T) {
+	r := NewRegexRedactor()
+	tests := []struct {
+		in, want string
+	}{
+		{"SSN 123-45-6789.", "SSN [REDACTED:ssn]."},
+		{"Not an SSN: 000-12-3456, 666-12-3456, 900-12-3456.", "Not an SSN: 000-12-3456, 666-12-3456, 900-12-3456."},
+		{"Card 4111 1111 1111 1111 expires", "Card [REDACTED:credit-card] expires"},
+		{"Card 4111-1111-1111-1111", "Card [REDACTED:credit-card]"},
+		{"Order 4111111111111112", "Order 4111111111111112"},
+		{"Mail jane.doe+tika@example.co.uk now", "Mail [REDACTED:email] now"},
+		{"Nothing to see", "Nothing to see"},
+	}
+	for _, test := range tests {
+		if got := r.Redact(test.in); got != test.want {
+			t.Errorf("Redact(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+
+	custom := NewRegexRedactor(Pattern{Name: "id", Regexp: PatternEmail.Regexp, Replacement: "***"})
+	if got, want := custom.Redact("a@b.org"), "***"; got != want {
+		t.Errorf("Redact with a Replacement = %q, want %q", got, want)
+	}
+}
+
+func TestWithRedactor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/tika":
+			w.Write([]byte("Contact a@b.org "))
+		case r.URL.Path == "/meta":
+			w.Write([]byte(`{"Author": ["a@b.org"], "Content-Type": ["text/plain"]}`))
+		case strings.HasPrefix(r.URL.Path, "/rmeta"):
+			w.Write([]byte(`[{"X-TIKA:content": ["SSN 123-45-6789 "], "Author": ["a@b.org"]}]`))
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL, WithTextOptions(TextOptions{CollapseWhitespace: true}), WithRedactor(NewRegexRedactor()))
+	ctx := context.Background()
+
+	if got, err := c.Parse(ctx, strings.NewReader("x")); err != nil || got != "Contact [REDACTED:email]" {
+		t.Errorf("Parse got %q, %v, want %q", got, err, "Contact [REDACTED:email]")
+	}
+	m, err := c.Metadata(ctx, strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("Metadata got error: %v", err)
+	}
+	if want := (Metadata{"Author": {"[REDACTED:email]"}, "Content-Type": {"text/plain"}}); !reflect.DeepEqual(m, want) {
+		t.Errorf("Metadata got %v, want %v", m, want)
+	}
+	docs, err := c.MetaRecursive(ctx, strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("MetaRecursive got error: %v", err)
+	}
+	if want := (Metadata{XTIKAContent: {"SSN [REDACTED:ssn]"}, "Author": {"[REDACTED:email]"}}); len(docs) != 1 || !reflect.DeepEqual(Metadata(docs[0]), want) {
+		t.Errorf("MetaRecursive got %v, want [%v]", docs, want)
+	}
+}
+
+func TestWithRedactorEntryPoints(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/meta/"):
+			w.Write([]byte("a@b.org"))
+		case r.URL.Path == "/meta":
+			w.Write([]byte(`"Author","a@b.org"`))
+		default:
+			w.Write([]byte("Contact a@b.org"))
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL, WithRedactor(NewRegexRedactor()))
+	ctx := context.Background()
+	readAll := func(rc io.ReadCloser, err error) (string, error) {
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+		b, err := ioutil.ReadAll(rc)
+		return string(b), err
+	}
+
+	tests := []struct {
+		name string
+		call func() (string, error)
+		want string
+	}{
+		{"Meta", func() (string, error) { return c.Meta(ctx, strings.NewReader("x")) }, `"Author","[REDACTED:email]"`},
+		{"MetaWithHeader", func() (string, error) { return c.MetaWithHeader(ctx, strings.NewReader("x"), nil) }, `"Author","[REDACTED:email]"`},
+		{"MetaField", func() (string, error) { return c.MetaField(ctx, strings.NewReader("x"), "Author") }, "[REDACTED:email]"},
+		{"MetaFieldWithHeader", func() (string, error) { return c.MetaFieldWithHeader(ctx, strings.NewReader("x"), "Author", nil) }, "[REDACTED:email]"},
+		{"Translate", func() (string, error) { return c.Translate(ctx, strings.NewReader("x"), Lingo24Translator, "fr", "en") }, "Contact [REDACTED:email]"},
+		{"TranslateString", func() (string, error) { return c.TranslateString(ctx, "x", Lingo24Translator, "fr", "en") }, "Contact [REDACTED:email]"},
+		{"TranslateReader", func() (string, error) {
+			return readAll(c.TranslateReader(ctx, strings.NewReader("x"), Lingo24Translator, "fr", "en"))
+		}, "Contact [REDACTED:email]"},
+		{"ParseReader", func() (string, error) { return readAll(c.ParseReader(ctx, strings.NewReader("x"))) }, "Contact [REDACTED:email]"},
+		{"ParseReaderWithHeader", func() (string, error) { return readAll(c.ParseReaderWithHeader(ctx, strings.NewReader("x"), nil)) }, "Contact [REDACTED:email]"},
+	}
+	for _, test := range tests {
+		got, err := test.call()
+		if err != nil {
+			t.Errorf("%s got error: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWithRedactorStructured(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get(PDFExtractAcroFormHeader) != "":
+			w.Write([]byte(`<html><body><div class="acroform"><ol><li fieldName="Email">Email: a@b.org</li></ol></div></body></html>`))
+		case r.URL.Path == "/tika":
+			w.Write([]byte(`<html><body><h1>a@b.org</h1><table><tr><td>Contact a@b.org</td><td>1</td></tr></table></body></html>`))
+		case strings.HasPrefix(r.URL.Path, "/rmeta"):
+			w.Write([]byte(`[{"X-TIKA:content":"Mail a@b.org","NER_EMAIL":"a@b.org","Count":3,"To":["a@b.org",true]}]`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL, WithRedactor(NewRegexRedactor()))
+	ctx := context.Background()
+	const masked = "[REDACTED:email]"
+
+	fields, err := c.FormFields(ctx, strings.NewReader("x"), FormOptions{})
+	if err != nil {
+		t.Fatalf("FormFields got error: %v", err)
+	}
+	if want := map[string]string{"Email": masked}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("FormFields got %v, want %v", fields, want)
+	}
+
+	sheets, err := c.Spreadsheet(ctx, strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("Spreadsheet got error: %v", err)
+	}
+	if want := []Sheet{{Name: masked, Rows: [][]string{{"Contact " + masked, "1"}}}}; !reflect.DeepEqual(sheets, want) {
+		t.Errorf("Spreadsheet got %v, want %v", sheets, want)
+	}
+
+	es, err := c.Entities(ctx, strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("Entities got error: %v", err)
+	}
+	if want := []Entity{{Type: "EMAIL", Text: masked, Offsets: []int{5}}}; !reflect.DeepEqual(es, want) {
+		t.Errorf("Entities got %v, want %v", es, want)
+	}
+
+	docs, err := c.MetaRecursiveTyped(ctx, strings.NewReader("x"), "")
+	if err != nil {
+		t.Fatalf("MetaRecursiveTyped got error: %v", err)
+	}
+	want := TypedMetadata{
+		XTIKAContent: StringValue("Mail " + masked),
+		"NER_EMAIL":  StringValue(masked),
+		"Count":      NumberValue("3"),
+		"To":         ListValue(StringValue(masked), BoolValue(true)),
+	}
+	if len(docs) != 1 || !reflect.DeepEqual(docs[0], want) {
+		t.Errorf("MetaRecursiveTyped got %v, want [%v]", docs, want)
+	}
+}
+
+func TestWithRedactorNotRedactable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL, WithRedactor(NewRegexRedactor()))
+	ctx := context.Background()
+
+	if _, err := c.OCR(ctx, strings.NewReader("x"), OCRHOCR, nil); err != ErrNotRedactable {
+		t.Errorf("OCR got error %v, want %v", err, ErrNotRedactable)
+	}
+	err := c.Unpack(ctx, strings.NewReader("x"), func(EmbeddedFile) error { return nil })
+	if err != ErrNotRedactable {
+		t.Errorf("Unpack got error %v, want %v", err, ErrNotRedactable)
+	}
+	if _, err := c.Do(ctx, "GET", "/version", nil, nil); err != ErrNotRedactable {
+		t.Errorf("Do got error %v, want %v", err, ErrNotRedactable)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(RequestIDHeader))
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+
+	_, err := c.Parse(context.Background(), nil)
+	var ce ClientError
+	if !errors.As(err, &ce) {
+		t.Fatalf("Parse got error %v, want a ClientError", err)
+	}
+	if len(got[0]) != 32 || ce.RequestID != got[0] {
+		t.Errorf("Parse sent request ID %q and got ClientError.RequestID %q, want the same generated ID", got[0], ce.RequestID)
+	}
+	if want := "response code 422 (request ID " + got[0] + ")"; err.Error() != want {
+		t.Errorf("Error got %q, want %q", err.Error(), want)
+	}
+
+	c.Parse(context.Background(), nil)
+	if got[1] == got[0] {
+		t.Error("Parse reused a generated request ID")
+	}
+
+	header := http.Header{}
+	header.Set(RequestIDHeader, "from-header")
+	c.ParseWithHeader(context.Background(), nil, header)
+	if got[2] != "from-header" {
+		t.Errorf("ParseWithHeader sent request ID %q, want %q", got[2], "from-header")
+	}
+
+	ctx := ContextWithRequestID(context.Background(), "from-context")
+	c.ParseWithHeader(ctx, nil, header)
+	if got[3] != "from-context" {
+		t.Errorf("Parse sent request ID %q, want %q", got[3], "from-context")
+	}
+	if len(header) != 1 || header.Get(RequestIDHeader) != "from-header" {
+		t.Errorf("Parse modified the caller's header: %v", header)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"net/http"
+)
+
+type responseHeaderKey struct{}
+
+// ContextWithResponseHeader returns a context which makes Client methods
+// store the headers of the server's response in *h, for example the
+// Content-Type of the output or warnings set by the server:
+//
+//	var h http.Header
+//	body, err := client.Parse(tika.ContextWithResponseHeader(ctx, &h), input)
+//
+// The headers are stored for error responses too. If a method makes several
+// requests, *h holds the headers of the last one. A context is used rather
+// than an Option so that a Client shared by several goroutines can capture
+// the headers of each call separately.
+func ContextWithResponseHeader(ctx context.Context, h *http.Header) context.Context {
+	return context.WithValue(ctx, responseHeaderKey{}, h)
+}
+
+// captureResponseHeader stores header in the destination set in ctx with
+// ContextWithResponseHeader, if any.
+func captureResponseHeader(ctx context.Context, header http.Header) {
+	if h, ok := ctx.Value(responseHeaderKey{}).(*http.Header); ok && h != nil {
+		*h = header.Clone()
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContextWithResponseHeader(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", r.URL.Path)
+		if r.URL.Path == "/meta" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	tests := []struct {
+		name    string
+		call    func(ctx context.Context) error
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Parse",
+			call: func(ctx context.Context) error {
+				_, err := c.Parse(ctx, strings.NewReader("doc"))
+				return err
+			},
+			want: "/tika",
+		},
+		{
+			name: "Detect",
+			call: func(ctx context.Context) error {
+				_, err := c.Detect(ctx, strings.NewReader("doc"))
+				return err
+			},
+			want: "/detect/stream",
+		},
+		{
+			name: "Meta",
+			call: func(ctx context.Context) error {
+				_, err := c.Meta(ctx, strings.NewReader("doc"))
+				return err
+			},
+			want:    "/meta",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		var h http.Header
+		err := test.call(ContextWithResponseHeader(context.Background(), &h))
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("%s got error %v, want error %v", test.name, err, test.wantErr)
+		}
+		if got := h.Get("X-Test"); got != test.want {
+			t.Errorf("%s captured X-Test %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RestartOptions configure Restart.
+type RestartOptions struct {
+	// StopTimeout is how long the Java process is given to exit gracefully
+	// before it is killed. If less than or equal to 0, 10s is used.
+	StopTimeout time.Duration
+	// JavaProps, if not nil, replaces the JavaProps of the server.
+	JavaProps map[string]string
+	// JavaOptions, if not nil, replaces the JavaOptions of the server, for
+	// example to change the heap size with -Xmx.
+	JavaOptions []string
+	// Readiness configures how Restart waits for the new process.
+	Readiness ReadinessOptions
+}
+
+// Restart gracefully stops the Java process of a started server and starts a
+// new one, applying any configuration changes in opts. The new process
+// listens on the same port, so URL and any Client using it stay valid;
+// requests made while the server restarts fail with ErrServerUnavailable.
+// If the new process cannot be started, Restart returns an error and the
+// server is left stopped.
+//
+// Restart is meant to bounce a wedged server or to apply new JVM settings
+// without rebuilding the state around the Server. It must not be called on a
+// server run by RunService; use ServiceOptions.Restart instead.
+func (s *Server) Restart(ctx context.Context, opts RestartOptions) error {
+	if s.adopted {
+		return fmt.Errorf("cannot restart an adopted server")
+	}
+	if s.cmd == nil {
+		return fmt.Errorf("server process not started")
+	}
+	s.stopProcess(opts.StopTimeout)
+	if opts.JavaProps != nil {
+		s.JavaProps = opts.JavaProps
+	}
+	if opts.JavaOptions != nil {
+		s.JavaOptions = opts.JavaOptions
+	}
+	return s.startSamePort(ctx, opts.Readiness)
+}
+
+// stopProcess gracefully stops the Java process, killing it after timeout,
+// and releases its resources, keeping the JAR so s can be started again.
+func (s *Server) stopProcess(timeout time.Duration) {
+	exited := make(chan error, 1)
+	cmd := s.cmd
+	go func() { exited <- cmd.Wait() }()
+	s.stopService(exited, timeout)
+	s.release()
+}
+
+// portReleaseWait is how long startSamePort waits for the port of a stopped
+// process to become free.
+const portReleaseWait = 5 * time.Second
+
+// startSamePort starts s on its current port, waiting for the previous
+// process to release it, instead of moving to another port as allowed by
+// AutoIncrementPort.
+func (s *Server) startSamePort(ctx context.Context, opts ReadinessOptions) error {
+	deadline := time.Now().Add(portReleaseWait)
+	for {
+		err := checkPort(s.host, s.port)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		t := time.NewTimer(100 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	tries := s.portTries
+	s.portTries = 0
+	defer func() { s.portTries = tries }()
+	return s.StartAndWaitReady(ctx, opts)
+}
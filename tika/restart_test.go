@@ -0,0 +1,65 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestRestart(t *testing.T) {
+	s := serviceServer(t, "10")
+	var args []string
+	command = func(_ string, a ...string) *exec.Cmd {
+		args = a
+		c := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep", "10")
+		c.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return c
+	}
+	ctx := context.Background()
+	opts := RestartOptions{
+		StopTimeout: time.Second,
+		JavaOptions: []string{"-Xmx2g"},
+		Readiness:   ReadinessOptions{PollInterval: 10 * time.Millisecond},
+	}
+	if err := s.Restart(ctx, opts); err == nil {
+		t.Error("Restart before Start got no error")
+	}
+	if err := s.StartAndWaitReady(ctx, opts.Readiness); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	defer func() { s.Stop() }()
+	pid, url := s.cmd.Process.Pid, s.URL()
+	if err := s.Restart(ctx, opts); err != nil {
+		t.Fatalf("Restart got error: %v", err)
+	}
+	if s.cmd.Process.Pid == pid {
+		t.Errorf("Restart kept the process %d", pid)
+	}
+	if processAlive(pid) {
+		t.Errorf("Restart left the process %d running", pid)
+	}
+	if s.URL() != url {
+		t.Errorf("URL() after Restart = %q, want %q", s.URL(), url)
+	}
+	if len(args) == 0 || args[0] != "-Xmx2g" {
+		t.Errorf("Restart started the JVM with %q, want -Xmx2g first", args)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// SandboxOptions restrict the Java process started by Server.Start. Tika
+// parses untrusted input, so it is good practice to run it with as few
+// privileges as possible.
+type SandboxOptions struct {
+	// SysProcAttr, if not nil, is used to start the Java process. For
+	// example, on Unix set Credential to run it as another user or Setpgid to
+	// start it in a new process group. The directories created for the
+	// process, by PrivateDir and Server.ManageTempDir, are then owned by the
+	// user of Credential, which requires the privilege to change the owner
+	// of files.
+	SysProcAttr *syscall.SysProcAttr
+	// PrivateDir runs the Java process in a new, private working directory,
+	// which is removed when the server stops.
+	PrivateDir bool
+	// Cgroup, if not empty, is the path of a Linux cgroup v2 directory, such
+	// as /sys/fs/cgroup/tika, that the Java process is moved into. The
+	// cgroup must exist and be writable by the current user.
+	Cgroup string
+	// MemoryMax, if greater than 0, is written to memory.max of Cgroup to
+	// limit the memory of the Java process, in bytes.
+	MemoryMax int64
+	// CPUs, if greater than 0, is written to cpu.max of Cgroup to limit the
+	// Java process to the given number of CPUs, for example 1.5.
+	CPUs float64
+}
+
+// cpuPeriod is the cgroup cpu.max period, in microseconds.
+const cpuPeriod = 100000
+
+// Sandbox sets up the server to start the Java process with the given
+// restrictions.
+// If used, Sandbox must be called before starting the server.
+// If you want to remove the restrictions, call Server.Sandbox(nil).
+func (s *Server) Sandbox(opts *SandboxOptions) error {
+	if s.cmd != nil {
+		return fmt.Errorf("server process already started, cannot change sandbox options")
+	}
+	if opts != nil && opts.Cgroup == "" && (opts.MemoryMax > 0 || opts.CPUs > 0) {
+		return fmt.Errorf("MemoryMax and CPUs require a Cgroup")
+	}
+	s.sandbox = opts
+	return nil
+}
+
+// prepare applies the options that must be set before cmd is started. It
+// returns the private directory created for cmd, if any.
+func (so *SandboxOptions) prepare(cmd *exec.Cmd) (string, error) {
+	if so == nil {
+		return "", nil
+	}
+	cmd.SysProcAttr = so.SysProcAttr
+	if so.Cgroup != "" {
+		if so.MemoryMax > 0 {
+			if err := writeCgroup(so.Cgroup, "memory.max", strconv.FormatInt(so.MemoryMax, 10)); err != nil {
+				return "", err
+			}
+		}
+		if so.CPUs > 0 {
+			quota := int64(so.CPUs * cpuPeriod)
+			if err := writeCgroup(so.Cgroup, "cpu.max", fmt.Sprintf("%d %d", quota, cpuPeriod)); err != nil {
+				return "", err
+			}
+		}
+	}
+	if !so.PrivateDir {
+		return "", nil
+	}
+	dir, err := ioutil.TempDir("", "tika-server-")
+	if err != nil {
+		return "", fmt.Errorf("error creating private directory: %v", err)
+	}
+	if err := chownForProcess(dir, so.SysProcAttr); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("error giving the private directory to the server user: %v", err)
+	}
+	cmd.Dir = dir
+	return dir, nil
+}
+
+// join moves the started process p into the cgroup, if any.
+func (so *SandboxOptions) join(p *os.Process) error {
+	if so == nil || so.Cgroup == "" {
+		return nil
+	}
+	return writeCgroup(so.Cgroup, "cgroup.procs", strconv.Itoa(p.Pid))
+}
+
+// writeCgroup writes value to the given file of the cgroup at dir.
+func writeCgroup(dir, file, value string) error {
+	if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(value), 0644); err != nil {
+		return fmt.Errorf("error configuring cgroup: %v", err)
+	}
+	return nil
+}
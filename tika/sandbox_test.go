@@ -0,0 +1,98 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestSandboxError(t *testing.T) {
+	s := &Server{}
+	if err := s.Sandbox(&SandboxOptions{MemoryMax: 1 << 30}); err == nil {
+		t.Error("Sandbox with MemoryMax and no Cgroup got no error")
+	}
+	if err := s.Sandbox(nil); err != nil {
+		t.Errorf("Sandbox(nil) got error: %v", err)
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	path, err := os.Executable() // Use the text executable path as a dummy jar.
+	if err != nil {
+		t.Skip("cannot find current test executable")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "1.14")
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("error creating test server: %v", err)
+	}
+
+	// A plain directory stands in for the cgroup file system.
+	cgroup, err := ioutil.TempDir("", "cgroup")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(cgroup)
+
+	s, err := NewServer(path, tsURL.Port())
+	if err != nil {
+		t.Fatalf("NewServer got error: %v", err)
+	}
+	err = s.Sandbox(&SandboxOptions{
+		PrivateDir: true,
+		Cgroup:     cgroup,
+		MemoryMax:  1 << 30,
+		CPUs:       1.5,
+	})
+	if err != nil {
+		t.Fatalf("Sandbox got error: %v", err)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	dir := s.cmd.Dir
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		t.Errorf("Start did not create a private directory: %v", err)
+	}
+	want := map[string]string{
+		"memory.max":   "1073741824",
+		"cpu.max":      "150000 100000",
+		"cgroup.procs": strconv.Itoa(s.cmd.Process.Pid),
+	}
+	for file, v := range want {
+		got, err := ioutil.ReadFile(filepath.Join(cgroup, file))
+		if err != nil || string(got) != v {
+			t.Errorf("Start wrote %s = %q, %v, want %q", file, got, err, v)
+		}
+	}
+	s.Stop()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Stop did not remove the private directory: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+//go:build unix
+
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+func TestSandboxDirOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing the owner of files requires root")
+	}
+	const nobody = 65534
+	attr := &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: nobody, Gid: nobody}}
+	s := &Server{manageTmp: true, sandbox: &SandboxOptions{SysProcAttr: attr, PrivateDir: true}}
+	if _, err := s.createTempDir(); err != nil {
+		t.Fatalf("createTempDir got error: %v", err)
+	}
+	defer s.removeTempDir()
+	dir, err := s.sandbox.prepare(exec.Command("true"))
+	if err != nil {
+		t.Fatalf("prepare got error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for _, d := range []string{s.tmpDir, dir} {
+		fi, err := os.Stat(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		st := fi.Sys().(*syscall.Stat_t)
+		if st.Uid != nobody || st.Gid != nobody {
+			t.Errorf("%s is owned by %d:%d, want %d:%d", d, st.Uid, st.Gid, nobody, nobody)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// SecurityInfo is a typed view of the encryption and digital signature
+// metadata Tika extracts from documents, so that signed or encrypted
+// documents can be routed differently. See Metadata.Security.
+type SecurityInfo struct {
+	// Encrypted reports whether the document is encrypted, even if Tika
+	// could decrypt it, as PDFs with an empty user password.
+	Encrypted bool
+	// Signed reports whether the document has a digital signature. Tika
+	// does not verify signatures.
+	Signed bool
+	// Signatures describe the signatures of the document, as far as Tika
+	// reports them, which it only does for PDFs.
+	Signatures []SignatureInfo
+	// Restrictions are the permissions the document denies, such as
+	// "can_modify" or "extract_content", as reported for PDFs.
+	Restrictions []string
+}
+
+// SignatureInfo describes a digital signature. Fields are empty if Tika did
+// not report them.
+type SignatureInfo struct {
+	// Name is the name of the signer.
+	Name string
+	// Date is when the document was signed.
+	Date time.Time
+	// Location is where the document was signed.
+	Location string
+	// Reason is why the document was signed.
+	Reason string
+	// ContactInfo is how to contact the signer.
+	ContactInfo string
+	// Filter is the handler of the signature, such as
+	// "Adobe.PPKLite".
+	Filter string
+}
+
+// encryptedTypes are the Content-Types Tika reports for encrypted documents
+// it cannot open.
+var encryptedTypes = map[string]bool{
+	"application/x-tika-ooxml-protected":    true,
+	"application/x-tika-msoffice-encrypted": true,
+	"application/encrypted":                 true,
+}
+
+// Security returns the typed security metadata in m.
+func (m Metadata) Security() SecurityInfo {
+	var info SecurityInfo
+	info.Encrypted = isTrue(m.Get("pdf:encrypted")) || isTrue(m.Get("encrypted")) ||
+		encryptedTypes[baseMIMEType(m.Get("Content-Type"))]
+	for _, w := range m.Warnings() {
+		if strings.Contains(w.Message, "EncryptedDocumentException") {
+			info.Encrypted = true
+		}
+	}
+
+	names, dates := m["signature:name"], m["signature:date"]
+	locations, reasons := m["signature:location"], m["signature:reason"]
+	contacts, filters := m["signature:contact-info"], m["signature:filter"]
+	n := 0
+	for _, vs := range [][]string{names, dates, locations, reasons, contacts, filters} {
+		if len(vs) > n {
+			n = len(vs)
+		}
+	}
+	at := func(vs []string, i int) string {
+		if i < len(vs) {
+			return vs[i]
+		}
+		return ""
+	}
+	for i := 0; i < n; i++ {
+		info.Signatures = append(info.Signatures, SignatureInfo{
+			Name:        at(names, i),
+			Date:        parseTime(at(dates, i)),
+			Location:    at(locations, i),
+			Reason:      at(reasons, i),
+			ContactInfo: at(contacts, i),
+			Filter:      at(filters, i),
+		})
+	}
+	info.Signed = n > 0 || isTrue(m.Get("hasSignature")) || isTrue(m.Get("pdf:hasSignature"))
+
+	const permission = "access_permission:"
+	for k := range m {
+		if strings.HasPrefix(k, permission) && strings.EqualFold(m.Get(k), "false") {
+			info.Restrictions = append(info.Restrictions, strings.TrimPrefix(k, permission))
+		}
+	}
+	sort.Strings(info.Restrictions)
+	return info
+}
+
+// isTrue reports whether s is "true", ignoring case.
+func isTrue(s string) bool {
+	return strings.EqualFold(strings.TrimSpace(s), "true")
+}
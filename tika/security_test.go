@@ -0,0 +1,95 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSecurity(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Metadata
+		want SecurityInfo
+	}{
+		{
+			name: "plain",
+			m:    Metadata{"Content-Type": {"application/pdf"}, "pdf:encrypted": {"false"}, "access_permission:can_modify": {"true"}},
+			want: SecurityInfo{},
+		},
+		{
+			name: "encrypted PDF",
+			m: Metadata{
+				"pdf:encrypted":                       {"true"},
+				"access_permission:can_modify":        {"false"},
+				"access_permission:extract_content":   {"FALSE"},
+				"access_permission:can_print":         {"true"},
+				"access_permission:assemble_document": {"false"},
+			},
+			want: SecurityInfo{Encrypted: true, Restrictions: []string{"assemble_document", "can_modify", "extract_content"}},
+		},
+		{
+			name: "protected OOXML",
+			m:    Metadata{"Content-Type": {"application/x-tika-ooxml-protected"}},
+			want: SecurityInfo{Encrypted: true},
+		},
+		{
+			name: "embedded encryption exception",
+			m:    Metadata{XTIKAExceptionPrefix + "embedded_exception": {"org.apache.tika.exception.EncryptedDocumentException: Unable to process"}},
+			want: SecurityInfo{Encrypted: true},
+		},
+		{
+			name: "signed PDF",
+			m: Metadata{
+				"hasSignature":           {"true"},
+				"signature:name":         {"Jane Doe", "John Roe"},
+				"signature:date":         {"2020-01-02T03:04:05Z", "2021-02-03T04:05:06Z"},
+				"signature:reason":       {"Approval"},
+				"signature:location":     {"", "Zürich"},
+				"signature:contact-info": {"jane@example.com"},
+				"signature:filter":       {"Adobe.PPKLite", "Adobe.PPKLite"},
+			},
+			want: SecurityInfo{Signed: true, Signatures: []SignatureInfo{
+				{
+					Name:        "Jane Doe",
+					Date:        time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+					Reason:      "Approval",
+					ContactInfo: "jane@example.com",
+					Filter:      "Adobe.PPKLite",
+				},
+				{
+					Name:     "John Roe",
+					Date:     time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC),
+					Location: "Zürich",
+					Filter:   "Adobe.PPKLite",
+				},
+			}},
+		},
+		{
+			name: "signature flag only",
+			m:    Metadata{"hasSignature": {"TRUE"}},
+			want: SecurityInfo{Signed: true},
+		},
+	}
+	for _, test := range tests {
+		if got := test.m.Security(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Security() = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
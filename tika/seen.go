@@ -0,0 +1,187 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// A SeenSet is a Bloom filter recording which Inputs a Batch has parsed,
+// keyed by their name, size and modification time, so that repeated crawls
+// of large corpora skip unchanged files without opening them. See
+// Batch.Seen.
+//
+// Unlike a Journal, a SeenSet takes a fixed amount of memory whatever the
+// number of inputs, about 1.2 bytes per input for a 1% false positive rate,
+// but it may report an input it has never seen as seen: with the rate the
+// SeenSet was created with, a changed file is wrongly skipped. It is safe
+// for concurrent use.
+type SeenSet struct {
+	mu   sync.Mutex
+	bits []uint64
+	k    uint32
+	n    uint64 // n is the number of keys added.
+}
+
+// NewSeenSet returns an empty SeenSet sized for n keys with a false positive
+// rate of p, such as 0.01, once n keys are added.
+func NewSeenSet(n int, p float64) *SeenSet {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	return &SeenSet{bits: make([]uint64, (int(m)+63)/64), k: uint32(k)}
+}
+
+// SeenKey returns the key of input in a SeenSet: its Name, Size, and
+// ModTime. It returns "" if input has no ModTime, as such inputs cannot be
+// known to be unchanged.
+func SeenKey(input Input) string {
+	if input.ModTime.IsZero() {
+		return ""
+	}
+	return input.Name + "\x00" + strconv.FormatInt(input.Size, 10) + "\x00" + strconv.FormatInt(input.ModTime.UnixNano(), 10)
+}
+
+// locations calls fn with the index of every bit of key, using double
+// hashing of its SHA-256 hash.
+func (s *SeenSet) locations(key string, fn func(i uint64)) {
+	sum := sha256.Sum256([]byte(key))
+	h1 := binary.LittleEndian.Uint64(sum[:8])
+	h2 := binary.LittleEndian.Uint64(sum[8:16]) | 1
+	m := uint64(len(s.bits)) * 64
+	for i := uint64(0); i < uint64(s.k); i++ {
+		fn((h1 + i*h2) % m)
+	}
+}
+
+// Add adds key to s.
+func (s *SeenSet) Add(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locations(key, func(i uint64) { s.bits[i/64] |= 1 << (i % 64) })
+	s.n++
+}
+
+// Contains reports whether key has probably been added to s. It returns
+// false if key has never been added, and true if it has, or, with the false
+// positive rate of s, if it has not.
+func (s *SeenSet) Contains(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found := true
+	s.locations(key, func(i uint64) {
+		if s.bits[i/64]&(1<<(i%64)) == 0 {
+			found = false
+		}
+	})
+	return found
+}
+
+// Len returns the number of keys added to s, counting keys added several
+// times once per call to Add.
+func (s *SeenSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int(s.n)
+}
+
+// seenMagic starts a SeenSet file.
+const seenMagic = "go-tika seen v1\n"
+
+// ErrBadSeenSet is returned by LoadSeenSet when the file is not a SeenSet.
+var ErrBadSeenSet = errors.New("not a seen set file")
+
+// seenHeader follows seenMagic in a SeenSet file, before the bits.
+type seenHeader struct {
+	Words uint64
+	K     uint32
+	N     uint64
+}
+
+// Save writes s to the file at path, replacing it atomically, so that a
+// crawl can be checkpointed while it runs and resumed after a crash.
+func (s *SeenSet) Save(path string) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	w := bufio.NewWriter(f)
+	s.mu.Lock()
+	io.WriteString(w, seenMagic)
+	binary.Write(w, binary.LittleEndian, seenHeader{Words: uint64(len(s.bits)), K: s.k, N: s.n})
+	binary.Write(w, binary.LittleEndian, s.bits)
+	s.mu.Unlock()
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadSeenSet reads the SeenSet saved at path. If the file does not exist,
+// it returns NewSeenSet(n, p); otherwise n and p are ignored, as the size
+// of a SeenSet is fixed when it is created.
+func LoadSeenSet(path string, n int, p float64) (*SeenSet, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return NewSeenSet(n, p), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	magic := make([]byte, len(seenMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != seenMagic {
+		return nil, fmt.Errorf("%s: %w", path, ErrBadSeenSet)
+	}
+	var h seenHeader
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil || h.K == 0 || h.Words == 0 {
+		return nil, fmt.Errorf("%s: %w", path, ErrBadSeenSet)
+	}
+	if fi, err := f.Stat(); err == nil && uint64(fi.Size()) != uint64(len(seenMagic))+20+h.Words*8 {
+		return nil, fmt.Errorf("%s: %w", path, ErrBadSeenSet)
+	}
+	s := &SeenSet{bits: make([]uint64, h.Words), k: h.K, n: h.N}
+	if err := binary.Read(r, binary.LittleEndian, s.bits); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeenSet(t *testing.T) {
+	const n = 10000
+	s := NewSeenSet(n, 0.01)
+	for i := 0; i < n; i++ {
+		s.Add(fmt.Sprint("added", i))
+	}
+	for i := 0; i < n; i++ {
+		if key := fmt.Sprint("added", i); !s.Contains(key) {
+			t.Fatalf("Contains(%q) got false for an added key, want true", key)
+		}
+	}
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		if s.Contains(fmt.Sprint("other", i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / n; rate > 0.02 {
+		t.Errorf("false positive rate got %v, want about 0.01", rate)
+	}
+	if got := s.Len(); got != n {
+		t.Errorf("Len() = %d, want %d", got, n)
+	}
+
+	dir, err := ioutil.TempDir("", "seen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "seen")
+	empty, err := LoadSeenSet(path, 10, 0.01)
+	if err != nil || empty.Contains("added0") {
+		t.Errorf("LoadSeenSet of a missing file = %v, %v, want an empty set", empty, err)
+	}
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save got error: %v", err)
+	}
+	loaded, err := LoadSeenSet(path, 10, 0.01)
+	if err != nil {
+		t.Fatalf("LoadSeenSet got error: %v", err)
+	}
+	if !loaded.Contains("added42") || loaded.Len() != n {
+		t.Errorf("LoadSeenSet got a set with %d keys and without added42, want %d keys", loaded.Len(), n)
+	}
+
+	for _, content := range []string{"", "not a seen set", seenMagic + "short"} {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSeenSet(path, 10, 0.01); !errors.Is(err, ErrBadSeenSet) {
+			t.Errorf("LoadSeenSet(%q) got error %v, want %v", content, err, ErrBadSeenSet)
+		}
+	}
+}
+
+func TestSeenKey(t *testing.T) {
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	base := Input{Name: "a", Size: 1, ModTime: mtime}
+	if SeenKey(Input{Name: "a", Size: 1}) != "" {
+		t.Error("SeenKey of an input without ModTime is not empty")
+	}
+	for _, other := range []Input{
+		{Name: "b", Size: 1, ModTime: mtime},
+		{Name: "a", Size: 2, ModTime: mtime},
+		{Name: "a", Size: 1, ModTime: mtime.Add(time.Second)},
+	} {
+		if SeenKey(other) == SeenKey(base) {
+			t.Errorf("SeenKey(%+v) equals SeenKey(%+v)", other, base)
+		}
+	}
+}
+
+func TestBatchSeen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "seen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a", "b"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(strings.ToUpper(name)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts := echoServer()
+	defer ts.Close()
+	b := &Batch{Client: NewClient(nil, ts.URL), Seen: NewSeenSet(100, 0.01)}
+	run := func() map[string]Result {
+		got := map[string]Result{}
+		err := b.Run(context.Background(), Walk(dir), func(r Result, err error) bool {
+			if err != nil {
+				t.Errorf("Run got error for %s: %v", r.Name, err)
+			}
+			got[filepath.Base(r.Name)] = r
+			return true
+		})
+		if err != nil {
+			t.Fatalf("Run returned an error: %v", err)
+		}
+		return got
+	}
+
+	got := run()
+	if got["a"].Content != "A" || got["b"].Content != "B" {
+		t.Errorf("first run got %+v, want a and b parsed", got)
+	}
+
+	// Changing the modification time makes b parsed again.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "b"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	got = run()
+	if r := got["a"]; !r.Skipped || r.SkipReason != SkipUnchanged {
+		t.Errorf("second run got %+v for a, want skipped as unchanged", r)
+	}
+	if r := got["b"]; r.Skipped || r.Content != "B" {
+		t.Errorf("second run got %+v for the touched b, want parsed", r)
+	}
+
+	// Inputs in the set are not opened.
+	fi, err := os.Stat(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := Input{Name: filepath.Join(dir, "a"), Size: fi.Size(), ModTime: fi.ModTime(), Open: func() (io.ReadCloser, error) {
+		t.Error("Open called for an unchanged input")
+		return nil, errors.New("opened")
+	}}
+	b.Run(context.Background(), Inputs(input), func(r Result, err error) bool { return true })
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"strings"
+)
+
+// MinSegmentLength is the minimum length in bytes of a segment passed to
+// language detection by LanguageSegments. Shorter paragraphs are joined with
+// the following ones, since detection is unreliable on little text.
+const MinSegmentLength = 80
+
+// A LanguageSegment is a range of text in a single language.
+type LanguageSegment struct {
+	// Start and End are the byte offsets of the segment in the text.
+	Start, End int
+	// Language is the two letter code of the detected language.
+	Language string
+}
+
+// LanguageSegments splits text into paragraphs, detects the language of each
+// with LanguageString, and returns the ranges of text in each language, with
+// adjacent paragraphs in the same language merged. Use it for documents that
+// mix languages, where a single language code is misleading.
+func (c *Client) LanguageSegments(ctx context.Context, text string) ([]LanguageSegment, error) {
+	var segs []LanguageSegment
+	for _, p := range paragraphs(text) {
+		lang, err := c.LanguageString(ctx, text[p.Start:p.End])
+		if err != nil {
+			return nil, err
+		}
+		lang = strings.TrimSpace(lang)
+		if n := len(segs); n > 0 && segs[n-1].Language == lang {
+			segs[n-1].End = p.End
+			continue
+		}
+		p.Language = lang
+		segs = append(segs, p)
+	}
+	return segs, nil
+}
+
+// paragraphs returns the ranges of the paragraphs of text, which are
+// separated by blank lines, trimmed of surrounding whitespace and joined so
+// each is at least MinSegmentLength long where possible.
+func paragraphs(text string) []LanguageSegment {
+	var ps []LanguageSegment
+	start := 0
+	for start < len(text) {
+		end := strings.Index(text[start:], "\n\n")
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		s, e := start, end
+		for s < e && isSpace(text[s]) {
+			s++
+		}
+		for e > s && isSpace(text[e-1]) {
+			e--
+		}
+		if s < e {
+			if n := len(ps); n > 0 && ps[n-1].End-ps[n-1].Start < MinSegmentLength {
+				ps[n-1].End = e
+			} else {
+				ps = append(ps, LanguageSegment{Start: s, End: e})
+			}
+		}
+		start = end + 2
+	}
+	// Join a short final paragraph with the previous one.
+	if n := len(ps); n > 1 && ps[n-1].End-ps[n-1].Start < MinSegmentLength {
+		ps[n-2].End = ps[n-1].End
+		ps = ps[:n-1]
+	}
+	return ps
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
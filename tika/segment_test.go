@@ -0,0 +1,64 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLanguageSegments(t *testing.T) {
+	// The server detects French if the text contains "le", else English.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(b), " le ") {
+			w.Write([]byte("fr"))
+			return
+		}
+		w.Write([]byte("en"))
+	}))
+	defer ts.Close()
+
+	en := strings.Repeat("the quick brown fox jumps over the lazy dog ", 3)
+	fr := strings.Repeat("le renard brun saute par-dessus le chien ", 3)
+	text := "  " + en + "\n\n" + en + "\n\nShort.\n\n" + fr + "\n\n" + fr + "\n"
+	got, err := NewClient(nil, ts.URL).LanguageSegments(context.Background(), text)
+	if err != nil {
+		t.Fatalf("LanguageSegments returned an error: %v", err)
+	}
+	var langs []string
+	for _, s := range got {
+		langs = append(langs, s.Language)
+	}
+	if want := []string{"en", "fr"}; !reflect.DeepEqual(langs, want) {
+		t.Fatalf("LanguageSegments got languages %v, want %v", langs, want)
+	}
+	if got[0].Start != 2 || got[1].End != len(strings.TrimRight(text, " \n")) || got[0].End > got[1].Start {
+		t.Errorf("LanguageSegments got ranges %+v", got)
+	}
+	if !strings.HasPrefix(text[got[1].Start:got[1].End], "Short.") {
+		t.Errorf("LanguageSegments did not join the short paragraph to the next: %q", text[got[1].Start:got[1].End])
+	}
+	if got, err := NewClient(nil, ts.URL).LanguageSegments(context.Background(), " \n\n "); err != nil || got != nil {
+		t.Errorf("LanguageSegments of blank text got %v, %v, want nil, nil", got, err)
+	}
+}
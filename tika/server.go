@@ -18,16 +18,24 @@ package tika
 
 import (
 	"context"
-	"crypto/sha512"
+	"crypto"
+	_ "crypto/sha256" // Register crypto.SHA256 for Checksums.
+	_ "crypto/sha512" // Register crypto.SHA512 for Checksums.
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
-
-	"golang.org/x/net/context/ctxhttp"
 )
 
 // Server represents a Tika server. Create a new Server with NewServer,
@@ -36,14 +44,38 @@ import (
 // There is no need to create a Server for an already running Tika Server
 // since you can pass its URL directly to a Client.
 // Additional Java system properties can be added to a Taka Server before
-// startup by adding to the JavaProps map
+// startup by adding to the JavaProps map, and other JVM options, such as
+// -Xmx2g, by appending to JavaOptions.
 type Server struct {
 	jar       string
-	url       string // url is derived from port.
+	tempJar   bool   // tempJar is true if jar is removed when the server stops.
+	url       string // url is derived from host and port.
+	host      string
 	port      string
+	portTries int         // portTries is the number of following ports to try.
+	adopted   bool        // adopted is true if the server was created by Adopt.
+	proc      *os.Process // proc is the process of an adopted server, if known.
 	cmd       *exec.Cmd
 	child     *ChildOptions
-	JavaProps map[string]string
+	socket    string       // socket is the path of the Unix socket proxy, if any.
+	proxy     *http.Server // proxy serves socket while the server runs.
+	sandbox   *SandboxOptions
+	dir       string // dir is the private working directory, if any.
+	manageTmp bool
+	tmpMaxAge time.Duration
+	tmpDir    string        // tmpDir is the managed java.io.tmpdir, if any.
+	stopSweep chan struct{} // stopSweep stops the tmpDir sweeper.
+	output    io.Writer     // output receives stdout and stderr, if not nil.
+	// procMu guards pid and state, which PID, ProcessState, and MonitorUsage
+	// read while the process is started and stopped.
+	procMu sync.Mutex
+	pid    int
+	state  *os.ProcessState
+	// expectVersion and minVersion are checked once the server is ready.
+	expectVersion Version
+	minVersion    Version
+	JavaProps     map[string]string
+	JavaOptions   []string
 }
 
 // ChildOptions represent command line parameters that can be used when Tika is run with the -spawnChild option.
@@ -85,7 +117,8 @@ func (s *Server) URL() string {
 	return s.url
 }
 
-// NewServer creates a new Server. The default port is 9998.
+// NewServer creates a new Server. The default port is 9998. If port is "0", a
+// free port is chosen; use URL to find out which.
 func NewServer(jar, port string) (*Server, error) {
 	if jar == "" {
 		return nil, fmt.Errorf("no jar file specified")
@@ -99,9 +132,15 @@ func NewServer(jar, port string) (*Server, error) {
 	if port == "" {
 		port = "9998"
 	}
+	if port == "0" {
+		p, err := freePort()
+		if err != nil {
+			return nil, fmt.Errorf("could not find a free port: %v", err)
+		}
+		port = p
+	}
 
-	urlString := "http://localhost:" + port
-	u, err := url.Parse(urlString)
+	u, err := serverURL("", port)
 	if err != nil {
 		return nil, fmt.Errorf("invalid port %q: %v", port, err)
 	}
@@ -109,13 +148,193 @@ func NewServer(jar, port string) (*Server, error) {
 	s := &Server{
 		jar:       jar,
 		port:      port,
-		url:       u.String(),
+		url:       u,
 		JavaProps: map[string]string{},
 	}
 
 	return s, nil
 }
 
+// NewServerFromReader creates a new Server like NewServer, but reads the JAR
+// from r, for example a byte slice from an artifact store. The JAR is written
+// to a temporary file, which is removed by Stop or Shutdown.
+func NewServerFromReader(r io.Reader, port string) (*Server, error) {
+	f, err := ioutil.TempFile("", "tika-server-*.jar")
+	if err != nil {
+		return nil, fmt.Errorf("error creating jar file: %v", err)
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("error writing jar file: %v", err)
+	}
+	s, err := NewServer(f.Name(), port)
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, err
+	}
+	s.tempJar = true
+	return s, nil
+}
+
+// NewServerFS is like NewServerFromReader, but reads the JAR named name from
+// fsys. This allows a binary to carry its own JAR with go:embed:
+//
+//	//go:embed tika-server-1.21.jar
+//	var jarFS embed.FS
+//
+//	s, err := tika.NewServerFS(jarFS, "tika-server-1.21.jar", "")
+func NewServerFS(fsys fs.FS, name, port string) (*Server, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewServerFromReader(f, port)
+}
+
+// cleanup stops the Unix socket proxy, if any, removes the private working
+// and temporary directories, if any, and removes the JAR of s if it was
+// created by NewServerFromReader.
+func (s *Server) cleanup() {
+	s.release()
+	if s.tempJar {
+		os.Remove(s.jar)
+	}
+}
+
+// release stops the Unix socket proxy, if any, and removes the private
+// working and temporary directories, if any, of a stopped process. Unlike
+// cleanup, it keeps the JAR, so s can be started again.
+func (s *Server) release() {
+	s.stopProxy()
+	s.removeTempDir()
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+		s.dir = ""
+	}
+}
+
+// freePort returns a port that is currently free on localhost.
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	return port, err
+}
+
+// Listen sets the host or IP address the server listens on, which is
+// localhost by default. URL is updated to match; if host is a wildcard
+// address such as 0.0.0.0, URL keeps using localhost.
+//
+// Tika Server parses untrusted input and has had remote code execution
+// vulnerabilities, so it should not be reachable from other machines unless
+// they are trusted. Listen returns an error for any host other than a
+// loopback address unless allowRemote is true.
+// If used, Listen must be called before starting the server.
+func (s *Server) Listen(host string, allowRemote bool) error {
+	if s.cmd != nil {
+		return fmt.Errorf("server process already started, cannot change the listen host")
+	}
+	if !allowRemote && !isLoopback(host) {
+		return fmt.Errorf("listening on non-loopback host %q exposes Tika Server to other machines; set allowRemote to allow it", host)
+	}
+	u, err := serverURL(host, s.port)
+	if err != nil {
+		return fmt.Errorf("invalid host %q: %v", host, err)
+	}
+	s.host = host
+	s.url = u
+	return nil
+}
+
+// serverURL returns the URL of a server listening on host and port. Clients
+// connect to localhost if host is empty or a wildcard address.
+func serverURL(host, port string) (string, error) {
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "localhost"
+	}
+	u, err := url.Parse("http://" + net.JoinHostPort(host, port))
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// ErrPortInUse is returned by Start when the port of the server is already
+// in use.
+var ErrPortInUse = errors.New("port already in use")
+
+// checkPort is overridden in tests, which run fake servers on the port.
+var checkPort = portFree
+
+// portFree returns an error wrapping ErrPortInUse if port cannot be bound on
+// host.
+func portFree(host, port string) error {
+	if host == "" {
+		host = "localhost"
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrPortInUse, port, err)
+	}
+	return l.Close()
+}
+
+// AutoIncrementPort makes Start try up to n following ports if the port of
+// the server is already in use, instead of returning ErrPortInUse. URL is
+// updated to the port that was chosen.
+// If used, AutoIncrementPort must be called before starting the server.
+func (s *Server) AutoIncrementPort(n int) error {
+	if s.cmd != nil {
+		return fmt.Errorf("server process already started, cannot change the port")
+	}
+	s.portTries = n
+	return nil
+}
+
+// reservePort checks that the port of s is free, moving to the next port as
+// allowed by AutoIncrementPort.
+func (s *Server) reservePort() error {
+	err := checkPort(s.host, s.port)
+	if err == nil || !errors.Is(err, ErrPortInUse) {
+		return err
+	}
+	p, convErr := strconv.Atoi(s.port)
+	if convErr != nil {
+		return err
+	}
+	for i := 1; i <= s.portTries; i++ {
+		port := strconv.Itoa(p + i)
+		if checkPort(s.host, port) != nil {
+			continue
+		}
+		u, urlErr := serverURL(s.host, port)
+		if urlErr != nil {
+			return urlErr
+		}
+		s.port = port
+		s.url = u
+		return nil
+	}
+	return err
+}
+
+// isLoopback reports whether host refers to the local machine only.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // ChildMode sets up the server to use the -spawnChild option.
 // If used, ChildMode must be called before starting the server.
 // If you want to turn off the -spawnChild option, call Server.ChildMode(nil).
@@ -129,113 +348,353 @@ func (s *Server) ChildMode(ops *ChildOptions) error {
 
 var command = exec.Command
 
+// javaPath returns the java executable in $JAVA_HOME/bin if it exists, or
+// the one found in PATH otherwise.
+func javaPath() string {
+	if home := os.Getenv("JAVA_HOME"); home != "" {
+		path := filepath.Join(home, "bin", javaExecutable)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return javaExecutable
+}
+
 // Start starts the given server. Start will start a new Java process. The
 // caller must call Stop() to shut down the process when finished with the
 // Server. Start will wait for the server to be available or until ctx is
-// cancelled.
+// cancelled, polling it every 500ms. See StartAndWaitReady to configure
+// polling.
+//
+// On Unix, the Java process is started in its own process group, so that
+// Stop and Shutdown also end the processes it forks, such as the child JVM
+// of ChildMode. On Linux, the Java process is also killed if the current
+// process dies without calling Stop.
 func (s *Server) Start(ctx context.Context) error {
+	return s.StartAndWaitReady(ctx, ReadinessOptions{})
+}
+
+// ReadinessOptions configure how StartAndWaitReady waits for the server to
+// respond to requests.
+type ReadinessOptions struct {
+	// PollInterval is the delay before the first probe and between probes.
+	// If less than or equal to 0, 500ms is used.
+	PollInterval time.Duration
+	// MaxPollInterval, if greater than PollInterval, enables exponential
+	// backoff: the delay between probes doubles after every failed probe, up
+	// to MaxPollInterval.
+	MaxPollInterval time.Duration
+	// Timeout, if greater than 0, is the maximum time to wait for the server
+	// to become ready, in addition to any deadline of the context.
+	Timeout time.Duration
+}
+
+// StartError is returned by Start and StartAndWaitReady when the server does
+// not become ready.
+type StartError struct {
+	// Err is the reason waiting stopped, usually a context error.
+	Err error
+	// LastProbe is the error returned by the last readiness probe, if any.
+	LastProbe error
+	// Stderr is the end of the standard error output of the Java process.
+	Stderr string
+}
+
+func (e *StartError) Error() string {
+	msg := fmt.Sprintf("error starting server: %v", e.Err)
+	if e.LastProbe != nil {
+		msg += fmt.Sprintf(" (last probe: %v)", e.LastProbe)
+	}
+	// Report stderr since sometimes the server says why it failed to start.
+	return msg + fmt.Sprintf("\nserver stderr:\n\n%s", e.Stderr)
+}
+
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// stderrTail is the amount of standard error output kept for StartError.
+const stderrTail = 8 << 10
+
+// StartAndWaitReady is like Start, but waits for the server to become ready
+// as configured by opts. If the server does not become ready, the Java
+// process is killed and a *StartError is returned. If it reports an
+// unexpected version (see ExpectVersion), the process is killed and a
+// *VersionMismatchError is returned.
+func (s *Server) StartAndWaitReady(ctx context.Context, opts ReadinessOptions) error {
+	if s.adopted {
+		if err := s.waitForStart(ctx, opts); err != nil {
+			return err
+		}
+		return s.checkVersion(ctx)
+	}
 	if _, err := os.Stat(s.jar); os.IsNotExist(err) {
 		return err
 	}
+	if err := s.reservePort(); err != nil {
+		return err
+	}
 
-	// Create a slice of Java system properties to be passed to the JVM.
-	props := []string{}
+	// Create a slice of Java system properties to be passed to the JVM. The
+	// arguments are not passed through a shell, so they must not be quoted;
+	// os/exec escapes them as needed on Windows.
+	props := append([]string{}, s.JavaOptions...)
 	for k, v := range s.JavaProps {
-		props = append(props, fmt.Sprintf("-D%s=%q", k, v))
+		props = append(props, fmt.Sprintf("-D%s=%s", k, v))
+	}
+	tmpProp, err := s.createTempDir()
+	if err != nil {
+		return err
+	}
+	if tmpProp != "" {
+		props = append(props, tmpProp)
 	}
 
-	args := append(append(props, "-jar", s.jar, "-p", s.port), s.child.args()...)
-	cmd := command("java", args...)
+	jar := s.jar
+	if s.sandbox != nil && s.sandbox.PrivateDir {
+		// The working directory changes, so relative paths no longer work.
+		abs, err := filepath.Abs(jar)
+		if err != nil {
+			s.abort()
+			return err
+		}
+		jar = abs
+	}
+	args := append(props, "-jar", jar, "-p", s.port)
+	if s.host != "" {
+		args = append(args, "-h", s.host)
+	}
+	args = append(args, s.child.args()...)
+	cmd := command(javaPath(), args...)
+	stderr := &tailBuffer{max: stderrTail}
+	cmd.Stderr = stderr
+	if s.output != nil {
+		cmd.Stdout = s.output
+		cmd.Stderr = io.MultiWriter(stderr, s.output)
+	}
+	s.dir, err = s.sandbox.prepare(cmd)
+	if err != nil {
+		s.abort()
+		return err
+	}
+	newProcessGroup(cmd)
 
 	if err := cmd.Start(); err != nil {
+		s.abort()
 		return err
 	}
 	s.cmd = cmd
+	s.started(cmd.Process.Pid)
+	if err := s.sandbox.join(cmd.Process); err != nil {
+		s.abort()
+		return err
+	}
 
-	if err := s.waitForStart(ctx); err != nil {
-		out, readErr := cmd.CombinedOutput()
-		if readErr != nil {
-			return fmt.Errorf("error reading output: %v", readErr)
+	if err := s.waitForStart(ctx, opts); err != nil {
+		s.abort()
+		if serr, ok := err.(*StartError); ok {
+			serr.Stderr = stderr.String()
 		}
-		// Report stderr since sometimes the server says why it failed to start.
-		return fmt.Errorf("error starting server: %v\nserver stderr:\n\n%s", err, out)
+		return err
+	}
+	if err := s.checkVersion(ctx); err != nil {
+		s.abort()
+		return err
+	}
+	s.startSweeper()
+	if err := s.startProxy(); err != nil {
+		s.abort()
+		return err
 	}
 	return nil
 }
 
+// abort kills the Java process, if any, and removes the directories created
+// by a failed Start.
+func (s *Server) abort() {
+	if s.cmd != nil {
+		killProcessGroup(s.cmd.Process)
+		s.cmd.Wait()
+		s.exited(s.cmd.ProcessState)
+		s.cmd = nil
+	}
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+		s.dir = ""
+	}
+	s.removeTempDir()
+}
+
 // waitForServer waits until the given Server is responding to requests or
-// ctx is Done().
-func (s Server) waitForStart(ctx context.Context) error {
+// ctx is Done(), probing it as configured by opts.
+func (s *Server) waitForStart(ctx context.Context, opts ReadinessOptions) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
 	c := NewClient(nil, s.url)
-	t := time.NewTicker(500 * time.Millisecond)
+	var lastErr error
+	t := time.NewTimer(interval)
 	defer t.Stop()
 	for {
 		select {
 		case <-t.C:
-			if _, err := c.Version(ctx); err == nil {
+			_, err := c.Version(ctx)
+			if err == nil {
 				return nil
 			}
+			lastErr = err
+			if opts.MaxPollInterval > interval {
+				interval *= 2
+				if interval > opts.MaxPollInterval {
+					interval = opts.MaxPollInterval
+				}
+			}
+			t.Reset(interval)
 		case <-ctx.Done():
-			return ctx.Err()
+			return &StartError{Err: ctx.Err(), LastProbe: lastErr}
 		}
 	}
 }
 
+// tailBuffer is an io.Writer that keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	b   []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.b = append(t.b, p...)
+	if len(t.b) > t.max {
+		t.b = append(t.b[:0], t.b[len(t.b)-t.max:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.b)
+}
+
 // Stop shuts the server down, killing the underlying Java process. Stop
 // must be called when finished with the server to avoid leaking the
 // Java process. If s has not been started, Stop will panic.
-// If not running in a Windows environment, it is recommended to use Shutdown
-// for a more graceful shutdown of the Java process.
+// The whole process group of the Java process is killed; on Windows, the
+// whole process tree is killed with taskkill.
+// It is recommended to use Shutdown for a more graceful shutdown of the Java
+// process.
 func (s *Server) Stop() error {
-	if err := s.cmd.Process.Kill(); err != nil {
+	if s.adopted {
+		return s.stopAdopted(killProcess)
+	}
+	defer s.cleanup()
+	if err := killProcessGroup(s.cmd.Process); err != nil {
 		return fmt.Errorf("could not kill server: %v", err)
 	}
-	if err := s.cmd.Wait(); err != nil {
+	err := s.cmd.Wait()
+	s.exited(s.cmd.ProcessState)
+	if err != nil {
 		return fmt.Errorf("could not wait for server to finish: %v", err)
 	}
 	return nil
 }
 
+// interruptServer asks the process group led by p to exit. It is a variable
+// for testing.
+var interruptServer = interruptProcessGroup
+
 // Shutdown attempts to close the server gracefully before using SIGKILL,
 // Stop() uses SIGKILL right away, which causes the kernal to stop the java process instantly.
+// On Windows, taskkill is used instead of signals. If the process cannot be
+// asked to exit, as a console java.exe on Windows, which has no window to
+// receive the request of taskkill, or once ctx is done, it is killed.
+// Shutdown returns once the process has exited.
 func (s *Server) Shutdown(ctx context.Context) error {
-	if err := s.cmd.Process.Signal(os.Interrupt); err != nil {
-		return fmt.Errorf("could not interrupt server: %v", err)
+	if s.adopted {
+		return s.shutdownAdopted(ctx)
 	}
-	errChannel := make(chan error)
-	go func() {
+	defer s.cleanup()
+	done := make(chan error, 1)
+	go func() { done <- s.cmd.Wait() }()
+	if interruptServer(s.cmd.Process) == nil {
 		select {
-		case errChannel <- s.cmd.Wait():
+		case err := <-done:
+			s.exited(s.cmd.ProcessState)
+			if err != nil {
+				return fmt.Errorf("could not wait for server to finish: %v", err)
+			}
+			return nil
 		case <-ctx.Done():
 		}
-	}()
-	select {
-	case err := <-errChannel:
-		if err != nil {
-			return fmt.Errorf("could not wait for server to finish: %v", err)
-		}
-	case <-ctx.Done():
-		if err := s.cmd.Process.Kill(); err != nil {
-			return fmt.Errorf("could not kill server: %v", err)
-		}
 	}
+	if err := killProcessGroup(s.cmd.Process); err != nil {
+		return fmt.Errorf("could not kill server: %v", err)
+	}
+	<-done
+	s.exited(s.cmd.ProcessState)
 	return nil
 }
 
 func sha512Hash(path string) (string, error) {
+	return fileHash(path, crypto.SHA512)
+}
+
+// fileHash returns the hex-encoded hash of the file at path.
+func fileHash(path string, hash crypto.Hash) (string, error) {
+	if !hash.Available() {
+		return "", fmt.Errorf("hash function %v is not available", hash)
+	}
 	f, err := os.Open(path)
 	if err != nil {
 		return "", err
 	}
 	defer f.Close()
 
-	h := sha512.New()
+	h := hash.New()
 	if _, err := io.Copy(h, f); err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
+// A Checksum is the expected hash of a file.
+type Checksum struct {
+	// Hash is the hash function, such as crypto.SHA256. Its package must be
+	// linked into the binary; crypto/sha256 and crypto/sha512 always are.
+	Hash crypto.Hash
+	// Sum is the hex-encoded hash.
+	Sum string
+}
+
+// SHA256 returns the Checksum with the given hex-encoded SHA-256 hash.
+func SHA256(sum string) Checksum {
+	return Checksum{Hash: crypto.SHA256, Sum: sum}
+}
+
+// SHA512 returns the Checksum with the given hex-encoded SHA-512 hash.
+func SHA512(sum string) Checksum {
+	return Checksum{Hash: crypto.SHA512, Sum: sum}
+}
+
+// verify reports whether the file at path matches c. It returns the actual
+// hash if not.
+func (c Checksum) verify(path string) (string, bool, error) {
+	got, err := fileHash(path, c.Hash)
+	if err != nil {
+		return "", false, err
+	}
+	return got, strings.EqualFold(got, strings.TrimSpace(c.Sum)), nil
+}
+
 // A Version represents a Tika Server version.
 type Version string
 
@@ -246,7 +705,9 @@ const (
 	Version121 Version = "1.21"
 )
 
-// Versions is a list of supported versions of Apache Tika.
+// Versions is a list of supported versions of Apache Tika, oldest first.
+// Prefer SupportedVersions, which returns a copy, and ParseVersion, which
+// validates version strings against this list.
 var Versions = []Version{Version119, Version120, Version121}
 
 var sha512s = map[Version]string{
@@ -255,6 +716,32 @@ var sha512s = map[Version]string{
 	Version121: "e705c836b2110530c8d363d05da27f65c4f6c9051b660cefdae0e5113c365dbabed2aa1e4171c8e52dbe4cbaa085e3d8a01a5a731e344942c519b85836da646c",
 }
 
+// Mirrors from which Tika Server JARs can be downloaded. Every occurrence of
+// "{version}" is replaced by the Version being downloaded.
+const (
+	MavenCentralMirror  = "https://repo1.maven.org/maven2/org/apache/tika/tika-server/{version}/tika-server-{version}.jar"
+	ApacheArchiveMirror = "https://archive.apache.org/dist/tika/tika-server-{version}.jar"
+)
+
+// DefaultMirrors is the list of mirrors used by a Downloader with no Mirrors.
+var DefaultMirrors = []string{MavenCentralMirror, ApacheArchiveMirror}
+
+// A Downloader downloads and validates Tika Server JARs. The zero value is
+// ready to use.
+type Downloader struct {
+	// Progress, if not nil, is called as the JAR is downloaded.
+	Progress func(Progress)
+	// Mirrors is the list of mirror URLs to try, in order. See
+	// MavenCentralMirror for the format. If empty, DefaultMirrors is used.
+	Mirrors []string
+	// HTTPClient is used to download the JAR. If nil, http.DefaultClient is
+	// used.
+	HTTPClient *http.Client
+	// AdvisoryPolicy controls what happens when the version has critical
+	// Advisories. By default, they are logged.
+	AdvisoryPolicy AdvisoryPolicy
+}
+
 // DownloadServer downloads and validates the given server version,
 // saving it at path. DownloadServer returns an error if it could
 // not be downloaded/validated.
@@ -262,42 +749,144 @@ var sha512s = map[Version]string{
 // If the file already exists and has the correct sha512, DownloadServer will
 // do nothing.
 func DownloadServer(ctx context.Context, v Version, path string) error {
+	var d Downloader
+	return d.Download(ctx, v, path)
+}
+
+// Download is like DownloadServer, but uses the configuration of d. Each
+// mirror is tried in turn until one succeeds. The JAR is written to path with
+// a ".part" suffix and only renamed to path once validated, so a partially
+// written JAR never exists at path. If a download is interrupted, the next
+// call to Download resumes it where it stopped.
+func (d *Downloader) Download(ctx context.Context, v Version, path string) error {
 	hash := sha512s[v]
 	if hash == "" {
 		return fmt.Errorf("unsupported Tika version: %s", v)
 	}
-	if got, err := sha512Hash(path); err == nil {
-		if got == hash {
+	if err := checkAdvisories(v, d.AdvisoryPolicy); err != nil {
+		return err
+	}
+	sum := SHA512(hash)
+	if _, ok, err := sum.verify(path); err == nil && ok {
+		return nil
+	}
+	mirrors := d.Mirrors
+	if len(mirrors) == 0 {
+		mirrors = DefaultMirrors
+	}
+	var errs []string
+	for _, m := range mirrors {
+		url := strings.ReplaceAll(m, "{version}", string(v))
+		err := d.fetch(ctx, url, path, sum)
+		if err == nil {
 			return nil
 		}
+		if ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, err.Error())
+	}
+	return fmt.Errorf("unable to download Tika Server %s: %s", v, strings.Join(errs, "; "))
+}
+
+// DownloadServerChecksum downloads the Tika Server JAR at url, saving it at
+// path, and validates it against sum. Unlike DownloadServer, it does not need
+// a known Version, so it can be used with internal mirrors and artifact
+// stores, or with versions newer than this package. If the file already
+// exists and matches sum, DownloadServerChecksum does nothing.
+func DownloadServerChecksum(ctx context.Context, url, path string, sum Checksum) error {
+	var d Downloader
+	return d.DownloadURL(ctx, url, path, sum)
+}
+
+// DownloadURL is like DownloadServerChecksum, but uses the configuration of d.
+// Mirrors and AdvisoryPolicy are not used.
+func (d *Downloader) DownloadURL(ctx context.Context, url, path string, sum Checksum) error {
+	if sum.Sum == "" {
+		return errors.New("missing checksum")
+	}
+	if _, ok, err := sum.verify(path); err == nil && ok {
+		return nil
 	}
-	out, err := os.Create(path)
+	return d.fetch(ctx, url, path, sum)
+}
+
+// fetch downloads url to path, resuming a partial download if one exists, and
+// validates the result against sum.
+func (d *Downloader) fetch(ctx context.Context, url, path string, sum Checksum) error {
+	tmp := path + ".part"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return fmt.Errorf("error creating file: %v", err)
 	}
 	defer out.Close()
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("error reading partial download: %v", err)
+	}
 
-	url := fmt.Sprintf("http://search.maven.org/remotecontent?filepath=org/apache/tika/tika-server/%s/tika-server-%s.jar", v, v)
-	resp, err := ctxhttp.Get(ctx, nil, url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("unable to download %q: %v", url, err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	client := d.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("unable to download %q: %v", url, err)
 	}
 	defer resp.Body.Close()
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		return fmt.Errorf("error saving download: %v", err)
+	complete := false
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The mirror ignored the range, so start over.
+		if err := out.Truncate(0); err != nil {
+			return fmt.Errorf("error truncating partial download: %v", err)
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("error truncating partial download: %v", err)
+		}
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial download is already complete.
+		complete = true
+	default:
+		return fmt.Errorf("unable to download %q: %v", url, ClientError{StatusCode: resp.StatusCode})
 	}
 
-	h, err := sha512Hash(path)
+	if !complete {
+		var body io.Reader = resp.Body
+		if d.Progress != nil {
+			total := int64(-1)
+			if resp.ContentLength >= 0 {
+				total = offset + resp.ContentLength
+			}
+			body = &progressReader{r: resp.Body, fn: d.Progress, p: Progress{Bytes: offset, Total: total}}
+		}
+		if _, err := io.Copy(out, body); err != nil {
+			return fmt.Errorf("error saving download: %v", err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("error saving download: %v", err)
+	}
 
+	h, ok, err := sum.verify(tmp)
 	if err != nil {
 		return err
 	}
-	if h != hash {
-		if err := os.Remove(path); err != nil {
-			return fmt.Errorf("invalid sha512: %s: error removing %s: %v", h, path, err)
+	if !ok {
+		if err := os.Remove(tmp); err != nil {
+			return fmt.Errorf("invalid %v: %s: error removing %s: %v", sum.Hash, h, tmp, err)
 		}
-		return fmt.Errorf("invalid sha512: %s", h)
+		return fmt.Errorf("invalid %v: %s", sum.Hash, h)
 	}
-	return nil
+	return os.Rename(tmp, path)
 }
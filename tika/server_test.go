@@ -17,15 +17,25 @@ limitations under the License.
 package tika
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -37,6 +47,8 @@ func init() {
 		c.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
 		return c
 	}
+	// The fake Tika servers used by the tests listen on the server port.
+	checkPort = func(string, string) error { return nil }
 }
 
 func TestNewServerError(t *testing.T) {
@@ -81,6 +93,88 @@ func TestStart(t *testing.T) {
 	s.Stop()
 }
 
+func TestNewServerFreePort(t *testing.T) {
+	path, err := os.Executable()
+	if err != nil {
+		t.Skip("cannot find current test executable")
+	}
+	s, err := NewServer(path, "0")
+	if err != nil {
+		t.Fatalf("NewServer got error: %v", err)
+	}
+	if p, err := strconv.Atoi(s.port); err != nil || p == 0 {
+		t.Errorf("NewServer(%q) chose port %q, want a free port", "0", s.port)
+	}
+	if want := "http://localhost:" + s.port; s.URL() != want {
+		t.Errorf("URL() = %q, want %q", s.URL(), want)
+	}
+}
+
+func TestListen(t *testing.T) {
+	tests := []struct {
+		host        string
+		allowRemote bool
+		wantURL     string
+		wantErr     bool
+	}{
+		{host: "127.0.0.1", wantURL: "http://127.0.0.1:9998"},
+		{host: "::1", wantURL: "http://[::1]:9998"},
+		{host: "localhost", wantURL: "http://localhost:9998"},
+		{host: "0.0.0.0", wantErr: true},
+		{host: "0.0.0.0", allowRemote: true, wantURL: "http://localhost:9998"},
+		{host: "tika.example.com", allowRemote: true, wantURL: "http://tika.example.com:9998"},
+	}
+	for _, test := range tests {
+		s := &Server{port: "9998"}
+		err := s.Listen(test.host, test.allowRemote)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Listen(%q, %v) got no error, want an error", test.host, test.allowRemote)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Listen(%q, %v) got error: %v", test.host, test.allowRemote, err)
+			continue
+		}
+		if s.URL() != test.wantURL {
+			t.Errorf("Listen(%q, %v) set URL %q, want %q", test.host, test.allowRemote, s.URL(), test.wantURL)
+		}
+	}
+}
+
+func TestNewServerFS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "1.14")
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("error creating test server: %v", err)
+	}
+
+	fsys := fstest.MapFS{"tika.jar": &fstest.MapFile{Data: []byte("jar")}}
+	s, err := NewServerFS(fsys, "tika.jar", tsURL.Port())
+	if err != nil {
+		t.Fatalf("NewServerFS got error: %v", err)
+	}
+	got, err := ioutil.ReadFile(s.jar)
+	if err != nil || string(got) != "jar" {
+		t.Errorf("NewServerFS wrote %q, %v, want %q", got, err, "jar")
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	s.Stop()
+	if _, err := os.Stat(s.jar); !os.IsNotExist(err) {
+		t.Errorf("Stop did not remove the temporary jar: %v", err)
+	}
+
+	if _, err := NewServerFS(fsys, "missing.jar", ""); err == nil {
+		t.Error("NewServerFS with a missing jar got no error")
+	}
+}
+
 func bouncyServer(bounce int) *httptest.Server {
 	bounced := 0
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -150,7 +244,7 @@ func TestWaitForStart(t *testing.T) {
 			s := &Server{url: ts.URL}
 			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
 			defer cancel()
-			got := s.waitForStart(ctx)
+			got := s.waitForStart(ctx, ReadinessOptions{})
 			if test.wantError && got == nil {
 				t.Errorf("waitForStart(%s) got no error, want error", test.name)
 			}
@@ -165,6 +259,142 @@ func TestWaitForStart(t *testing.T) {
 	}
 }
 
+func TestStartAndWaitReadyError(t *testing.T) {
+	oldCommand := command
+	defer func() { command = oldCommand }()
+	command = func(string, ...string) *exec.Cmd {
+		c := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "stderr", "port in use", "sleep", "2")
+		c.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return c
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		t.Skip("cannot find current test executable")
+	}
+	var probes int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("error creating test server: %v", err)
+	}
+	s, err := NewServer(path, tsURL.Port())
+	if err != nil {
+		t.Fatalf("NewServer got error: %v", err)
+	}
+
+	opts := ReadinessOptions{
+		PollInterval:    10 * time.Millisecond,
+		MaxPollInterval: 40 * time.Millisecond,
+		Timeout:         time.Second,
+	}
+	err = s.StartAndWaitReady(context.Background(), opts)
+	var serr *StartError
+	if !errors.As(err, &serr) {
+		t.Fatalf("StartAndWaitReady got error %v, want a *StartError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StartAndWaitReady got error %v, want %v", serr.Err, context.DeadlineExceeded)
+	}
+	if ce, ok := serr.LastProbe.(ClientError); !ok || ce.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("StartAndWaitReady got last probe error %v, want response code %d", serr.LastProbe, http.StatusServiceUnavailable)
+	}
+	if serr.Stderr != "port in use" {
+		t.Errorf("StartAndWaitReady got stderr %q, want %q", serr.Stderr, "port in use")
+	}
+	// With backoff capped at 40ms, a 1s budget allows roughly 25 probes.
+	if n := atomic.LoadInt32(&probes); n < 5 || n > 40 {
+		t.Errorf("StartAndWaitReady probed %d times, want about 25", n)
+	}
+	if s.cmd != nil {
+		t.Error("StartAndWaitReady did not stop the process after failing")
+	}
+}
+
+func TestReservePort(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer l.Close()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	if err != nil {
+		t.Fatalf("SplitHostPort: %v", err)
+	}
+
+	oldCheck := checkPort
+	checkPort = portFree
+	defer func() { checkPort = oldCheck }()
+
+	s := &Server{port: port}
+	if err := s.reservePort(); !errors.Is(err, ErrPortInUse) {
+		t.Errorf("reservePort on a used port got error %v, want %v", err, ErrPortInUse)
+	}
+	if err := s.AutoIncrementPort(10); err != nil {
+		t.Fatalf("AutoIncrementPort got error: %v", err)
+	}
+	if err := s.reservePort(); err != nil {
+		t.Fatalf("reservePort with AutoIncrementPort got error: %v", err)
+	}
+	if s.port == port {
+		t.Errorf("reservePort kept used port %s", port)
+	}
+	if want := "http://localhost:" + s.port; s.URL() != want {
+		t.Errorf("reservePort set URL %q, want %q", s.URL(), want)
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{max: 5}
+	fmt.Fprint(b, "abc")
+	fmt.Fprint(b, "defg")
+	if got := b.String(); got != "cdefg" {
+		t.Errorf("tailBuffer kept %q, want %q", got, "cdefg")
+	}
+}
+
+func TestServerShutdownInterruptFailure(t *testing.T) {
+	path, err := os.Executable()
+	if err != nil {
+		t.Skip("cannot find current test executable")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "1.14")
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("error creating test server: %v", err)
+	}
+	// Like a console java.exe on Windows, which taskkill cannot close.
+	defer func(f func(*os.Process) error) { interruptServer = f }(interruptServer)
+	interruptServer = func(*os.Process) error { return errors.New("no window") }
+
+	s, err := NewServer(path, tsURL.Port())
+	if err != nil {
+		t.Fatalf("NewServer got error: %v", err)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown got error: %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Shutdown took %v, want the process killed without waiting for the context", d)
+	}
+	if s.cmd.ProcessState == nil {
+		t.Errorf("Shutdown returned before the process exited")
+	}
+}
+
 // TestHelperProcess isn't a real test. It's used as a helper process
 // for TestParameterRun.
 // Adapted from os/exec/exec_test.go.
@@ -182,6 +412,20 @@ func TestHelperProcess(*testing.T) {
 		}
 		args = args[1:]
 	}
+	if args[0] == "stderr" {
+		fmt.Fprint(os.Stderr, args[1])
+		args = args[2:]
+	}
+	if args[0] == "fork" {
+		// Start a grandchild which outlives this process unless killed.
+		c := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep", "10")
+		c.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		if err := c.Start(); err != nil {
+			os.Exit(1)
+		}
+		fmt.Println(c.Process.Pid)
+		args = args[1:]
+	}
 	if args[0] == "sleep" {
 		l, err := strconv.Atoi(args[1])
 		if err != nil {
@@ -230,6 +474,37 @@ func TestDownloadServerError(t *testing.T) {
 	}
 }
 
+func TestJavaPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "java")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	oldHome, hadHome := os.LookupEnv("JAVA_HOME")
+	defer func() {
+		if hadHome {
+			os.Setenv("JAVA_HOME", oldHome)
+		} else {
+			os.Unsetenv("JAVA_HOME")
+		}
+	}()
+
+	os.Setenv("JAVA_HOME", dir)
+	if got := javaPath(); got != javaExecutable {
+		t.Errorf("javaPath() with no java in JAVA_HOME = %q, want %q", got, javaExecutable)
+	}
+	java := filepath.Join(dir, "bin", javaExecutable)
+	if err := os.MkdirAll(filepath.Dir(java), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := ioutil.WriteFile(java, nil, 0755); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if got := javaPath(); got != java {
+		t.Errorf("javaPath() = %q, want %q", got, java)
+	}
+}
+
 func TestAddJavaProps(t *testing.T) {
 	oldCommand := command
 	defer func() { command = oldCommand }()
@@ -258,7 +533,7 @@ func TestAddJavaProps(t *testing.T) {
 
 	command = func(c string, args ...string) *exec.Cmd {
 		found := false
-		want := fmt.Sprintf("-D%s=%q", wantKey, wantVal)
+		want := fmt.Sprintf("-D%s=%s", wantKey, wantVal)
 		for _, arg := range args {
 			if arg == want {
 				found = true
@@ -274,3 +549,118 @@ func TestAddJavaProps(t *testing.T) {
 		t.Errorf("Start got error: %v", err)
 	}
 }
+
+func TestDownloaderResume(t *testing.T) {
+	content := bytes.Repeat([]byte("tika server jar "), 1024)
+	h := sha512.Sum512(content)
+	v := Version("test")
+	sha512s[v] = fmt.Sprintf("%x", h)
+	defer delete(sha512s, v)
+
+	var gotRange string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/good/test.jar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		gotRange = r.Header.Get("Range")
+		http.ServeContent(w, r, "test.jar", time.Time{}, bytes.NewReader(content))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tika-server.jar")
+	half := len(content) / 2
+	if err := ioutil.WriteFile(path+".part", content[:half], 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	var last Progress
+	d := &Downloader{
+		Mirrors:  []string{ts.URL + "/bad/{version}.jar", ts.URL + "/good/{version}.jar"},
+		Progress: func(p Progress) { last = p },
+	}
+	if err := d.Download(context.Background(), v, path); err != nil {
+		t.Fatalf("Download got error: %v", err)
+	}
+	if want := fmt.Sprintf("bytes=%d-", half); gotRange != want {
+		t.Errorf("Download sent Range %q, want %q", gotRange, want)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Download wrote %d bytes, want %d bytes of content", len(got), len(content))
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Errorf("Download left the partial file behind: %v", err)
+	}
+	if want := (Progress{Bytes: int64(len(content)), Total: int64(len(content))}); last != want {
+		t.Errorf("Download last reported %+v, want %+v", last, want)
+	}
+}
+
+func TestDownloaderInvalidHash(t *testing.T) {
+	v := Version("test")
+	sha512s[v] = "invalid"
+	defer delete(sha512s, v)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "not a jar")
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "download")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tika-server.jar")
+	d := &Downloader{Mirrors: []string{ts.URL}}
+	if err := d.Download(context.Background(), v, path); err == nil {
+		t.Fatal("Download got no error, want an error")
+	}
+	for _, p := range []string{path, path + ".part"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("Download left %s behind: %v", p, err)
+		}
+	}
+}
+
+func TestDownloadServerChecksum(t *testing.T) {
+	content := []byte("tika server jar")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(content)
+	}))
+	defer ts.Close()
+	sha256Sum := fmt.Sprintf("%x", sha256.Sum256(content))
+	sha512Sum := fmt.Sprintf("%x", sha512.Sum512(content))
+	tests := []struct {
+		name    string
+		sum     Checksum
+		wantErr bool
+	}{
+		{name: "sha256", sum: SHA256(sha256Sum)},
+		{name: "sha256 upper case", sum: SHA256(fmt.Sprintf("%X", sha256.Sum256(content)))},
+		{name: "sha512", sum: SHA512(sha512Sum)},
+		{name: "wrong sum", sum: SHA256(sha512Sum), wantErr: true},
+		{name: "missing sum", sum: Checksum{Hash: crypto.SHA256}, wantErr: true},
+		{name: "unavailable hash", sum: Checksum{Hash: crypto.MD4, Sum: "00"}, wantErr: true},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "tika-server.jar")
+		err := DownloadServerChecksum(context.Background(), ts.URL, path, test.sum)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("DownloadServerChecksum(%s) got error %v, want error %v", test.name, err, test.wantErr)
+			continue
+		}
+		_, statErr := os.Stat(path)
+		if test.wantErr != os.IsNotExist(statErr) {
+			t.Errorf("DownloadServerChecksum(%s) left file state %v", test.name, statErr)
+		}
+	}
+}
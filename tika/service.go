@@ -0,0 +1,287 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServiceOptions configure RunService.
+type ServiceOptions struct {
+	// PIDFile, if not empty, is the path of a file holding the process ID of
+	// the Java process while it runs. RunService refuses to start if the file
+	// names a running process.
+	PIDFile string
+	// Output, if not nil, receives the standard output and standard error of
+	// the Java process. See RotatingFile.
+	Output io.Writer
+	// MaxRestarts is the number of times in a row the Java process is
+	// restarted after it fails. If negative, it is always restarted. The
+	// count is reset once the process has run for a minute.
+	MaxRestarts int
+	// RestartDelay is the delay before the first restart, doubling after
+	// each failure in a row up to a minute. If less than or equal to 0, 1s is
+	// used.
+	RestartDelay time.Duration
+	// Readiness configures how every start waits for the server.
+	Readiness ReadinessOptions
+	// StopTimeout is how long the Java process is given to exit gracefully
+	// when ctx is done before it is killed. If less than or equal to 0, 10s
+	// is used.
+	StopTimeout time.Duration
+	// Restart, if not nil, restarts the Java process gracefully whenever it
+	// receives, for example to pick up changes to JavaOptions or JavaProps.
+	// Such restarts do not count towards MaxRestarts.
+	Restart <-chan struct{}
+}
+
+// stableRun is how long a process must run for its failure not to count
+// towards ServiceOptions.MaxRestarts.
+const stableRun = time.Minute
+
+// RunService runs s as a long-running service until ctx is done: it starts
+// the server, restarts it when the Java process exits as configured by opts,
+// and shuts it down gracefully once ctx is done, returning nil. It returns an
+// error if the server cannot be started the first time, or fails more than
+// opts.MaxRestarts times in a row; a restart that fails to start counts as a
+// failure.
+//
+// RunService is meant for programs run by a service manager such as systemd;
+// see the "server" action of the tika command.
+func (s *Server) RunService(ctx context.Context, opts ServiceOptions) error {
+	if opts.PIDFile != "" {
+		if pid, err := readPIDFile(opts.PIDFile); err == nil && processAlive(pid) {
+			return fmt.Errorf("server already running with PID %d (%s)", pid, opts.PIDFile)
+		}
+	}
+	delay := opts.RestartDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+	s.output = opts.Output
+	defer s.cleanup()
+	failures := 0
+	// retry counts a failure, waiting before the next start. It returns
+	// false if RunService must return instead, because ctx is done or there
+	// were too many failures in a row.
+	retry := func() bool {
+		if opts.MaxRestarts >= 0 && failures >= opts.MaxRestarts {
+			return false
+		}
+		wait := delay << uint(failures)
+		if wait > stableRun || wait <= 0 {
+			wait = stableRun
+		}
+		failures++
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-t.C:
+			return true
+		}
+	}
+	for restarting := false; ; restarting = true {
+		if err := s.StartAndWaitReady(ctx, opts.Readiness); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !restarting {
+				return err
+			}
+			if retry() {
+				continue
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("server failed to restart: %v", err)
+		}
+		if opts.PIDFile != "" {
+			if err := writePIDFile(opts.PIDFile, s.cmd.Process.Pid); err != nil {
+				s.abort()
+				return err
+			}
+		}
+		started := time.Now()
+		exited := make(chan error, 1)
+		cmd := s.cmd
+		go func() { exited <- cmd.Wait() }()
+
+		var exitErr error
+		select {
+		case <-ctx.Done():
+			s.stopService(exited, opts.StopTimeout)
+			removePIDFile(opts.PIDFile)
+			return nil
+		case <-opts.Restart:
+			s.stopService(exited, opts.StopTimeout)
+			s.release()
+			removePIDFile(opts.PIDFile)
+			continue
+		case exitErr = <-exited:
+		}
+		s.exited(cmd.ProcessState)
+		s.cmd = nil
+		s.release()
+		removePIDFile(opts.PIDFile)
+
+		if time.Since(started) >= stableRun {
+			failures = 0
+		}
+		if !retry() {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("server exited: %v", exitErr)
+		}
+	}
+}
+
+// stopService interrupts the Java process, waiting for it to send on exited,
+// and kills it if it does not exit within timeout.
+func (s *Server) stopService(exited <-chan error, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	interruptProcessGroup(s.cmd.Process)
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-exited:
+	case <-t.C:
+		killProcessGroup(s.cmd.Process)
+		<-exited
+	}
+	s.exited(s.cmd.ProcessState)
+	s.cmd = nil
+}
+
+func readPIDFile(path string) (int, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+func writePIDFile(path string, pid int) error {
+	if err := ioutil.WriteFile(path, []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+		return fmt.Errorf("error writing PID file: %v", err)
+	}
+	return nil
+}
+
+func removePIDFile(path string) {
+	if path != "" {
+		os.Remove(path)
+	}
+}
+
+// A RotatingFile is an io.Writer appending to the file at Path, which is
+// rotated once it reaches MaxBytes: Path is renamed Path.1, Path.1 is renamed
+// Path.2, and so on, keeping at most MaxFiles old files. It is safe for
+// concurrent use.
+type RotatingFile struct {
+	// Path is the path of the file.
+	Path string
+	// MaxBytes is the size at which the file is rotated. If less than or
+	// equal to 0, the file is never rotated.
+	MaxBytes int64
+	// MaxFiles is the number of rotated files to keep.
+	MaxFiles int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// Write appends p to the file, rotating it first if it would grow past
+// MaxBytes.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.MaxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.MaxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, fi.Size()
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+	if r.MaxFiles <= 0 {
+		if err := os.Remove(r.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return r.open()
+	}
+	os.Remove(fmt.Sprintf("%s.%d", r.Path, r.MaxFiles))
+	for i := r.MaxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.Path, i), fmt.Sprintf("%s.%d", r.Path, i+1))
+	}
+	if err := os.Rename(r.Path, r.Path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
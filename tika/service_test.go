@@ -0,0 +1,210 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serviceServer returns a Server whose fake Java process runs for the given
+// number of seconds, and whose fake Tika server is always ready.
+func serviceServer(t *testing.T, seconds string) *Server {
+	t.Helper()
+	path, err := os.Executable()
+	if err != nil {
+		t.Skip("cannot find current test executable")
+	}
+	oldCommand := command
+	t.Cleanup(func() { command = oldCommand })
+	command = func(string, ...string) *exec.Cmd {
+		c := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--", "sleep", seconds)
+		c.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		return c
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "1.14")
+	}))
+	t.Cleanup(ts.Close)
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("error creating test server: %v", err)
+	}
+	s, err := NewServer(path, tsURL.Port())
+	if err != nil {
+		t.Fatalf("NewServer got error: %v", err)
+	}
+	return s
+}
+
+func TestRunServiceStop(t *testing.T) {
+	s := serviceServer(t, "10")
+	pidFile := filepath.Join(t.TempDir(), "tika.pid")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.RunService(ctx, ServiceOptions{
+			PIDFile:   pidFile,
+			Readiness: ReadinessOptions{PollInterval: 10 * time.Millisecond},
+		})
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := readPIDFile(pidFile); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("RunService did not write the PID file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("RunService got error: %v", err)
+	}
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Errorf("RunService left the PID file behind: %v", err)
+	}
+}
+
+func TestRunServiceRestart(t *testing.T) {
+	s := serviceServer(t, "0")
+	start := time.Now()
+	err := s.RunService(context.Background(), ServiceOptions{
+		MaxRestarts:  2,
+		RestartDelay: 10 * time.Millisecond,
+		Readiness:    ReadinessOptions{PollInterval: 10 * time.Millisecond},
+	})
+	if err == nil || !strings.Contains(err.Error(), "server exited") {
+		t.Errorf("RunService got error %v, want the server to have exited", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("RunService took %v to give up", d)
+	}
+}
+
+func TestRunServiceRestartStartFailure(t *testing.T) {
+	s := serviceServer(t, "0")
+	starts := 0
+	helper := command
+	command = func(name string, args ...string) *exec.Cmd {
+		starts++
+		if starts > 1 {
+			return exec.Command(filepath.Join(t.TempDir(), "missing"))
+		}
+		return helper(name, args...)
+	}
+	err := s.RunService(context.Background(), ServiceOptions{
+		MaxRestarts:  2,
+		RestartDelay: 10 * time.Millisecond,
+		Readiness:    ReadinessOptions{PollInterval: 10 * time.Millisecond},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to restart") {
+		t.Errorf("RunService got error %v, want the server to have failed to restart", err)
+	}
+	if want := 3; starts != want {
+		t.Errorf("RunService started the server %d times, want %d", starts, want)
+	}
+}
+
+func TestRunServiceRunning(t *testing.T) {
+	s := serviceServer(t, "0")
+	pidFile := filepath.Join(t.TempDir(), "tika.pid")
+	if err := writePIDFile(pidFile, os.Getpid()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RunService(context.Background(), ServiceOptions{PIDFile: pidFile}); err == nil {
+		t.Error("RunService with a running PID got no error")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tika.log")
+	r := &RotatingFile{Path: path, MaxBytes: 10, MaxFiles: 2}
+	for _, s := range []string{"aaaaaa", "bbbbbb", "cccccc", "dddddd"} {
+		if _, err := r.Write([]byte(s)); err != nil {
+			t.Fatalf("Write got error: %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close got error: %v", err)
+	}
+	want := map[string]string{
+		path:        "dddddd",
+		path + ".1": "cccccc",
+		path + ".2": "bbbbbb",
+	}
+	for p, w := range want {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Errorf("ReadFile(%s) got error: %v", p, err)
+			continue
+		}
+		if string(b) != w {
+			t.Errorf("%s = %q, want %q", p, b, w)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("RotatingFile kept too many files: %v", err)
+	}
+}
+
+func TestRunServiceReload(t *testing.T) {
+	s := serviceServer(t, "10")
+	pidFile := filepath.Join(t.TempDir(), "tika.pid")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	restart := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- s.RunService(ctx, ServiceOptions{
+			PIDFile:   pidFile,
+			Readiness: ReadinessOptions{PollInterval: 10 * time.Millisecond},
+			Restart:   restart,
+		})
+	}()
+	// waitPID waits for the PID file to name a process other than old.
+	waitPID := func(old int) int {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if pid, err := readPIDFile(pidFile); err == nil && pid != old {
+				return pid
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("RunService did not write a new PID file")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	first := waitPID(0)
+	restart <- struct{}{}
+	waitPID(first)
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("RunService got error: %v", err)
+	}
+}
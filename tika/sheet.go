@@ -0,0 +1,128 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// A Sheet is a worksheet of a spreadsheet.
+type Sheet struct {
+	// Name is the name of the sheet, or "" if it is unknown.
+	Name string
+	// Rows are the rows of the sheet, each a list of cell values as
+	// displayed.
+	Rows [][]string
+}
+
+// Spreadsheet parses a spreadsheet, such as an .xlsx, .xls, or .ods file, and
+// returns its sheets. It requests XHTML from the server and converts each
+// table to a Sheet; see SheetsFromXHTML.
+func (c *Client) Spreadsheet(ctx context.Context, input io.Reader) ([]Sheet, error) {
+	body, err := c.call(ctx, input, "PUT", "/tika", http.Header{"Accept": {"text/html"}})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	sheets, err := SheetsFromXHTML(body)
+	if err != nil || c.redactor == nil {
+		return sheets, err
+	}
+	for i := range sheets {
+		sheets[i].Name = c.redactor.Redact(sheets[i].Name)
+		for _, row := range sheets[i].Rows {
+			for j, cell := range row {
+				row[j] = c.redactor.Redact(cell)
+			}
+		}
+	}
+	return sheets, nil
+}
+
+// SheetsFromXHTML converts the tables in XHTML produced by Tika to Sheets.
+// Each <table> is a Sheet, named by the <h1> heading preceding it, as Tika
+// produces for spreadsheets. Whitespace in cells is collapsed.
+func SheetsFromXHTML(r io.Reader) ([]Sheet, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	var (
+		sheets  []Sheet
+		sheet   *Sheet
+		heading strings.Builder
+		cell    strings.Builder
+		inH1    bool
+		inCell  bool
+		name    string
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "h1":
+				inH1 = true
+				heading.Reset()
+			case "table":
+				sheets = append(sheets, Sheet{Name: name})
+				sheet = &sheets[len(sheets)-1]
+				name = ""
+			case "tr":
+				if sheet != nil {
+					sheet.Rows = append(sheet.Rows, []string{})
+				}
+			case "td", "th":
+				inCell = true
+				cell.Reset()
+			}
+		case xml.EndElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "h1":
+				inH1 = false
+				name = strings.Join(strings.Fields(heading.String()), " ")
+			case "table":
+				sheet = nil
+			case "td", "th":
+				inCell = false
+				if sheet != nil && len(sheet.Rows) > 0 {
+					row := &sheet.Rows[len(sheet.Rows)-1]
+					*row = append(*row, strings.Join(strings.Fields(cell.String()), " "))
+				}
+			}
+		case xml.CharData:
+			switch {
+			case inCell:
+				cell.Write(t)
+			case inH1:
+				heading.Write(t)
+			}
+		}
+	}
+	return sheets, nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSpreadsheet(t *testing.T) {
+	var gotAccept string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept")
+		fmt.Fprint(w, `<html xmlns="http://www.w3.org/1999/xhtml"><head><title></title></head><body>
+<div class="page"><h1>Sales</h1>
+<table><tbody>
+<tr>	<td>Region</td>	<td>Total</td></tr>
+<tr>	<td>North
+</td>	<td>1,200</td></tr>
+</tbody></table>
+</div>
+<div class="page"><h1>Empty &amp; Old</h1>
+<table><tbody></tbody></table>
+</div>
+</body></html>`)
+	}))
+	defer ts.Close()
+
+	got, err := NewClient(nil, ts.URL).Spreadsheet(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Spreadsheet returned an error: %v", err)
+	}
+	if gotAccept != "text/html" {
+		t.Errorf("Spreadsheet sent Accept %q, want text/html", gotAccept)
+	}
+	want := []Sheet{
+		{Name: "Sales", Rows: [][]string{{"Region", "Total"}, {"North", "1,200"}}},
+		{Name: "Empty & Old"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Spreadsheet got %+v, want %+v", got, want)
+	}
+}
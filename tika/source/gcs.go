@@ -0,0 +1,55 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// GCSEndpoint is the default endpoint of Google Cloud Storage.
+const GCSEndpoint = "https://storage.googleapis.com"
+
+// GCS opens gs://bucket/object references.
+type GCS struct {
+	// HTTPClient makes requests and is responsible for authentication. If
+	// HTTPClient is nil, http.DefaultClient is used, and only public objects
+	// can be opened.
+	HTTPClient *http.Client
+	// Endpoint is the base URL of the storage API. If Endpoint is empty,
+	// GCSEndpoint is used.
+	Endpoint string
+}
+
+// Open opens the object referenced by ref.
+func (g *GCS) Open(ctx context.Context, ref string) (*Object, error) {
+	bucket, name, err := splitRef(ref, "gs")
+	if err != nil {
+		return nil, err
+	}
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = GCSEndpoint
+	}
+	u := strings.TrimSuffix(endpoint, "/") + "/" + escape(bucket) + "/" + escapePath(name)
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return get(g.HTTPClient, req, ref)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package source opens objects in cloud storage, such as gs://bucket/object
+// and s3://bucket/key references, as streams suitable for parsing with a
+// tika.Client.
+//
+// Objects are fetched with plain HTTP requests. Requests to Google Cloud
+// Storage are authenticated by the http.Client passed in GCS.HTTPClient, for
+// example one created with golang.org/x/oauth2/google. Requests to S3 are
+// signed with AWS Signature Version 4 using S3.Credentials.
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-tika/tika"
+)
+
+// An Object is the streamed content of a stored object. The caller must close
+// it.
+type Object struct {
+	io.ReadCloser
+	// Ref is the reference the object was opened with.
+	Ref string
+	// Size is the size of the object in bytes, or -1 if it is unknown.
+	Size int64
+	// ContentType is the content type recorded in the object metadata, or ""
+	// if there is none.
+	ContentType string
+}
+
+// Header returns a header hinting the content type of o to the Tika Server,
+// suitable for the WithHeader methods of tika.Client. Generic content types,
+// which would not help detection, are omitted.
+func (o *Object) Header() http.Header {
+	h := http.Header{}
+	if o.ContentType != "" && o.ContentType != "application/octet-stream" && o.ContentType != "binary/octet-stream" {
+		h.Set("Content-Type", o.ContentType)
+	}
+	return h
+}
+
+// An Opener opens object references.
+type Opener interface {
+	Open(ctx context.Context, ref string) (*Object, error)
+}
+
+// Mux is an Opener which dispatches references to Openers by URL scheme.
+type Mux map[string]Opener
+
+// Default opens gs:// references with an unauthenticated GCS and s3://
+// references with an S3 using EnvCredentials and the AWS_REGION environment
+// variable.
+var Default = Mux{
+	"gs": &GCS{},
+	"s3": &S3{},
+}
+
+// Open opens ref with the Opener registered for its scheme.
+func (m Mux) Open(ctx context.Context, ref string) (*Object, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	o, ok := m[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("unsupported object reference %q", ref)
+	}
+	return o.Open(ctx, ref)
+}
+
+// Open opens ref with Default.
+func Open(ctx context.Context, ref string) (*Object, error) {
+	return Default.Open(ctx, ref)
+}
+
+// Input returns a tika.Input named ref which opens ref with o, for use with
+// tika.Batch.
+func Input(ctx context.Context, o Opener, ref string) tika.Input {
+	return tika.Input{
+		Name: ref,
+		Open: func() (io.ReadCloser, error) { return o.Open(ctx, ref) },
+	}
+}
+
+// splitRef splits a reference of the form scheme://bucket/name.
+func splitRef(ref, scheme string) (bucket, name string, err error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != scheme || u.Host == "" || len(u.Path) < 2 {
+		return "", "", fmt.Errorf("invalid %s reference %q", scheme, ref)
+	}
+	return u.Host, u.Path[1:], nil
+}
+
+// escapePath escapes each segment of a slash separated object name.
+func escapePath(name string) string {
+	segs := strings.Split(name, "/")
+	for i, s := range segs {
+		segs[i] = escape(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+// escape percent-encodes every byte of s except the RFC 3986 unreserved
+// characters.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// get sends req and returns its body as an Object.
+func get(client *http.Client, req *http.Request, ref string) (*Object, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: %s", ref, resp.Status)
+	}
+	return &Object{
+		ReadCloser:  resp.Body,
+		Ref:         ref,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets of the latency histogram
+// in a StatsSnapshot.
+var LatencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	30 * time.Second,
+}
+
+// Stats collects statistics about the requests made by a Client. Pass it to
+// NewClient with WithStats. The zero value is ready to use, and a Stats is
+// safe for concurrent use and may be shared by several Clients.
+type Stats struct {
+	mu sync.Mutex
+	s  StatsSnapshot
+}
+
+// A StatsSnapshot is a copy of the statistics collected by a Stats.
+type StatsSnapshot struct {
+	// Requests is the number of requests made.
+	Requests int64
+	// BytesSent is the number of request body bytes sent.
+	BytesSent int64
+	// BytesReceived is the number of response body bytes read.
+	BytesReceived int64
+	// Errors counts failed requests by response status code. Requests which
+	// failed without a response are counted under 0.
+	Errors map[int]int64
+	// Latency is a histogram of the time until response headers were
+	// received. Latency[i] counts requests that took at most
+	// LatencyBuckets[i] and longer than LatencyBuckets[i-1]; the last
+	// element counts requests slower than every bucket.
+	Latency []int64
+	// TotalLatency is the sum of the latencies of every request.
+	TotalLatency time.Duration
+	// MIMETypes records the time taken to process documents by MIME type,
+	// as reported by Pipeline or ObserveMIME.
+	MIMETypes map[string]MIMEStats
+}
+
+// MIMEStats are the statistics of documents of one MIME type.
+type MIMEStats struct {
+	Count int64
+	Total time.Duration
+}
+
+// WithStats records statistics about every request made by the Client in s.
+func WithStats(s *Stats) Option {
+	return func(c *Client) {
+		c.stats = s
+	}
+}
+
+// Snapshot returns a copy of the statistics collected so far.
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := s.s
+	snap.Errors = make(map[int]int64, len(s.s.Errors))
+	for k, v := range s.s.Errors {
+		snap.Errors[k] = v
+	}
+	snap.Latency = make([]int64, len(LatencyBuckets)+1)
+	copy(snap.Latency, s.s.Latency)
+	snap.MIMETypes = make(map[string]MIMEStats, len(s.s.MIMETypes))
+	for k, v := range s.s.MIMETypes {
+		snap.MIMETypes[k] = v
+	}
+	return snap
+}
+
+// ObserveMIME records that a document of the given MIME type took d to
+// process.
+func (s *Stats) ObserveMIME(mimeType string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.s.MIMETypes == nil {
+		s.s.MIMETypes = map[string]MIMEStats{}
+	}
+	m := s.s.MIMETypes[mimeType]
+	m.Count++
+	m.Total += d
+	s.s.MIMETypes[mimeType] = m
+}
+
+// request records a request which received a response with the given status
+// code, or 0 if it failed, after d.
+func (s *Stats) request(status int, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.Requests++
+	s.s.TotalLatency += d
+	if s.s.Latency == nil {
+		s.s.Latency = make([]int64, len(LatencyBuckets)+1)
+	}
+	i := sort.Search(len(LatencyBuckets), func(i int) bool { return d <= LatencyBuckets[i] })
+	s.s.Latency[i]++
+	if status != 200 {
+		if s.s.Errors == nil {
+			s.s.Errors = map[int]int64{}
+		}
+		s.s.Errors[status]++
+	}
+}
+
+func (s *Stats) addBytes(sent, received int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.s.BytesSent += sent
+	s.s.BytesReceived += received
+}
+
+// String summarizes the snapshot on a few lines, suitable for printing at the
+// end of a batch job.
+func (s StatsSnapshot) String() string {
+	b := &strings.Builder{}
+	var errs int64
+	for _, n := range s.Errors {
+		errs += n
+	}
+	fmt.Fprintf(b, "requests: %d, errors: %d, sent: %d bytes, received: %d bytes", s.Requests, errs, s.BytesSent, s.BytesReceived)
+	if s.Requests > 0 {
+		fmt.Fprintf(b, ", mean latency: %v", s.TotalLatency/time.Duration(s.Requests))
+	}
+	var types []string
+	for t := range s.MIMETypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		m := s.MIMETypes[t]
+		fmt.Fprintf(b, "\n%s: %d documents, mean %v", t, m.Count, m.Total/time.Duration(m.Count))
+	}
+	return b.String()
+}
+
+// countingBody counts the bytes read from a request or response body.
+type countingBody struct {
+	io.ReadCloser
+	stats *Stats
+	sent  bool
+}
+
+func (c *countingBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if c.sent {
+		c.stats.addBytes(int64(n), 0)
+	} else {
+		c.stats.addBytes(0, int64(n))
+	}
+	return n, err
+}
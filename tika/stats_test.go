@@ -0,0 +1,88 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/detect/stream" {
+			fmt.Fprint(w, "text/plain; charset=UTF-8")
+			return
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		fmt.Fprint(w, "parsed")
+	}))
+	defer ts.Close()
+
+	s := &Stats{}
+	c := NewClient(nil, ts.URL, WithStats(s))
+	ctx := context.Background()
+	if _, err := c.Parse(ctx, strings.NewReader("input")); err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	c.callString(ctx, nil, "GET", "/fail", nil)
+	p := &Pipeline{Client: c}
+	if _, err := p.Process(ctx, strings.NewReader("doc")); err != nil {
+		t.Fatalf("Process returned an error: %v", err)
+	}
+
+	snap := s.Snapshot()
+	if snap.Requests != 4 {
+		t.Errorf("Requests got %d, want 4", snap.Requests)
+	}
+	if want := int64(len("input") + 2*len("doc")); snap.BytesSent != want {
+		t.Errorf("BytesSent got %d, want %d", snap.BytesSent, want)
+	}
+	if want := int64(len("parsed")*2 + len("text/plain; charset=UTF-8")); snap.BytesReceived != want {
+		t.Errorf("BytesReceived got %d, want %d", snap.BytesReceived, want)
+	}
+	if len(snap.Errors) != 1 || snap.Errors[http.StatusUnprocessableEntity] != 1 {
+		t.Errorf("Errors got %v, want one %d", snap.Errors, http.StatusUnprocessableEntity)
+	}
+	var total int64
+	for _, n := range snap.Latency {
+		total += n
+	}
+	if total != 4 || len(snap.Latency) != len(LatencyBuckets)+1 {
+		t.Errorf("Latency got %v, want 4 requests in %d buckets", snap.Latency, len(LatencyBuckets)+1)
+	}
+	if m := snap.MIMETypes["text/plain"]; m.Count != 1 {
+		t.Errorf("MIMETypes got %v, want one text/plain document", snap.MIMETypes)
+	}
+	if got := snap.String(); !strings.Contains(got, "requests: 4, errors: 1") || !strings.Contains(got, "text/plain: 1 documents") {
+		t.Errorf("String got %q", got)
+	}
+
+	// Snapshots are copies.
+	snap.Errors[0] = 10
+	s.ObserveMIME("text/plain", time.Second)
+	if s.Snapshot().Errors[0] != 0 || snap.MIMETypes["text/plain"].Count != 1 {
+		t.Error("Snapshot shares state with Stats")
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManageTempDir makes Start point the java.io.tmpdir of the Java process to
+// a new temporary directory, which is removed when the server stops. Tika
+// leaks large temporary files from failed parses, so long running servers
+// should also set maxAge: while the server runs, files and directories in
+// the temporary directory that have not been modified for maxAge are
+// removed. If maxAge is less than or equal to 0, files are only removed when
+// the server stops. The directory is swept every maxAge/2, but at most once
+// a second.
+//
+// If the Java process runs as another user, set with the Credential of
+// SandboxOptions.SysProcAttr, the directory is owned by that user.
+// If used, ManageTempDir must be called before starting the server.
+func (s *Server) ManageTempDir(maxAge time.Duration) error {
+	if s.cmd != nil {
+		return fmt.Errorf("server process already started, cannot manage the temporary directory")
+	}
+	s.manageTmp = true
+	s.tmpMaxAge = maxAge
+	return nil
+}
+
+// createTempDir creates the managed temporary directory, if enabled, and
+// returns the Java system property pointing to it.
+func (s *Server) createTempDir() (string, error) {
+	if !s.manageTmp {
+		return "", nil
+	}
+	dir, err := ioutil.TempDir("", "tika-tmp-")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary directory: %v", err)
+	}
+	if s.sandbox != nil {
+		if err := chownForProcess(dir, s.sandbox.SysProcAttr); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("error giving the temporary directory to the server user: %v", err)
+		}
+	}
+	s.tmpDir = dir
+	return "-Djava.io.tmpdir=" + dir, nil
+}
+
+// minSweepInterval is the minimum interval between two sweeps of the
+// managed temporary directory.
+const minSweepInterval = time.Second
+
+// startSweeper periodically removes stale files from the managed temporary
+// directory until stopSweeper is called.
+func (s *Server) startSweeper() {
+	if s.tmpDir == "" || s.tmpMaxAge <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	s.stopSweep = stop
+	dir, maxAge := s.tmpDir, s.tmpMaxAge
+	interval := maxAge / 2
+	if interval < minSweepInterval {
+		interval = minSweepInterval
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				sweepTempDir(dir, maxAge)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// removeTempDir stops the sweeper and removes the managed temporary
+// directory, if any.
+func (s *Server) removeTempDir() {
+	if s.stopSweep != nil {
+		close(s.stopSweep)
+		s.stopSweep = nil
+	}
+	if s.tmpDir != "" {
+		os.RemoveAll(s.tmpDir)
+		s.tmpDir = ""
+	}
+}
+
+// sweepTempDir removes the entries of dir that have not been modified for
+// maxAge.
+func sweepTempDir(dir string, maxAge time.Duration) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		if e.ModTime().Before(cutoff) {
+			os.RemoveAll(filepath.Join(dir, e.Name()))
+		}
+	}
+}
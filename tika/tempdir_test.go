@@ -0,0 +1,118 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManageTempDir(t *testing.T) {
+	oldCommand := command
+	defer func() { command = oldCommand }()
+
+	path, err := os.Executable() // Use the text executable path as a dummy jar.
+	if err != nil {
+		t.Skip("cannot find current test executable")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "1.14")
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("error creating test server: %v", err)
+	}
+
+	s, err := NewServer(path, tsURL.Port())
+	if err != nil {
+		t.Fatalf("NewServer got error: %v", err)
+	}
+	if err := s.ManageTempDir(time.Hour); err != nil {
+		t.Fatalf("ManageTempDir got error: %v", err)
+	}
+	var gotProp string
+	command = func(c string, args ...string) *exec.Cmd {
+		for _, arg := range args {
+			if strings.HasPrefix(arg, "-Djava.io.tmpdir=") {
+				gotProp = arg
+			}
+		}
+		return oldCommand(c, args...)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	dir := s.tmpDir
+	if want := "-Djava.io.tmpdir=" + dir; dir == "" || gotProp != want {
+		t.Errorf("Start passed %q, want %q", gotProp, want)
+	}
+	s.Stop()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Stop did not remove the temporary directory: %v", err)
+	}
+}
+
+func TestSweepTempDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sweep")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	old := filepath.Join(dir, "old")
+	fresh := filepath.Join(dir, "fresh")
+	for _, p := range []string{old, fresh} {
+		if err := ioutil.WriteFile(p, nil, 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	stale := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(old, stale, stale); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	sweepTempDir(dir, time.Hour)
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("sweepTempDir did not remove a stale file: %v", err)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("sweepTempDir removed a fresh file: %v", err)
+	}
+}
+
+func TestStartSweeperTinyMaxAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sweep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// A maxAge of 1ns would make a ticker of 0 panic in the goroutine.
+	s := &Server{tmpDir: dir, tmpMaxAge: 1}
+	s.startSweeper()
+	time.Sleep(10 * time.Millisecond)
+	s.removeTempDir()
+}
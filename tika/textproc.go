@@ -0,0 +1,97 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"unicode/utf8"
+)
+
+// A TextProcessor transforms extracted text, for example to normalize,
+// redact, or truncate it. Processors are chained with WithTextProcessors or
+// Pipeline.Processors, and can be tested in isolation with ProcessText.
+type TextProcessor interface {
+	// Process returns the transformed text, or an error which fails the
+	// request that extracted it.
+	Process(ctx context.Context, text string) (string, error)
+}
+
+// TextProcessorFunc adapts a function to a TextProcessor.
+type TextProcessorFunc func(ctx context.Context, text string) (string, error)
+
+// Process returns f(ctx, text).
+func (f TextProcessorFunc) Process(ctx context.Context, text string) (string, error) {
+	return f(ctx, text)
+}
+
+// Process implements TextProcessor with Apply.
+func (o TextOptions) Process(_ context.Context, text string) (string, error) {
+	return o.Apply(text), nil
+}
+
+// Process implements TextProcessor with Redact.
+func (r *RegexRedactor) Process(_ context.Context, text string) (string, error) {
+	return r.Redact(text), nil
+}
+
+// Truncate returns a TextProcessor keeping at most the first n bytes of the
+// text, cut at a UTF-8 character boundary.
+func Truncate(n int) TextProcessor {
+	return TextProcessorFunc(func(_ context.Context, text string) (string, error) {
+		if len(text) <= n {
+			return text, nil
+		}
+		i := n
+		for i > 0 && !utf8.RuneStart(text[i]) {
+			i--
+		}
+		return text[:i], nil
+	})
+}
+
+// ProcessText applies ps to text in order, stopping at the first error or
+// once ctx is done.
+func ProcessText(ctx context.Context, text string, ps ...TextProcessor) (string, error) {
+	for _, p := range ps {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		var err error
+		if text, err = p.Process(ctx, text); err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+// WithTextProcessors applies ps, in order, to the text returned by Parse and
+// ParseWithHeader and to the XTIKAContent field of recursive results, after
+// WithTextOptions and before WithRedactor. Calling it again appends to the
+// chain. Readers returned by ParseReader are not processed.
+func WithTextProcessors(ps ...TextProcessor) Option {
+	return func(c *Client) {
+		c.processors = append(c.processors[:len(c.processors):len(c.processors)], ps...)
+	}
+}
+
+// processText applies the TextOptions and TextProcessors of c to text.
+func (c *Client) processText(ctx context.Context, text string) (string, error) {
+	if c.textOptions != nil {
+		text = c.textOptions.Apply(text)
+	}
+	return ProcessText(ctx, text, c.processors...)
+}
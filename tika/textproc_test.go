@@ -0,0 +1,108 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// upper is a TextProcessor for tests.
+var upper = TextProcessorFunc(func(_ context.Context, s string) (string, error) {
+	return strings.ToUpper(s), nil
+})
+
+func TestProcessText(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		in   string
+		ps   []TextProcessor
+		want string
+	}{
+		{"a  b ", []TextProcessor{TextOptions{CollapseWhitespace: true}, upper}, "A B"},
+		{"mail a@b.org", []TextProcessor{NewRegexRedactor(), Truncate(9)}, "mail [RED"},
+		{"héllo", []TextProcessor{Truncate(2)}, "h"},
+		{"short", []TextProcessor{Truncate(10)}, "short"},
+		{"none", nil, "none"},
+	}
+	for _, test := range tests {
+		if got, err := ProcessText(ctx, test.in, test.ps...); err != nil || got != test.want {
+			t.Errorf("ProcessText(%q) = %q, %v, want %q", test.in, got, err, test.want)
+		}
+	}
+
+	errFail := errors.New("fail")
+	fail := TextProcessorFunc(func(context.Context, string) (string, error) { return "", errFail })
+	if _, err := ProcessText(ctx, "x", upper, fail, upper); err != errFail {
+		t.Errorf("ProcessText with a failing processor got error %v, want %v", err, errFail)
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := ProcessText(canceled, "x", upper); err != context.Canceled {
+		t.Errorf("ProcessText with a canceled context got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTruncateReused(t *testing.T) {
+	p := Truncate(4)
+	tests := []struct {
+		in, want string
+	}{
+		{"aaéé", "aaé"},
+		{"abcé", "abc"},
+		{"aaaé", "aaa"},
+		{"abcdefgh", "abcd"},
+	}
+	for _, test := range tests {
+		if got, err := p.Process(context.Background(), test.in); err != nil || got != test.want {
+			t.Errorf("Truncate(4).Process(%q) = %q, %v, want %q", test.in, got, err, test.want)
+		}
+	}
+}
+
+func TestWithTextProcessors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/rmeta") {
+			w.Write([]byte(`[{"X-TIKA:content": ["mail a@b.org  "]}]`))
+			return
+		}
+		w.Write([]byte("mail a@b.org  "))
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL,
+		WithTextOptions(TextOptions{CollapseWhitespace: true}),
+		WithTextProcessors(upper),
+		WithRedactor(NewRegexRedactor()),
+		WithTextProcessors(Truncate(12)))
+	ctx := context.Background()
+	got, err := c.Parse(ctx, strings.NewReader("x"))
+	if want := "MAIL [REDACTED:email]"; err != nil || got != want {
+		t.Errorf("Parse got %q, %v, want %q", got, err, want)
+	}
+	docs, err := c.MetaRecursive(ctx, strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("MetaRecursive got error: %v", err)
+	}
+	if want := []string{"MAIL [REDACTED:email]"}; len(docs) != 1 || !reflect.DeepEqual(docs[0][XTIKAContent], want) {
+		t.Errorf("MetaRecursive got %v, want content %q", docs, want)
+	}
+}
@@ -17,13 +17,19 @@ limitations under the License.
 package tika
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
-	"reflect"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ClientError is returned by Client's various parse methods and
@@ -42,30 +48,169 @@ import (
 //	} else if err != nil {
 //	    // Handle non-http error
 //	}
+//
+// ClientError also matches the sentinel errors ErrUnsupportedMediaType,
+// ErrUnprocessable, ErrServerUnavailable, and ErrEncrypted with errors.Is.
 type ClientError struct {
 	// StatusCode is the HTTP status code returned by the Tika server.
 	StatusCode int
+	// Message is the start of the body of the response, which usually
+	// holds the Java exception that caused the error.
+	Message string
+	// RequestID is the RequestIDHeader sent with the request, which can be
+	// used to find the request in the server logs.
+	RequestID string
 }
 
 func (e ClientError) Error() string {
-	return fmt.Sprintf("response code %d", e.StatusCode)
+	if e.RequestID == "" {
+		return fmt.Sprintf("response code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("response code %d (request ID %s)", e.StatusCode, e.RequestID)
 }
 
 // Client represents a connection to a Tika Server.
+//
+// A Client is configured once, by NewClient and its Options, and is never
+// modified afterwards. It is safe for concurrent use by multiple goroutines.
 type Client struct {
 	// url is the URL of the Tika Server, including the port (if necessary), but
 	// not the trailing slash. For example, http://localhost:9998.
 	url string
-	// HTTPClient is the client that will be used to call the Tika Server. If no
-	// client is specified, a default client will be used. Since http.Clients are
+	// httpClient is the client that will be used to call the Tika Server. If no
+	// client is specified, http.DefaultClient is used. Since http.Clients are
 	// thread safe, the same client will be used for all requests by this Client.
 	httpClient *http.Client
+	// maxResponseBytes is the maximum size of a response body, if greater
+	// than 0. See WithMaxResponseBytes.
+	maxResponseBytes int64
+	// stats, if not nil, records statistics about requests. See WithStats.
+	stats *Stats
+	// breaker, if not nil, fails requests fast while the server is down. See
+	// WithCircuitBreaker.
+	breaker *breaker
+	// strictEmbedded makes recursive methods fail on recorded exceptions.
+	// See WithStrictEmbedded.
+	strictEmbedded bool
+	// transcode and invalidPolicy configure conversion of responses to
+	// UTF-8. See WithUTF8.
+	transcode     bool
+	invalidPolicy InvalidPolicy
+	// textOptions, if not nil, normalize extracted text. See
+	// WithTextOptions.
+	textOptions *TextOptions
+	// embeddedTypes, if not empty, filters embedded documents. See
+	// WithEmbeddedTypes.
+	embeddedTypes []string
+	// pathPrefix is prepended to the path of every request. See
+	// WithPathPrefix.
+	pathPrefix string
+	// configErr is the error found validating url or applying an Option, if
+	// any. Every request fails with it.
+	configErr error
+	// keyMap, if not nil, renames metadata keys. See WithCanonicalKeys.
+	keyMap map[string]string
+	// inflight tracks the requests in flight for Shutdown.
+	inflight *inflight
+	// checkContentType rejects responses not matching the Accept header.
+	// See WithContentTypeCheck.
+	checkContentType bool
+	// redactor, if not nil, masks sensitive content. See WithRedactor.
+	redactor Redactor
+	// processors transform extracted text. See WithTextProcessors.
+	processors []TextProcessor
+	// headers are sent with every request. See WithHeader.
+	headers http.Header
+	// urlProvider, if not nil, replaces url. See WithURLProvider.
+	urlProvider URLProvider
+	// refresh, if not nil, periodically closes idle connections. See
+	// WithConnectionRefresh.
+	refresh *connRefresh
+	// timeouts, if not nil, limit the time of requests by MIME type. See
+	// WithMIMETimeouts.
+	timeouts TimeoutTable
+	// archiveLimits, if not nil, are checked before sending inputs. See
+	// WithArchiveLimits.
+	archiveLimits *ArchiveLimits
+	// urlPolicy, if not nil, restricts ParseURL and FetchURL. See
+	// WithURLPolicy.
+	urlPolicy *URLPolicy
+	// fetchClient is the client FetchURL uses, built from urlPolicy on
+	// first use and shared by every call so that connections are reused
+	// rather than leaked. It is guarded by fetchMu.
+	fetchMu     sync.Mutex
+	fetchClient *http.Client
+	// transport, if not nil, is the transport of httpClient, cloned by the
+	// Client for WithProxy or WithConnectionRefresh. Unlike the transport of
+	// the http.Client passed to NewClient, which may be shared, it is owned
+	// by the Client, whose idle connections it closes.
+	transport *http.Transport
 }
 
 // NewClient creates a new Client. If httpClient is nil, the http.DefaultClient will be
-// used.
-func NewClient(httpClient *http.Client, urlString string) *Client {
-	return &Client{httpClient: httpClient, url: urlString}
+// used. The Client is configured with the given options, which are applied
+// before NewClient returns.
+//
+// If urlString is not an absolute URL, every request fails with an error
+// describing the problem, unless the Client uses WithURLProvider.
+func NewClient(httpClient *http.Client, urlString string, opts ...Option) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	c := &Client{httpClient: httpClient, url: urlString, inflight: &inflight{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.urlProvider != nil {
+		return c
+	}
+	if u, err := url.Parse(urlString); err != nil {
+		c.configErr = fmt.Errorf("invalid server URL %q: %v", urlString, err)
+	} else if u.Scheme == "" || u.Host == "" {
+		c.configErr = fmt.Errorf("invalid server URL %q: want scheme://host[:port]", urlString)
+	}
+	return c
+}
+
+// NewClientURL is like NewClient, but normalizes rawURL and returns an error
+// if it does not name a Tika server. A URL without a scheme, such as
+// "localhost:9998", defaults to http, and trailing slashes are removed. Only
+// the http and https schemes are accepted.
+func NewClientURL(httpClient *http.Client, rawURL string, opts ...Option) (*Client, error) {
+	u, err := normalizeURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(httpClient, u, opts...), nil
+}
+
+// normalizeURL returns rawURL with a default http scheme and without trailing
+// slashes.
+func normalizeURL(rawURL string) (string, error) {
+	s := strings.TrimSpace(rawURL)
+	if s == "" {
+		return "", fmt.Errorf("invalid server URL %q: empty", rawURL)
+	}
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %v", rawURL, err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid server URL %q: unsupported scheme %q", rawURL, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid server URL %q: missing host", rawURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid server URL %q: unexpected query or fragment", rawURL)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
 }
 
 // A Parser represents a Tika Parser. To get a list of all Parsers, see Parsers().
@@ -106,32 +251,242 @@ const (
 	YandexTranslator    Translator = "org.apache.tika.language.translate.YandexTranslator"
 )
 
+// AllTranslators is a list of the Translators available by default in Tika.
+var AllTranslators = []Translator{
+	Lingo24Translator,
+	GoogleTranslator,
+	MosesTranslator,
+	JoshuaTranslator,
+	MicrosoftTranslator,
+	YandexTranslator,
+}
+
 // XTIKAContent is the metadata field of the content of a file after recursive
 // parsing. See ParseRecursive and MetaRecursive.
 const XTIKAContent = "X-TIKA:content"
 
+// XTIKAEmbeddedResourcePath is the metadata field of the path of an embedded
+// document within its container after recursive parsing. It is not set for
+// the container itself.
+const XTIKAEmbeddedResourcePath = "X-TIKA:embedded_resource_path"
+
+// Request headers used to choose which parsers handle a single request. See
+// SkipParsers and ForceParser.
+const (
+	SkipParsersHeader = "X-Tika-Skip-Parsers"
+	ForceParserHeader = "X-Tika-Parser"
+)
+
+// SkipParsers adds the given parser classes to the SkipParsersHeader of header
+// and returns it. If header is nil, a new header is created. The result can be
+// passed to any of the WithHeader methods. For example, to skip OCR:
+//
+//	h := tika.SkipParsers(nil, "org.apache.tika.parser.ocr.TesseractOCRParser")
+//	body, err := client.ParseWithHeader(context.Background(), input, h)
+func SkipParsers(header http.Header, parsers ...string) http.Header {
+	if header == nil {
+		header = http.Header{}
+	}
+	for _, p := range parsers {
+		header.Add(SkipParsersHeader, p)
+	}
+	return header
+}
+
+// ForceParser sets the ForceParserHeader of header so that the input is
+// handled by the given parser class instead of the one chosen by detection,
+// and returns header. If header is nil, a new header is created. For example,
+// to get the raw text of an HTML file:
+//
+//	h := tika.ForceParser(nil, "org.apache.tika.parser.txt.TXTParser")
+//	body, err := client.ParseWithHeader(context.Background(), input, h)
+func ForceParser(header http.Header, parser string) http.Header {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(ForceParserHeader, parser)
+	return header
+}
+
 // call makes the given request to c and returns the response body.
 // call returns an error and a nil reader if the response code is not 200 StatusOK.
 func (c *Client) call(ctx context.Context, input io.Reader, method, path string, header http.Header) (io.ReadCloser, error) {
-	if c.httpClient == nil {
-		c.httpClient = http.DefaultClient
+	ctx, cancel := c.mimeTimeout(ctx, header)
+	ctx, done, err := c.inflight.add(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
-
-	req, err := http.NewRequestWithContext(ctx, method, c.url+path, input)
+	release := func() {
+		done()
+		cancel()
+	}
+	if err := c.checkFileURL(ctx, header); err != nil {
+		release()
+		return nil, err
+	}
+	if c.archiveLimits != nil {
+		if err := c.archiveLimits.checkInput(input); err != nil {
+			release()
+			return nil, err
+		}
+		header = c.archiveLimits.header(path, header)
+	}
+	body, err := c.callBreaker(ctx, input, method, path, header)
 	if err != nil {
+		release()
 		return nil, err
 	}
-	req.Header = header
+	return &trackedBody{ReadCloser: body, ctx: ctx, release: release}, nil
+}
+
+// callBreaker makes the request described by call through the circuit
+// breaker, if any.
+func (c *Client) callBreaker(ctx context.Context, input io.Reader, method, path string, header http.Header) (io.ReadCloser, error) {
+	if c.breaker == nil {
+		return c.do(ctx, input, method, path, header)
+	}
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	body, err := c.do(ctx, input, method, path, header)
+	c.breaker.record(err)
+	return body, err
+}
 
-	resp, err := c.httpClient.Do(req)
+// do makes the request described by call.
+func (c *Client) do(ctx context.Context, input io.Reader, method, path string, header http.Header) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, method, path, input, header)
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return nil, ClientError{resp.StatusCode}
+		defer resp.Body.Close()
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorMessage))
+		return nil, ClientError{StatusCode: resp.StatusCode, Message: string(msg), RequestID: resp.Request.Header.Get(RequestIDHeader)}
+	}
+	if c.checkContentType {
+		if err := checkContentType(resp); err != nil {
+			return nil, err
+		}
+	}
+	body := resp.Body
+	if c.maxResponseBytes > 0 {
+		if resp.ContentLength > c.maxResponseBytes {
+			resp.Body.Close()
+			return nil, ErrResponseTooLarge
+		}
+		body = &limitedBody{body, c.maxResponseBytes, ErrResponseTooLarge}
+	}
+	if c.transcode {
+		return newTranscoder(body, responseCharset(ctx, resp.Header.Get("Content-Type")), c.invalidPolicy)
+	}
+	return body, nil
+}
+
+// send sends a request to the server, setting the request ID and recording
+// statistics. Errors reaching the server match ErrServerUnavailable.
+func (c *Client) send(ctx context.Context, method, path string, input io.Reader, header http.Header) (*http.Response, error) {
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+	base, err := c.baseURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, base+c.pathPrefix+path, input)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	for k, vs := range c.headers {
+		if _, ok := req.Header[k]; !ok {
+			req.Header[k] = append([]string(nil), vs...)
+		}
+	}
+	req.Header.Set(RequestIDHeader, requestID(ctx, req.Header))
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &cancelBody{req.Body, ctx}
+	}
+	if c.stats != nil && req.Body != nil {
+		req.Body = &countingBody{req.Body, c.stats, true}
+	}
+
+	start := time.Now()
+	hc := c.httpClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		if c.stats != nil {
+			c.stats.request(0, time.Since(start))
+		}
+		if ctx.Err() == nil {
+			err = unavailableError{err}
+		}
+		return nil, err
+	}
+	captureResponseHeader(ctx, resp.Header)
+	if c.stats != nil {
+		c.stats.request(resp.StatusCode, time.Since(start))
+		resp.Body = &countingBody{resp.Body, c.stats, false}
+	}
+	return resp, nil
+}
+
+// Do sends a request to an arbitrary path of the server, such as a custom
+// resource or an endpoint this package does not support yet, and returns the
+// response. Unlike the other methods, Do returns a response for any status
+// code; the caller must check it and close the body.
+//
+// The request uses the configuration of the Client, including the
+// http.Client, request ID, statistics, circuit breaker, and response size
+// limit. Responses are not transcoded or checked by WithContentTypeCheck.
+// If the Client was created with WithRedactor, Do returns ErrNotRedactable.
+func (c *Client) Do(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	if err := c.redactable(); err != nil {
+		return nil, err
+	}
+	ctx, release, err := c.inflight.add(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.doBreaker(ctx, method, path, body, header)
+	if err != nil {
+		release()
+		return nil, err
 	}
-	return resp.Body, nil
+	resp.Body = &trackedBody{ReadCloser: resp.Body, ctx: ctx, release: release}
+	return resp, nil
+}
+
+// doBreaker sends the request described by Do through the circuit breaker,
+// if any.
+func (c *Client) doBreaker(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.send(ctx, method, path, body, header)
+	if c.breaker != nil {
+		outcome := err
+		if err == nil && resp.StatusCode >= 500 {
+			outcome = ClientError{StatusCode: resp.StatusCode}
+		}
+		c.breaker.record(outcome)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.maxResponseBytes > 0 {
+		resp.Body = &limitedBody{resp.Body, c.maxResponseBytes, ErrResponseTooLarge}
+	}
+	return resp, nil
 }
 
 // callString makes the given request to c and returns the result as a string
@@ -143,12 +498,7 @@ func (c *Client) callString(ctx context.Context, input io.Reader, method, path s
 	}
 	defer body.Close()
 
-	b := &strings.Builder{}
-	if _, err := io.Copy(b, body); err != nil {
-		return "", err
-	}
-
-	return b.String(), nil
+	return readString(body)
 }
 
 // Parse parses the given input, returning the body of the input as a string and an error.
@@ -157,6 +507,12 @@ func (c *Client) Parse(ctx context.Context, input io.Reader) (string, error) {
 	return c.ParseWithHeader(ctx, input, nil)
 }
 
+// ParseBytes is like Parse, but parses b. The request body is sent directly
+// from b without copying, and can be resent if the server redirects.
+func (c *Client) ParseBytes(ctx context.Context, b []byte) (string, error) {
+	return c.Parse(ctx, bytes.NewReader(b))
+}
+
 // ParseReader parses the given input, returning the body of the input as a reader and an error.
 // If the error is nil, the returned reader must be closed, else, the reader is nil.
 func (c *Client) ParseReader(ctx context.Context, input io.Reader) (io.ReadCloser, error) {
@@ -167,14 +523,21 @@ func (c *Client) ParseReader(ctx context.Context, input io.Reader) (io.ReadClose
 // If the error is not nil. the body is undefined.
 // This function also accepts a header so the caller can specify things like `Accept`
 func (c *Client) ParseWithHeader(ctx context.Context, input io.Reader, header http.Header) (string, error) {
-	return c.callString(ctx, input, "PUT", "/tika", header)
+	s, err := c.callString(ctx, input, "PUT", "/tika", header)
+	if err != nil {
+		return "", err
+	}
+	if s, err = c.processText(ctx, s); err != nil {
+		return "", err
+	}
+	return c.redactString(s), nil
 }
 
 // ParseReaderWithHeader parses the given input, returning the body of the input as a reader and an error.
 // If the error is nil, the returned reader must be closed, else, the reader is nil.
 // This function also accepts a header so the caller can specify things like `Accept`
 func (c *Client) ParseReaderWithHeader(ctx context.Context, input io.Reader, header http.Header) (io.ReadCloser, error) {
-	return c.call(ctx, input, "PUT", "/tika", header)
+	return c.redactBody(c.call(ctx, input, "PUT", "/tika", header))
 }
 
 // ParseRecursive parses the given input and all embedded documents, returning a
@@ -195,17 +558,93 @@ func (c *Client) ParseRecursive(ctx context.Context, input io.Reader) ([]string,
 	return r, nil
 }
 
+// EmbeddedContent is the content of one document returned by
+// ParseRecursiveDocs.
+type EmbeddedContent struct {
+	// Path is the embedded resource path of the document, such as
+	// "/attachment.pdf/image0.png", or "" for the container document.
+	Path string
+	// Name is the resource name of the document, if known.
+	Name string
+	// ContentType is the MIME type of the document, if known.
+	ContentType string
+	// Content is the extracted text of the document, which may be empty.
+	Content string
+}
+
+// ParseRecursiveDocs is like ParseRecursive, but returns one EmbeddedContent
+// per document in the order returned by the server, the container first,
+// including documents with no content, so each content can be traced back to
+// its attachment.
+func (c *Client) ParseRecursiveDocs(ctx context.Context, input io.Reader) ([]EmbeddedContent, error) {
+	var r []EmbeddedContent
+	err := c.MetaRecursiveStream(ctx, input, "text", func(m Metadata) error {
+		r = append(r, EmbeddedContent{
+			Path:        m.Get(XTIKAEmbeddedResourcePath),
+			Name:        m.Get("resourceName"),
+			ContentType: m.Get("Content-Type"),
+			Content:     m.Get(XTIKAContent),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
 // Meta parses the metadata from the given input, returning the metadata and an
 // error. If the error is not nil, the metadata is undefined.
+//
+// Deprecated: Meta returns the metadata as raw CSV. Use Metadata, which
+// returns parsed Metadata, or ParseMetaCSV.
 func (c *Client) Meta(ctx context.Context, input io.Reader) (string, error) {
 	return c.MetaWithHeader(ctx, input, nil)
 }
 
+// Metadata parses the metadata from the given input, returning a map from
+// metadata key to values. If the error is not nil, the metadata is undefined.
+func (c *Client) Metadata(ctx context.Context, input io.Reader) (Metadata, error) {
+	body, err := c.call(ctx, input, "PUT", "/meta", jsonHeader)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var m Metadata
+	if err := json.NewDecoder(body).Decode(&m); err != nil {
+		return nil, err
+	}
+	if c.keyMap != nil {
+		m = m.CanonicalKeys(c.keyMap)
+	}
+	if c.redactor != nil {
+		m = m.Redact(c.redactor)
+	}
+	return m, nil
+}
+
+// ParseMetaCSV parses metadata in the CSV format returned by Meta, where each
+// record is a key followed by its values.
+func ParseMetaCSV(s string) (Metadata, error) {
+	r := csv.NewReader(strings.NewReader(s))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	m := make(Metadata)
+	for _, rec := range records {
+		m[rec[0]] = append(m[rec[0]], rec[1:]...)
+	}
+	return m, nil
+}
+
 // MetaWithHeader parses the metadata from the given input, returning the metadata and an
 // error. If the error is not nil, the metadata is undefined.
 // This function also accepts a header so the caller can specify things like `Accept`
 func (c *Client) MetaWithHeader(ctx context.Context, input io.Reader, header http.Header) (string, error) {
-	return c.callString(ctx, input, "PUT", "/meta", header)
+	s, err := c.callString(ctx, input, "PUT", "/meta", header)
+	return c.redactString(s), err
 }
 
 // MetaField parses the metadata from the given input and returns the given
@@ -218,7 +657,44 @@ func (c *Client) MetaField(ctx context.Context, input io.Reader, field string) (
 // field. If the error is not nil, the result string is undefined.
 // This function also accepts a header so the caller can specify things like `Accept`
 func (c *Client) MetaFieldWithHeader(ctx context.Context, input io.Reader, field string, header http.Header) (string, error) {
-	return c.callString(ctx, input, "PUT", fmt.Sprintf("/meta/%v", field), header)
+	s, err := c.callString(ctx, input, "PUT", fmt.Sprintf("/meta/%v", field), header)
+	return c.redactString(s), err
+}
+
+// MetaFieldJSON parses the metadata from the given input and returns the
+// values of the given field. If the field is not present, MetaFieldJSON
+// returns a ClientError with StatusCode 404.
+func (c *Client) MetaFieldJSON(ctx context.Context, input io.Reader, field string) ([]string, error) {
+	body, err := c.call(ctx, input, "PUT", fmt.Sprintf("/meta/%v", field), jsonHeader)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var m Metadata
+	if err := json.NewDecoder(body).Decode(&m); err != nil {
+		return nil, err
+	}
+	if c.redactor != nil {
+		m = m.Redact(c.redactor)
+	}
+	return m[field], nil
+}
+
+// MetaFields parses the metadata from the given input and returns the values
+// of the given fields. The input is sent to the server once. Fields which are
+// not present in the metadata are omitted from the result.
+func (c *Client) MetaFields(ctx context.Context, input io.Reader, fields ...string) (Metadata, error) {
+	all, err := c.Metadata(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	m := make(Metadata, len(fields))
+	for _, f := range fields {
+		if v, ok := all[f]; ok {
+			m[f] = v
+		}
+	}
+	return m, nil
 }
 
 // Detect gets the mimetype of the given input, returning the mimetype and an
@@ -227,6 +703,51 @@ func (c *Client) Detect(ctx context.Context, input io.Reader) (string, error) {
 	return c.callString(ctx, input, "PUT", "/detect/stream", nil)
 }
 
+// DefaultDetectPrefix is the number of bytes DetectPrefix sends when n is not
+// positive.
+const DefaultDetectPrefix = 64 << 10
+
+// DetectPrefix is like Detect, but reads and sends only the first n bytes of
+// input, or DefaultDetectPrefix bytes if n <= 0. Detection by magic bytes
+// rarely needs more, so DetectPrefix saves uploading large files in full.
+// Container formats detected from their contents, such as OOXML documents
+// inside a ZIP file, may be reported as the generic container type when the
+// prefix is too short.
+func (c *Client) DetectPrefix(ctx context.Context, input io.Reader, n int64) (string, error) {
+	if n <= 0 {
+		n = DefaultDetectPrefix
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(input, n))
+	if err != nil {
+		return "", err
+	}
+	return c.Detect(ctx, bytes.NewReader(b))
+}
+
+// ParseWithDetection detects the MIME type of input from its first
+// DefaultDetectPrefix bytes, like DetectPrefix, and then parses it like Parse,
+// returning both the content and the MIME type. The prefix read for
+// detection is sent again followed by the rest of input, so input is read
+// only once and can be a stream such as an HTTP request body; only the prefix
+// is uploaded twice. The server detects the type of the full input again to
+// parse it, which can refine a generic container type reported for the
+// prefix.
+func (c *Client) ParseWithDetection(ctx context.Context, input io.Reader) (content, mimeType string, err error) {
+	b, err := ioutil.ReadAll(io.LimitReader(input, DefaultDetectPrefix))
+	if err != nil {
+		return "", "", err
+	}
+	mimeType, err = c.Detect(ctx, bytes.NewReader(b))
+	if err != nil {
+		return "", "", err
+	}
+	content, err = c.Parse(ctx, io.MultiReader(bytes.NewReader(b), input))
+	if err != nil {
+		return "", "", err
+	}
+	return content, mimeType, nil
+}
+
 // Language detects the language of the given input, returning the two letter
 // language code and an error. If the error is not nil, the language is
 // undefined.
@@ -242,6 +763,41 @@ func (c *Client) LanguageString(ctx context.Context, input string) (string, erro
 	return c.callString(ctx, r, "PUT", "/language/string", nil)
 }
 
+// DocumentLanguage is the language of the text of a single document.
+type DocumentLanguage struct {
+	// Path is the embedded resource path of the document, or empty for the
+	// container. See XTIKAEmbeddedResourcePath.
+	Path string
+	// Language is the two letter language code of the document, or empty if
+	// the document has no text.
+	Language string
+}
+
+// LanguageOfDocument parses the given input and all embedded documents, and
+// detects the language of the extracted text of each. Unlike Language, which
+// looks at the raw bytes of the input, this gives meaningful results for
+// binary formats such as PDF. The result has one element per document, in
+// the order returned by MetaRecursive. If the error is not nil, the result is
+// undefined.
+func (c *Client) LanguageOfDocument(ctx context.Context, input io.Reader) ([]DocumentLanguage, error) {
+	docs, err := c.MetaRecursive(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	var r []DocumentLanguage
+	for _, d := range docs {
+		dl := DocumentLanguage{Path: Metadata(d).Get(XTIKAEmbeddedResourcePath)}
+		if text := strings.TrimSpace(Metadata(d).Get(XTIKAContent)); text != "" {
+			dl.Language, err = c.LanguageString(ctx, text)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r = append(r, dl)
+	}
+	return r, nil
+}
+
 // MetaRecursive parses the given input and all embedded documents. The result
 // is a list of maps from metadata key to value for each document. The content
 // of each document is in the XTIKAContent field in text form. See
@@ -258,54 +814,171 @@ func (c *Client) MetaRecursive(ctx context.Context, input io.Reader) ([]map[stri
 // type of XML. See ParseRecursive to just get the content of each document. If
 // the error is not nil, the result list is undefined.
 func (c *Client) MetaRecursiveType(ctx context.Context, input io.Reader, contentType string) ([]map[string][]string, error) {
+	var r []map[string][]string
+	err := c.MetaRecursiveStream(ctx, input, contentType, func(m Metadata) error {
+		r = append(r, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Metadata is a map from metadata key to values for a single document.
+type Metadata map[string][]string
+
+// Get returns the first value of key, or an empty string if there is none.
+func (m Metadata) Get(key string) string {
+	if v := m[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// MetaRecursiveStream is like MetaRecursiveType, but decodes the response one
+// document at a time and calls fn with each, so the whole list is never held
+// in memory. If fn returns an error, MetaRecursiveStream stops and returns
+// that error.
+func (c *Client) MetaRecursiveStream(ctx context.Context, input io.Reader, contentType string, fn func(Metadata) error) error {
+	return c.metaRecursiveStream(ctx, input, contentType, nil, fn)
+}
+
+// metaRecursiveStream is like MetaRecursiveStream, but sends header with the
+// request.
+func (c *Client) metaRecursiveStream(ctx context.Context, input io.Reader, contentType string, header http.Header, fn func(Metadata) error) error {
 	path := "/rmeta"
 	if contentType != "" {
 		path = fmt.Sprintf("/rmeta/%s", contentType)
 	}
-	body, err := c.call(ctx, input, "PUT", path, nil)
+	body, err := c.call(ctx, input, "PUT", path, acceptJSON(header))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer body.Close()
-	var m []map[string]interface{}
-	if err := json.NewDecoder(body).Decode(&m); err != nil {
-		return nil, err
-	}
-	var r []map[string][]string
-	for _, d := range m {
-		doc := make(map[string][]string)
-		r = append(r, doc)
-		for k, v := range d {
-			switch vt := v.(type) {
-			case string:
-				doc[k] = []string{vt}
-			case []interface{}:
-				for _, i := range vt {
-					s, ok := i.(string)
-					if !ok {
-						return nil, fmt.Errorf("field %q has value %v and type %T, expected a string or []string", k, v, vt)
-					}
-					doc[k] = append(doc[k], s)
-				}
-			default:
-				return nil, fmt.Errorf("field %q has value %v and type %v, expected a string or []string", k, v, reflect.TypeOf(v))
+	return decodeRecursive(body, func(m Metadata) error {
+		if c.keyMap != nil {
+			m = m.CanonicalKeys(c.keyMap)
+		}
+		if m.Get(XTIKAEmbeddedResourcePath) != "" && !c.wantEmbedded(m.Get("Content-Type")) {
+			return nil
+		}
+		for i, v := range m[XTIKAContent] {
+			s, err := c.processText(ctx, v)
+			if err != nil {
+				return err
 			}
+			m[XTIKAContent][i] = s
+		}
+		if c.redactor != nil {
+			m = m.Redact(c.redactor)
+		}
+		if ws := m.Warnings(); c.strictEmbedded && len(ws) > 0 {
+			return &EmbeddedExceptionError{ws[0]}
 		}
+		return fn(m)
+	})
+}
+
+// decodeRecursive decodes the JSON array of metadata returned by /rmeta from
+// r, calling fn with each document as it is decoded.
+func decodeRecursive(r io.Reader, fn func(Metadata) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return err
 	}
-	return r, nil
+	for dec.More() {
+		var m Metadata
+		if err := dec.Decode(&m); err != nil {
+			return err
+		}
+		if m == nil {
+			m = Metadata{}
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// expectDelim reads the next token from dec and returns an error if it is not
+// the delimiter want.
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("unexpected token %v, expected %v", tok, want)
+	}
+	return nil
+}
+
+// translatePath returns the path used to translate from src to dst using t.
+// If src is empty, Tika detects the source language.
+func translatePath(t Translator, src, dst string) string {
+	if src == "" {
+		return fmt.Sprintf("/translate/all/%s/%s", t, dst)
+	}
+	return fmt.Sprintf("/translate/all/%s/%s/%s", t, src, dst)
 }
 
 // Translate returns an error and the translated input from src language to
-// dst language using t. If the error is not nil, the translation is undefined.
+// dst language using t. If src is empty, Tika detects the source language.
+// If the error is not nil, the translation is undefined.
 func (c *Client) Translate(ctx context.Context, input io.Reader, t Translator, src, dst string) (string, error) {
-	return c.callString(ctx, input, "POST", fmt.Sprintf("/translate/all/%s/%s/%s", t, src, dst), nil)
+	s, err := c.callString(ctx, input, "POST", translatePath(t, src, dst), nil)
+	return c.redactString(s), err
 }
 
 // TranslateReader translates the given input from src language to dst language using t.
+// If src is empty, Tika detects the source language.
 // It returns the translated document as a reader. If an error occurs, the reader is nil, else, the reader
 // must be closed by the caller after usage.
 func (c *Client) TranslateReader(ctx context.Context, input io.Reader, t Translator, src, dst string) (io.ReadCloser, error) {
-	return c.call(ctx, input, "POST", fmt.Sprintf("/translate/all/%s/%s/%s", t, src, dst), nil)
+	return c.redactBody(c.call(ctx, input, "POST", translatePath(t, src, dst), nil))
+}
+
+// TranslateString translates the given text from src language to dst language
+// using t. If src is empty, Tika detects the source language. If the error is
+// not nil, the translation is undefined.
+func (c *Client) TranslateString(ctx context.Context, text string, t Translator, src, dst string) (string, error) {
+	return c.Translate(ctx, strings.NewReader(text), t, src, dst)
+}
+
+// translatorProbe is the French text Translators asks candidates to
+// translate to English.
+const translatorProbe = "Bonjour tout le monde, merci beaucoup."
+
+// Translators returns the Translators from candidates that are configured and
+// usable by the Tika Server. If no candidates are given, AllTranslators are
+// checked. Tika Server has no endpoint listing translators, so each candidate
+// is probed by translating a French sentence to English; a Translator is
+// usable if it returns text other than its input, as unconfigured
+// translators return their input unchanged. Probes may be billed by paid
+// translation services. Errors other than a ClientError from a probe are
+// returned, in which case the result is undefined.
+func (c *Client) Translators(ctx context.Context, candidates ...Translator) ([]Translator, error) {
+	if len(candidates) == 0 {
+		candidates = AllTranslators
+	}
+	var r []Translator
+	for _, t := range candidates {
+		out, err := c.TranslateString(ctx, translatorProbe, t, "fr", "en")
+		var tikaErr ClientError
+		if errors.As(err, &tikaErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if out = strings.TrimSpace(out); out == "" || strings.EqualFold(out, translatorProbe) {
+			continue
+		}
+		r = append(r, t)
+	}
+	return r, nil
 }
 
 // Version returns the default hello message from Tika server.
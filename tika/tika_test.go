@@ -21,12 +21,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // errorServer always responds with http.StatusInternalServerError.
@@ -99,6 +103,31 @@ func TestParseWithHeader(t *testing.T) {
 	}
 }
 
+func TestParserSelectionHeaders(t *testing.T) {
+	var gotSkip []string
+	var gotForce string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSkip = r.Header.Values(SkipParsersHeader)
+		gotForce = r.Header.Get(ForceParserHeader)
+		fmt.Fprint(w, "test value")
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+
+	wantSkip := []string{"org.example.A", "org.example.B"}
+	wantForce := "org.example.C"
+	hdr := ForceParser(SkipParsers(nil, wantSkip...), wantForce)
+	if _, err := c.ParseWithHeader(context.Background(), nil, hdr); err != nil {
+		t.Fatalf("ParseWithHeader returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(gotSkip, wantSkip) {
+		t.Errorf("ParseWithHeader sent %s %v, want %v", SkipParsersHeader, gotSkip, wantSkip)
+	}
+	if gotForce != wantForce {
+		t.Errorf("ParseWithHeader sent %s %q, want %q", ForceParserHeader, gotForce, wantForce)
+	}
+}
+
 func TestParseReader(t *testing.T) {
 	want := "test value"
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -120,6 +149,32 @@ func TestParseReader(t *testing.T) {
 	}
 }
 
+func TestParseReaderCancel(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "partial")
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+	c := NewClient(nil, ts.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	body, err := c.ParseReader(ctx, nil)
+	if err != nil {
+		t.Fatalf("ParseReader returned an error: %v", err)
+	}
+	defer body.Close()
+	buf := make([]byte, len("partial"))
+	if _, err := io.ReadFull(body, buf); err != nil {
+		t.Fatalf("Reading the returned body failed: %v", err)
+	}
+	cancel()
+	if _, err := ioutil.ReadAll(body); !errors.Is(err, context.Canceled) {
+		t.Errorf("Reading the body after cancel got error %v, want %v", err, context.Canceled)
+	}
+}
+
 func TestParseRecursive(t *testing.T) {
 	tests := []struct {
 		response   string
@@ -183,6 +238,31 @@ func TestParseRecursiveError(t *testing.T) {
 	}
 }
 
+func TestParseRecursiveDocs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"X-TIKA:content":"body","Content-Type":"message/rfc822"},`+
+			`{"X-TIKA:embedded_resource_path":"/a.png","resourceName":"a.png","Content-Type":"image/png"},`+
+			`{"X-TIKA:embedded_resource_path":"/b.txt","resourceName":"b.txt","X-TIKA:content":"b"}]`)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	got, err := c.ParseRecursiveDocs(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ParseRecursiveDocs got error: %v", err)
+	}
+	want := []EmbeddedContent{
+		{ContentType: "message/rfc822", Content: "body"},
+		{Path: "/a.png", Name: "a.png", ContentType: "image/png"},
+		{Path: "/b.txt", Name: "b.txt", Content: "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRecursiveDocs = %+v, want %+v", got, want)
+	}
+	if _, err := errorClient.ParseRecursiveDocs(context.Background(), nil); err == nil {
+		t.Error("ParseRecursiveDocs got no error, want an error")
+	}
+}
+
 func TestMeta(t *testing.T) {
 	want := "test value"
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -199,6 +279,93 @@ func TestMeta(t *testing.T) {
 	}
 }
 
+func TestMetadata(t *testing.T) {
+	var gotAccept string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept")
+		fmt.Fprint(w, `{"Content-Type":"application/pdf","dc:creator":["A","B"]}`)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	got, err := c.Metadata(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Metadata returned an error: %v", err)
+	}
+	want := Metadata{"Content-Type": {"application/pdf"}, "dc:creator": {"A", "B"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Metadata got %+v, want %+v", got, want)
+	}
+	if gotAccept != "application/json" {
+		t.Errorf("Metadata sent Accept %q, want %q", gotAccept, "application/json")
+	}
+	if _, err := errorClient.Metadata(context.Background(), nil); err == nil {
+		t.Error("Metadata got no error, want an error")
+	}
+}
+
+func TestMetaFieldJSON(t *testing.T) {
+	var gotPath, gotAccept string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAccept = r.Header.Get("Accept")
+		fmt.Fprint(w, `{"dc:creator":["A","B"]}`)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	got, err := c.MetaFieldJSON(context.Background(), nil, "dc:creator")
+	if err != nil {
+		t.Fatalf("MetaFieldJSON returned an error: %v", err)
+	}
+	if want := []string{"A", "B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MetaFieldJSON got %v, want %v", got, want)
+	}
+	if want := "/meta/dc:creator"; gotPath != want {
+		t.Errorf("MetaFieldJSON requested %q, want %q", gotPath, want)
+	}
+	if gotAccept != "application/json" {
+		t.Errorf("MetaFieldJSON sent Accept %q, want %q", gotAccept, "application/json")
+	}
+}
+
+func TestMetaFields(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"Author":"A","Content-Type":"text/plain","Created":"2017","Other":"x"}`)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	got, err := c.MetaFields(context.Background(), nil, "Author", "Created", "Content-Type", "Missing")
+	if err != nil {
+		t.Fatalf("MetaFields returned an error: %v", err)
+	}
+	want := Metadata{"Author": {"A"}, "Created": {"2017"}, "Content-Type": {"text/plain"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MetaFields got %+v, want %+v", got, want)
+	}
+	if calls != 1 {
+		t.Errorf("MetaFields made %d requests, want 1", calls)
+	}
+	if _, err := errorClient.MetaFields(context.Background(), nil, "Author"); err == nil {
+		t.Error("MetaFields got no error, want an error")
+	}
+}
+
+func TestParseMetaCSV(t *testing.T) {
+	in := "\"Content-Type\",\"application/pdf\"\n\"dc:creator\",\"Doe, Jane\",\"B \"\"Q\"\" C\"\n"
+	got, err := ParseMetaCSV(in)
+	if err != nil {
+		t.Fatalf("ParseMetaCSV returned an error: %v", err)
+	}
+	want := Metadata{"Content-Type": {"application/pdf"}, "dc:creator": {"Doe, Jane", `B "Q" C`}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMetaCSV got %+v, want %+v", got, want)
+	}
+	if _, err := ParseMetaCSV(`"unterminated`); err == nil {
+		t.Error("ParseMetaCSV got no error for invalid CSV")
+	}
+}
+
 func TestMetaWithHeader(t *testing.T) {
 	want := "test value"
 	wantHeader := "application/json"
@@ -280,6 +447,62 @@ func TestDetect(t *testing.T) {
 	}
 }
 
+func TestDetectPrefix(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprint(w, len(b))
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	tests := []struct {
+		size int
+		n    int64
+		want string
+	}{
+		{size: 10, n: 4, want: "4"},
+		{size: 3, n: 4, want: "3"},
+		{size: DefaultDetectPrefix + 1, n: 0, want: fmt.Sprint(DefaultDetectPrefix)},
+	}
+	for _, test := range tests {
+		input := strings.NewReader(strings.Repeat("x", test.size))
+		got, err := c.DetectPrefix(context.Background(), input, test.n)
+		if err != nil {
+			t.Errorf("DetectPrefix(%d bytes, %d) got error: %v", test.size, test.n, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("DetectPrefix(%d bytes, %d) sent %s bytes, want %s", test.size, test.n, got, test.want)
+		}
+	}
+}
+
+func TestParseWithDetection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/detect/stream":
+			fmt.Fprintf(w, "detected %d bytes", len(b))
+		case "/tika":
+			fmt.Fprintf(w, "parsed %d bytes", len(b))
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	size := DefaultDetectPrefix + 10
+	// Hide the Len method of strings.Reader to test a stream.
+	input := struct{ io.Reader }{strings.NewReader(strings.Repeat("x", size))}
+	content, mimeType, err := c.ParseWithDetection(context.Background(), input)
+	if err != nil {
+		t.Fatalf("ParseWithDetection got error: %v", err)
+	}
+	if want := fmt.Sprintf("detected %d bytes", DefaultDetectPrefix); mimeType != want {
+		t.Errorf("ParseWithDetection got type %q, want %q", mimeType, want)
+	}
+	if want := fmt.Sprintf("parsed %d bytes", size); content != want {
+		t.Errorf("ParseWithDetection got content %q, want %q", content, want)
+	}
+}
+
 func TestLanguage(t *testing.T) {
 	want := "test value"
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -312,6 +535,39 @@ func TestLanguageString(t *testing.T) {
 	}
 }
 
+func TestLanguageOfDocument(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rmeta/text":
+			fmt.Fprint(w, `[{"X-TIKA:content":"bonjour"},{"X-TIKA:embedded_resource_path":"/a.txt","X-TIKA:content":"hello"},{"X-TIKA:embedded_resource_path":"/b.png"}]`)
+		case "/language/string":
+			b, _ := ioutil.ReadAll(r.Body)
+			if string(b) == "bonjour" {
+				fmt.Fprint(w, "fr")
+			} else {
+				fmt.Fprint(w, "en")
+			}
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	got, err := c.LanguageOfDocument(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("LanguageOfDocument returned an error: %v", err)
+	}
+	want := []DocumentLanguage{
+		{Language: "fr"},
+		{Path: "/a.txt", Language: "en"},
+		{Path: "/b.png"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LanguageOfDocument got %+v, want %+v", got, want)
+	}
+	if _, err := errorClient.LanguageOfDocument(context.Background(), nil); err == nil {
+		t.Error("LanguageOfDocument got no error, want an error")
+	}
+}
+
 func TestMetaRecursive(t *testing.T) {
 	tests := []struct {
 		response string
@@ -362,6 +618,44 @@ func TestMetaRecursive(t *testing.T) {
 		}
 	}
 }
+func TestMetaRecursiveStream(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"X-TIKA:content":"test 1"},{"X-TIKA:content":"test 2"},{"X-TIKA:content":"test 3"}]`)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+
+	var got []Metadata
+	err := c.MetaRecursiveStream(context.Background(), nil, "text", func(m Metadata) error {
+		got = append(got, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("MetaRecursiveStream returned an error: %v", err)
+	}
+	want := []Metadata{
+		{"X-TIKA:content": {"test 1"}},
+		{"X-TIKA:content": {"test 2"}},
+		{"X-TIKA:content": {"test 3"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MetaRecursiveStream got %+v, want %+v", got, want)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = c.MetaRecursiveStream(context.Background(), nil, "text", func(Metadata) error {
+		calls++
+		return stop
+	})
+	if err != stop {
+		t.Errorf("MetaRecursiveStream got error %v, want %v", err, stop)
+	}
+	if calls != 1 {
+		t.Errorf("MetaRecursiveStream called fn %d times after an error, want 1", calls)
+	}
+}
+
 func TestMetaRecursiveType(t *testing.T) {
 	const (
 		// Distilled forms of the X-TIKA:content in actual Tika responses.
@@ -495,6 +789,80 @@ func TestTranslateReader(t *testing.T) {
 	}
 }
 
+func TestTranslateString(t *testing.T) {
+	tests := []struct {
+		src      string
+		wantPath string
+	}{
+		{"src", "/translate/all/translator/src/dst"},
+		{"", "/translate/all/translator/dst"},
+	}
+	for _, test := range tests {
+		var gotPath, gotBody string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			b, _ := ioutil.ReadAll(r.Body)
+			gotBody = string(b)
+			fmt.Fprint(w, "translated")
+		}))
+		defer ts.Close()
+		c := NewClient(nil, ts.URL)
+		got, err := c.TranslateString(context.Background(), "text", "translator", test.src, "dst")
+		if err != nil {
+			t.Errorf("TranslateString(%q) returned an error: %v", test.src, err)
+			continue
+		}
+		if got != "translated" {
+			t.Errorf("TranslateString(%q) got %q, want %q", test.src, got, "translated")
+		}
+		if gotPath != test.wantPath {
+			t.Errorf("TranslateString(%q) requested %q, want %q", test.src, gotPath, test.wantPath)
+		}
+		if gotBody != "text" {
+			t.Errorf("TranslateString(%q) sent %q, want %q", test.src, gotBody, "text")
+		}
+	}
+}
+
+func TestTranslators(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, string(MosesTranslator)):
+			w.Write([]byte("Hello everyone, thank you very much.\n"))
+		case strings.Contains(r.URL.Path, string(Lingo24Translator)):
+			// Unconfigured translators echo their input.
+			io.Copy(w, r.Body)
+		case strings.Contains(r.URL.Path, string(JoshuaTranslator)):
+			// Nor is an empty translation usable.
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	got, err := c.Translators(context.Background())
+	if err != nil {
+		t.Fatalf("Translators returned an error: %v", err)
+	}
+	if want := []Translator{MosesTranslator}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Translators got %v, want %v", got, want)
+	}
+	got, err = c.Translators(context.Background(), GoogleTranslator)
+	if err != nil {
+		t.Fatalf("Translators(%v) returned an error: %v", GoogleTranslator, err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Translators(%v) got %v, want none", GoogleTranslator, got)
+	}
+}
+
+func TestTranslatorsError(t *testing.T) {
+	c := NewClient(nil, "https://unknown_test_url")
+	if _, err := c.Translators(context.Background()); err == nil {
+		t.Error("Translators got no error, want an error")
+	}
+}
+
 func TestParsers(t *testing.T) {
 	tests := []struct {
 		response string
@@ -802,3 +1170,71 @@ func TestDetectorsError(t *testing.T) {
 		t.Errorf("Detectors got no error, want an error")
 	}
 }
+
+func TestNewClientURL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "http://localhost:9998", want: "http://localhost:9998"},
+		{in: "localhost:9998", want: "http://localhost:9998"},
+		{in: "HTTPS://example.com/tika/", want: "https://example.com/tika"},
+		{in: " http://example.com// ", want: "http://example.com"},
+		{in: "", wantErr: true},
+		{in: "ftp://example.com", wantErr: true},
+		{in: "http://", wantErr: true},
+		{in: "http://[::1", wantErr: true},
+		{in: "http://example.com/?q=1", wantErr: true},
+	}
+	for _, test := range tests {
+		c, err := NewClientURL(nil, test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("NewClientURL(%q) got no error, want an error", test.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewClientURL(%q) got error: %v", test.in, err)
+			continue
+		}
+		if c.url != test.want {
+			t.Errorf("NewClientURL(%q) URL got %q, want %q", test.in, c.url, test.want)
+		}
+	}
+}
+
+// TestConcurrentUse is meant to be run with -race.
+func TestConcurrentUse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer ts.Close()
+	stats := &Stats{}
+	c := NewClient(nil, ts.URL,
+		WithStats(stats),
+		WithCircuitBreaker(100, time.Second),
+		WithTextOptions(TextOptions{CollapseWhitespace: true}),
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			want := fmt.Sprint(i)
+			got, err := c.Parse(context.Background(), strings.NewReader(want))
+			if err != nil {
+				t.Errorf("Parse(%q) got error: %v", want, err)
+				return
+			}
+			if got != want {
+				t.Errorf("Parse(%q) = %q, want %q", want, got, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := stats.Snapshot().Requests; got != 20 {
+		t.Errorf("Snapshot().Requests = %d, want 20", got)
+	}
+}
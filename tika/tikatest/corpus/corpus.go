@@ -0,0 +1,113 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package corpus provides small sample documents of common formats, and
+// helpers comparing what a Tika Server extracts from them with golden files,
+// so programs using package tika can check that their pipelines still work
+// after a Tika upgrade.
+//
+// A regression test parses each document and compares the result with a
+// golden file checked in next to the test:
+//
+//	func TestExtraction(t *testing.T) {
+//	    c := tika.NewClient(nil, serverURL)
+//	    for _, d := range corpus.All() {
+//	        corpus.AssertParseMatches(t, c, d, filepath.Join("testdata", d.Name+".golden"))
+//	    }
+//	}
+//
+// Run the test with TIKATEST_UPDATE=1 to write the golden files.
+package corpus
+
+//go:generate go run gen.go
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// A Document is a sample document.
+type Document struct {
+	// Name is the file name of the document.
+	Name string
+	// MIMEType is the MIME type Tika detects for the document.
+	MIMEType string
+	// Text is a phrase in the text Tika extracts from the document, or ""
+	// if it has none, as for images without OCR and archives.
+	Text string
+	// Embedded is a phrase in the text of a document embedded in the
+	// document, or "" if it has none.
+	Embedded string
+}
+
+// Bytes returns the content of d.
+func (d Document) Bytes() []byte {
+	s, ok := files[d.Name]
+	if !ok {
+		panic("corpus: unknown document " + d.Name)
+	}
+	return []byte(s)
+}
+
+// Reader returns a reader of the content of d, which can be passed to the
+// methods of tika.Client, including those needing an io.ReadSeeker.
+func (d Document) Reader() *bytes.Reader {
+	return bytes.NewReader(d.Bytes())
+}
+
+// Open implements the Open function of a tika.Input for d.
+func (d Document) Open() (io.ReadCloser, error) {
+	return ioutil.NopCloser(d.Reader()), nil
+}
+
+// phrase is in the text of every document with text.
+const phrase = "go-tika integration fixture"
+
+// The sample documents.
+var (
+	// PDF is a one-page PDF with a title.
+	PDF = Document{Name: "sample.pdf", MIMEType: "application/pdf", Text: "PDF " + phrase}
+	// DOCX is a Word document with one paragraph.
+	DOCX = Document{Name: "sample.docx", MIMEType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Text: "DOCX " + phrase}
+	// EML is an email with a text attachment.
+	EML = Document{Name: "sample.eml", MIMEType: "message/rfc822", Text: "Email body " + phrase, Embedded: "Attachment " + phrase}
+	// XLSX is a spreadsheet with one sheet holding a string and a number.
+	XLSX = Document{Name: "sample.xlsx", MIMEType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Text: "XLSX " + phrase}
+	// PNG is a 2x2 image whose EXIF data has the Make "go-tika" and the
+	// Model "corpus".
+	PNG = Document{Name: "sample.png", MIMEType: "image/png"}
+	// TXT is a plain text file. Like CSV, it is not recognizable without its
+	// name.
+	TXT = Document{Name: "sample.txt", MIMEType: "text/plain", Text: "Plain text " + phrase}
+	// CSV is a CSV file with a header and two rows.
+	CSV = Document{Name: "sample.csv", MIMEType: "text/plain", Text: "CSV " + phrase}
+	// HTML is a web page with a title, a heading, and a link.
+	HTML = Document{Name: "sample.html", MIMEType: "text/html", Text: "HTML body " + phrase}
+	// XML is an XML document.
+	XML = Document{Name: "sample.xml", MIMEType: "application/xml", Text: "XML body " + phrase}
+	// RTF is a rich text document.
+	RTF = Document{Name: "sample.rtf", MIMEType: "application/rtf", Text: "RTF " + phrase}
+	// ODT is an OpenDocument text document.
+	ODT = Document{Name: "sample.odt", MIMEType: "application/vnd.oasis.opendocument.text", Text: "ODT " + phrase}
+	// ZIP is a ZIP archive holding a text file.
+	ZIP = Document{Name: "sample.zip", MIMEType: "application/zip", Embedded: "Zipped text " + phrase}
+)
+
+// All returns every sample document.
+func All() []Document {
+	return []Document{PDF, DOCX, EML, XLSX, PNG, TXT, CSV, HTML, XML, RTF, ODT, ZIP}
+}
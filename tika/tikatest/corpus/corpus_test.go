@@ -0,0 +1,145 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package corpus
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-tika/tika"
+)
+
+func TestAll(t *testing.T) {
+	magic := map[string]string{
+		"application/pdf": "%PDF-",
+		"image/png":       "\x89PNG",
+		"message/rfc822":  "From:",
+		"application/zip": "PK",
+		"application/rtf": "{\\rtf",
+		"application/xml": "<?xml",
+	}
+	for _, d := range All() {
+		b := d.Bytes()
+		if len(b) == 0 {
+			t.Errorf("%s is empty", d.Name)
+		}
+		if m, ok := magic[d.MIMEType]; ok && !bytes.HasPrefix(b, []byte(m)) {
+			t.Errorf("%s does not start with %q", d.Name, m)
+		}
+		if (strings.Contains(d.MIMEType, "openxmlformats") || strings.Contains(d.MIMEType, "opendocument")) && !bytes.HasPrefix(b, []byte("PK")) {
+			t.Errorf("%s is not a ZIP file", d.Name)
+		}
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skipf("go tool not found: %v", err)
+	}
+	dir, err := ioutil.TempDir("", "corpus")
+	if err != nil {
+		t.Fatalf("TempDir got error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "data.go")
+	if b, err := exec.Command(goTool, "run", "gen.go", "-o", out).CombinedOutput(); err != nil {
+		t.Fatalf("go run gen.go got error: %v\n%s", err, b)
+	}
+	got, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile("data.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("data.go is out of date with the files directory; run go generate")
+	}
+}
+
+// recorder is a testing.TB recording errors instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertMatches(t *testing.T) {
+	text := "  PDF go-tika\n\n\n integration fixture "
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/meta" {
+			fmt.Fprint(w, `{"Content-Type": ["application/pdf"], "dc:title": ["PDF fixture"], "date": ["now"]}`)
+			return
+		}
+		fmt.Fprint(w, text)
+	}))
+	defer ts.Close()
+	c := tika.NewClient(nil, ts.URL)
+	dir, err := ioutil.TempDir("", "corpus")
+	if err != nil {
+		t.Fatalf("TempDir got error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	parseGolden := filepath.Join(dir, "pdf.txt")
+	metaGolden := filepath.Join(dir, "pdf.json")
+
+	r := &recorder{TB: t}
+	AssertParseMatches(r, c, PDF, parseGolden)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], UpdateEnv) {
+		t.Errorf("AssertParseMatches without a golden file reported %q", r.errors)
+	}
+
+	Update = true
+	AssertParseMatches(t, c, PDF, parseGolden)
+	AssertMetadataMatches(t, c, PDF, metaGolden, "dc:title", "Content-Type", "missing")
+	Update = false
+	b, err := ioutil.ReadFile(parseGolden)
+	if want := "PDF go-tika\n\nintegration fixture\n"; err != nil || string(b) != want {
+		t.Errorf("AssertParseMatches wrote %q, %v, want %q", b, err, want)
+	}
+	b, err = ioutil.ReadFile(metaGolden)
+	if want := "{\n  \"Content-Type\": [\n    \"application/pdf\"\n  ],\n  \"dc:title\": [\n    \"PDF fixture\"\n  ]\n}\n"; err != nil || string(b) != want {
+		t.Errorf("AssertMetadataMatches wrote %q, %v, want %q", b, err, want)
+	}
+
+	// Whitespace changes do not matter.
+	text = "PDF go-tika \n\nintegration fixture"
+	AssertParseMatches(t, c, PDF, parseGolden)
+	AssertMetadataMatches(t, c, PDF, metaGolden, "dc:title", "Content-Type", "missing")
+
+	text = "PDF go-tika changed"
+	r = &recorder{TB: t}
+	AssertParseMatches(r, c, PDF, parseGolden)
+	if len(r.errors) != 1 {
+		t.Errorf("AssertParseMatches with different text reported %q, want one error", r.errors)
+	}
+}
@@ -0,0 +1,18 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package corpus
+
+var files = map[string]string{
+	"sample.csv":  "name,description\nfirst,CSV go-tika integration fixture\nsecond,another row\n",
+	"sample.docx": "PK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\xc9O\x1a\xb0\xeb\x00\x00\x00\xae\x01\x00\x00\x13\x00\x00\x00[Content_Types].xml}P\xbdN\xc30\x10\xdey\n\xcb+\x8a\x1d\x18\x10BI:\xf03\x02Cy\x80\x93}I,\xec\xb3\xe5sK\xfb\xf68m\xe9\x80\n\xe3\xdd\xf7\xab\xaf[\xed\x82\x17[\xcc\xec\"\xf5\xf2F\xb5R \x99h\x1dM\xbd\xfcX\xbf4\xf7Rp\x01\xb2\xe0#a/\xf7\xc8r5\\u\xeb}B\x16UL\xdc˹\x94\xf4\xa05\x9b\x19\x03\xb0\x8a\t\xa9\"c\xcc\x01J=\xf3\xa4\x13\x98O\x98P߶\xed\x9d6\x91\nRi\xca\xe2!\x87\xee\tG\xd8\xf8\"\x9ew\xf5},\x92ѳ\x14\x8fG\xe2\x92\xd5KH\xc9;\x03\xa5\xe2zK\xf6WJsJPUy\xe0\xf0\xec\x12_W\x82\xd4\x17\x13\x16\xe4\uf013\xee\xad.\x93\x9dE\xf1\x0e\xb9\xbcB\xa8,\xfd\x15\xb3\xd56\x9aM\xa8J\xf5\xbfͅ\x9eq\x1c\x9d\xc1\xb3~qK9\x1ad\xae\x93\a\xaf\xceH\x00G?\xfd\xf5a\xee\xe1\x1bPK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\xb9\x81Dq\xb0\x00\x00\x00*\x01\x00\x00\v\x00\x00\x00_rels/.rels\x8d\xcf;\x0e\xc20\f\x06\xe0\x9dSD\xdeiZ\x06\x84P\x93.\b\xa9+*\a\x88\x127\x8dh\x1eJ£\xb7'\x03\x03 \x06Fۿ?\xcbm\xf7\xb03\xb9aL\xc6;\x06MU\x03A'\xbd2N38\x0f\xc7\xf5\x0eH\xca\xc2)1{\x87\f\x16L\xd0\xf1U{\xc2Y䲓&\x13\x12)\x88K\f\xa6\x9cÞ\xd2$'\xb4\"U>\xa0+\x93\xd1G+r)\xa3\xa6Aȋ\xd0H7u\xbd\xa5\xf1\xdd\x00\xfea\x92^1\x88\xbdj\x80\fK\xc0\x7fl?\x8eF\xe2\xc1˫E\x97\x7f\x9c\xf8J\x14YD\x8d\x99\xc1\xddGEի]\x15\x16(o\xe9ǋ\xfc\tPK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\xd4\xd8\x00)\xb5\x00\x00\x00\xeb\x00\x00\x00\x11\x00\x00\x00word/document.xmlE\x8e\xb1\x8e\xc20\f\x86\xf7{\x8a(;Ma8\x9d\xaa\xb6\f\x87XY\x0e\xe9\xd6И\x12]cG\x8eK\xcbۓ\xc0p\xcbgY\xbf\xf5\xf9o\xf7k\x98\xd4\x1d8y\xc2No\xabZ+\xc0\x81\x9cǱ\xd3\xe7\x9f\xe3\xe6K\xab$\x16\x9d\x9d\b\xa1\xd3\x0fHz\xdf\x7f\xb4K\xe3h\x98\x03\xa0\xa8l\xc0\xd4,\x9d\xbe\x89\xc4Ƙ4\xdc \xd8TQ\x04\xccٕ8X\xc9+\x8ff!v\x91i\x80\x94\xf2\x830\x99]]\x7f\x9a`=\xea>+/\xe4\x1ee\xc6\x02.\x90\xfep\xfa\xfeU#m\xc4\xffY\xe5Q`d+\xb9\xac\xba\xfaUf\x86\xaa5嬐_\x8c/\xbeU\xe6\xbff\xff\x04PK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\xc9O\x1a\xb0\xeb\x00\x00\x00\xae\x01\x00\x00\x13\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\x00\x00\x00\x00[Content_Types].xmlPK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\xb9\x81Dq\xb0\x00\x00\x00*\x01\x00\x00\v\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\x1c\x01\x00\x00_rels/.relsPK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\xd4\xd8\x00)\xb5\x00\x00\x00\xeb\x00\x00\x00\x11\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\xf5\x01\x00\x00word/document.xmlPK\x05\x06\x00\x00\x00\x00\x03\x00\x03\x00\xb9\x00\x00\x00\xd9\x02\x00\x00\x00\x00",
+	"sample.eml":  "From: Alice <alice@example.com>\r\nTo: Bob <bob@example.com>\r\nSubject: Email go-tika integration fixture\r\nDate: Tue, 06 Oct 2026 10:00:00 +0000\r\nMessage-ID: <fixture@example.com>\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=\"BOUNDARY\"\r\n\r\n--BOUNDARY\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\nEmail body go-tika integration fixture.\r\n\r\n--BOUNDARY\r\nContent-Type: text/plain; charset=UTF-8\r\nContent-Disposition: attachment; filename=\"attachment.txt\"\r\n\r\nAttachment go-tika integration fixture.\r\n\r\n--BOUNDARY--\r\n",
+	"sample.html": "<!DOCTYPE html>\n<html><head><title>HTML go-tika integration fixture</title></head>\n<body><h1>Heading</h1><p>HTML body go-tika integration fixture.</p><p><a href=\"https://example.com/\">A link</a></p></body></html>\n",
+	"sample.odt":  "PK\x03\x04\x14\x00\x00\x00\x00\x00\x00\x00!\\^\xc62\f'\x00\x00\x00'\x00\x00\x00\b\x00\x00\x00mimetypeapplication/vnd.oasis.opendocument.textPK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\xa0\xf9\n\xa2\xb2\x00\x00\x00j\x01\x00\x00\x15\x00\x00\x00META-INF/manifest.xml\x8dP\xcd\n\xc20\f\xbe\xfb\x14#\xf7\xad\xeaI\x8a\x9d7\x9f@\x1f\xa0t\x99\x16ڴ\xac\xd9\xd8\xde\xden\xa0ND\xf0\x96\x8f|\x7f\xc9\xf14zW\f\xd8%\x1bH\xc1\xae\xdaB\x81dBc\xe9\xa6\xe0z9\x97\a8՛\xa3\xd7d[L,\x9fC\x91u\x94^PAߑ\f:\xd9$I{L\x92\x8d\f\x11\xa9\t\xa6\xf7H,?\xf9rIz\xa1U\x81=\xd4\xef\xb4\xd6:,\xb3\xba\x9b\xdeܶw\xae\x8c\x9a\xef\n\xc4\xca\xc2ccu\xc9SD\x05:Fg\x8d\xe6l)\x06j\xaa\xa5W\xb5\xaeS1\x8e\f\xe2\xff(\x13\x88g]>\xe3G\xe8\xec(\xe6uv\x15_\xff\xaa\x1fPK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\xb1\xa3\x18\x88\xb0\x00\x00\x00R\x01\x00\x00\v\x00\x00\x00content.xml\x8d\x90=\x0e\xc20\f\x85wN\x11eo\x03L(J\xd3\x05\xb1\xb2\x94\x03\x844\xad\"\xa8]%)*\xb7'\xfdC\xed\x80\xc4d\xd9~\x9f\xfdl\x91\xf7͓\xbc\x8c\xf3\x16!\xa3\x87tO\x89\x01\x8d\xa5\x85:\xa3\xb7⒜h.w\x02\xab\xcaj\xc3K\xd4]c $\x1a!\xc4H\"\r\x9eO\u074cv\x0e8*o=\a\xd5\x18σ\xe6\xd8\x1aX(\xbeV\xf3q\xd7T\t\xa6\x0f\xff҃vb\xe79+\xf3G*\x17\xa7w,\xdf\xdfd`\xa4\x18\xc9V^\xcf\x05\xa91\t\xf6\xa1\x88\x8dW\xd4N\x85ȓ\xca\xf6\xa1s&\x15l\x16\n\xb6\xc1\xd9f2\xfb\xf1\x11\xf9\x01PK\x01\x02\x14\x03\x14\x00\x00\x00\x00\x00\x00\x00!\\^\xc62\f'\x00\x00\x00'\x00\x00\x00\b\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\x00\x00\x00\x00mimetypePK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\xa0\xf9\n\xa2\xb2\x00\x00\x00j\x01\x00\x00\x15\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01M\x00\x00\x00META-INF/manifest.xmlPK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\xb1\xa3\x18\x88\xb0\x00\x00\x00R\x01\x00\x00\v\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x012\x01\x00\x00content.xmlPK\x05\x06\x00\x00\x00\x00\x03\x00\x03\x00\xb2\x00\x00\x00\v\x02\x00\x00\x00\x00",
+	"sample.pdf":  "%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>\nendobj\n4 0 obj\n<< /Length 63 >>\nstream\nBT /F1 12 Tf 72 720 Td (PDF go-tika integration fixture.) Tj ET\nendstream\nendobj\n5 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>\nendobj\n6 0 obj\n<< /Title (PDF fixture) /Producer (go-tika) >>\nendobj\nxref\n0 7\n0000000000 65535 f \n0000000009 00000 n \n0000000058 00000 n \n0000000115 00000 n \n0000000241 00000 n \n0000000354 00000 n \n0000000424 00000 n \ntrailer\n<< /Size 7 /Root 1 0 R /Info 6 0 R >>\nstartxref\n486\n%%EOF\n",
+	"sample.png":  "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x02\x00\x00\x00\x02\b\x02\x00\x00\x00\xfdԚs\x00\x00\x00ReXIfMM\x00*\x00\x00\x00\b\x00\x03\x01\x0f\x00\x02\x00\x00\x00\b\x00\x00\x002\x01\x10\x00\x02\x00\x00\x00\a\x00\x00\x00:\x011\x00\x02\x00\x00\x00\x0f\x00\x00\x00B\x00\x00\x00\x00go-tika\x00corpus\x00\x00go-tika corpus\x00\x00\xde_\x10\xf0\x00\x00\x00'tEXtComment\x00PNG go-tika integration fixturew\x96\x8d\x8a\x00\x00\x00\x10IDATx\x9cc\xf8\xcf\xc0\x00D\f\x10\n\x00\x1f\xee\x03\xfd\x8b_\x14\xd4\x00\x00\x00\x00IEND\xaeB`\x82",
+	"sample.rtf":  "{\\rtf1\\ansi\\deff0{\\fonttbl{\\f0 Times New Roman;}}\\f0\\fs24 RTF go-tika integration fixture.\\par}\n",
+	"sample.txt":  "Plain text go-tika integration fixture.\n",
+	"sample.xlsx": "PK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\xc5/\x1d}\x00\x01\x00\x00.\x02\x00\x00\x13\x00\x00\x00[Content_Types].xml\xad\x91\xcdN\xc30\x10\x84\xef<\x85\xe5k\x15;\xe5\x80\x10J\xd2C\x81#p(\x0f\xb08\x9bĊ\xff\xe4uK\xfa\xf68i\xe1\x80\n\\8\xad\xec\x99\xd9odW\x9b\xc9\x1av\xc0Hڻ\x9a\xafE\xc9\x19:\xe5[\xed\xfa\x9a\xbf\xee\x1e\x8b[\xce(\x81k\xc1x\x875?\"\xf1MsU\xed\x8e\x01\x89尣\x9a\x0f)\x85;)I\rh\x81\x84\x0f\xe8\xb2\xd2\xf9h!\xe5c\xece\x005B\x8f\xf2\xba,o\xa4\xf2.\xa1KE\x9aw\xf0\xa6\xba\xc7\x0e\xf6&\xb1\x87)_\x9f\x8aD4\xc4\xd9\xf6d\x9cY5\x87\x10\x8cV\x90\xb2.\x0f\xae\xfdF)\xce\x04\x91\x93\x8b\x87\x06\x1dh\x95\r\\^$\xcc\xcaπs\xee9\xbfL\xd4-\xb2\x17\x88\xe9\tlv\xc9\xc9\xc8w\x1f\xc77\xefG\xf1\xfb\x92\v-}\xd7i\x85\xadW{\x9b#\x82BDhi@Lֈe\n\vڭ\xfe\xe6/f\x92\xcbX\xffs\x91\xaf\xfd\x9f=\xe4\xf2\xdd\xcd\aPK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\x06Yǂ\xb1\x00\x00\x00(\x01\x00\x00\v\x00\x00\x00_rels/.rels\x8dϱ\x0e\x820\x10\x06\xe0ݧhn\x97\x82\x831\x86\xc2bLX\r>@m\x8fB\x80^\xd3V\x85\xb7\xb7\xa3\x1a\a\xc7\xcb\xfd\xf7\xfd\xb9\xb2^\xe6\x89=Ї\x81\xac\x80\"ˁ\xa1U\xa4\ak\x04\\\xdb\xf3\xf6\x00,Di\xb5\x9cȢ\x80\x15\x03\xd4զ\xbc\xe0$c\xba\t\xfd\xe0\x02K\x88\r\x02\xfa\x18ݑ\xf3\xa0z\x9ce\xc8ȡM\x9b\x8e\xfc,c\x1a\xbd\xe1N\xaaQ\x1a\xe4\xbb<\xdfs\xffn@\xf5a\xb2F\v\xf0\x8d.\x80\xb5\xab\xc3\x7fl\xea\xbaA\xe1\x89\xd4}F\x1b\x7fT|%\x92,\xbd\xc1(`\x99\xf8\x93\xfcx#\x1a\xb3\x84\x02\xafJ\xfe\xf1`\xf5\x02PK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\w@\xfeļ\x00\x00\x00\x1c\x01\x00\x00\x0f\x00\x00\x00xl/workbook.xml\x8dOˎ\xc20\f\xbc\xf3\x15\x91\xefK\xda= T\xb5傐8/|@h\\\x1a\xd1ؕ\x9d\xe5\xf1\xf7\x84םӌ5\x9a\xf1L\xbd\xba\xc6ќQ405P\xce\v0H\x1d\xfb@\xc7\x06\xf6\xbb\xcd\xcf\x12\x8c&GލL\xd8\xc0\r\x15V\xed\xac\xbe\xb0\x9c\x0e\xcc'\x93\xfd\xa4\r\f)M\x95\xb5\xda\r\x18\x9d\xceyB\xcaJ\xcf\x12]ʧ\x1c\xadN\x82\xce뀘\xe2h\x7f\x8bba\xa3\v\x04\xaf\x84J\xbe\xc9\xe0\xbe\x0f\x1d\xae\xb9\xfb\x8fH\xe9\x15\"8\xba\x94\xdb\xeb\x10&\x85\xb6~~\xd07\x1ar1\xb7\xfe{\xf02/y\xe0\xd6\xe7\xa1`\xa4\n\x99\xc8֗`\xdb\xda~l\xf6\xb3\xac\xbd\x03PK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\x9ao<|\xb5\x00\x00\x00)\x01\x00\x00\x1a\x00\x00\x00xl/_rels/workbook.xml.rels\x8d\xcf\xcd\n\xc20\f\a\xf0\xbbOQrw\xd9<\x88Ⱥ]D\xd8U\xe6\x03\x94.\xfb`[[\x9a\xfa\xb1\xb7\xb7x\x10\a\x1e<\x85\xe4O~!y\xf9\x9c'q'σ5\x12\xb2$\x05AF\xdbf0\x9d\x84k}\xde\x1e@pP\xa6Q\x935$a!\x86\xb2\xd8\xe4\x17\x9aT\x88;\xdc\x0f\x8eED\fK\xe8CpGD\xd6=͊\x13\xeb\xc8Ĥ\xb5~V!\xb6\xbeC\xa7\xf4\xa8:\xc2]\x9a\xee\xd1\x7f\x1bP\xacLQ5\x12|\xd5d \xea\xc5\xd1?\xb6m\xdbA\xd3\xc9\xea\xdbL&\xfc8\x81\x0f\xebG\xee\x89BD\x95\xef(H\xf8\x8c\x18\xdf%K\xa2\nX\xe4\xb8\xfa\xb0x\x01PK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\\xf5\xfb\xcc!\xd2\x00\x00\x00\x1c\x01\x00\x00\x18\x00\x00\x00xl/worksheets/sheet1.xmlM\x8f\xc1N\xc30\f\x86\xef<E\x94\xfb\xe6nB\b\xa1$\xd3\x10\xe2\xc4m \xedj\xb5^\x1b\xadq*\xc7t\xe3\xedI\aB\\,\xfb\xb3\xfd\xff\xb6\xdb]\xd3hf\x92\x123{\xbbY7\xd6\x10\xb7\xb9\x8b\xdc{\xfb\xf1\xfe\xbaz\xb4\xa6(r\x87cf\xf2\xf6\x8b\x8a݅;w\xc9r.\x03\x91\x9a*\xc0\xc5\xdbAuz\x02(\xed@\t\xcb:OĵsʒPk)=\x94I\b\xbb\xdbR\x1aa\xdb4\x0f\x900\xb2\r\xee\xc6^P18\xc9\x17#\xf5\x90J\xdb%\xd9o\xacQo#\x8f\x91\xe9\xa0Ry,\xc1i8\xbe\x1d\x8e\xa6\xcf+\x8dg4\x91\x95zA\xadO\x98S\xbc꧐\x03\r\x0e\x96Yh\x7f\xb5\x9e\x17\xd59\xdco\x1d\xcc?\x18\xaa]\x8d\xff\xfc\xe1\xef\xb1\xf0\rPK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\xc5/\x1d}\x00\x01\x00\x00.\x02\x00\x00\x13\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\x00\x00\x00\x00[Content_Types].xmlPK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\x06Yǂ\xb1\x00\x00\x00(\x01\x00\x00\v\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x011\x01\x00\x00_rels/.relsPK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\w@\xfeļ\x00\x00\x00\x1c\x01\x00\x00\x0f\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\v\x02\x00\x00xl/workbook.xmlPK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\x9ao<|\xb5\x00\x00\x00)\x01\x00\x00\x1a\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\xf4\x02\x00\x00xl/_rels/workbook.xml.relsPK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\\xf5\xfb\xcc!\xd2\x00\x00\x00\x1c\x01\x00\x00\x18\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\xe1\x03\x00\x00xl/worksheets/sheet1.xmlPK\x05\x06\x00\x00\x00\x00\x05\x00\x05\x00E\x01\x00\x00\xe9\x04\x00\x00\x00\x00",
+	"sample.xml":  "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<document><title>XML</title><body>XML body go-tika integration fixture.</body></document>\n",
+	"sample.zip":  "PK\x03\x04\x14\x00\x00\x00\b\x00\x00\x00!\\KV\xe6'+\x00\x00\x00)\x00\x00\x00\t\x00\x00\x00inner.txt\x8b\xca,(HMQ(I\xad(QH\xcf\xd7-\xc9\xccNT\xc8\xcc+IM/J,\xc9\xcc\xcfSHˬ()-J\xd5\xe3\x02\x00PK\x01\x02\x14\x03\x14\x00\x00\x00\b\x00\x00\x00!\\KV\xe6'+\x00\x00\x00)\x00\x00\x00\t\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x01\x00\x00\x00\x00inner.txtPK\x05\x06\x00\x00\x00\x00\x01\x00\x01\x007\x00\x00\x00R\x00\x00\x00\x00\x00",
+}
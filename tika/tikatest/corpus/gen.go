@@ -0,0 +1,65 @@
+//go:build ignore
+// +build ignore
+
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gen writes data.go, holding the content of the files in the files
+// directory, so that the package builds without go:embed. The -o flag
+// writes another file instead, which TestGenerated compares with data.go.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+)
+
+func main() {
+	out := flag.String("o", "data.go", "output file")
+	flag.Parse()
+	names, err := filepath.Glob(filepath.Join("files", "*"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by gen.go; DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package corpus")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "var files = map[string]string{")
+	for _, name := range names {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(&buf, "%q: %q,\n", filepath.Base(name), b)
+	}
+	fmt.Fprintln(&buf, "}")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package corpus
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-tika/tika"
+)
+
+// UpdateEnv is the environment variable which, if set to 1, makes the
+// Assert functions write golden files instead of comparing with them.
+const UpdateEnv = "TIKATEST_UPDATE"
+
+// Update reports whether golden files are written instead of compared. It
+// defaults to whether UpdateEnv is set to 1, and can be set from a test flag.
+var Update = os.Getenv(UpdateEnv) == "1"
+
+// normalize is applied to extracted text before comparing it, so that
+// whitespace changes between Tika versions do not fail the comparison.
+var normalize = tika.TextOptions{StripControl: true, CollapseWhitespace: true}
+
+// AssertParseMatches parses d with c and reports an error through t if the
+// text, normalized by collapsing whitespace, differs from the content of the
+// golden file. If Update is true, the golden file is written instead.
+func AssertParseMatches(t testing.TB, c *tika.Client, d Document, golden string) {
+	t.Helper()
+	got, err := c.Parse(context.Background(), d.Reader())
+	if err != nil {
+		t.Errorf("Parse(%s) got error: %v", d.Name, err)
+		return
+	}
+	compare(t, "Parse("+d.Name+")", normalize.Apply(got)+"\n", golden)
+}
+
+// AssertMetadataMatches gets the metadata of d with c and reports an error
+// through t if the values of the given keys differ from those in the golden
+// file, a JSON object. Keys missing from the metadata are omitted. Only
+// stable keys should be compared: dates of parsing or versions of the
+// parsers change with every run or upgrade. If Update is true, the golden
+// file is written instead.
+func AssertMetadataMatches(t testing.TB, c *tika.Client, d Document, golden string, keys ...string) {
+	t.Helper()
+	m, err := c.Metadata(context.Background(), d.Reader())
+	if err != nil {
+		t.Errorf("Metadata(%s) got error: %v", d.Name, err)
+		return
+	}
+	sort.Strings(keys)
+	selected := make(tika.Metadata)
+	for _, k := range keys {
+		if vs, ok := m[k]; ok {
+			selected[k] = vs
+		}
+	}
+	b, err := json.MarshalIndent(selected, "", "  ")
+	if err != nil {
+		t.Errorf("Metadata(%s): %v", d.Name, err)
+		return
+	}
+	compare(t, "Metadata("+d.Name+")", string(b)+"\n", golden)
+}
+
+// compare reports an error through t if got differs from the content of the
+// golden file, or writes it to the file if Update is true.
+func compare(t testing.TB, what, got, golden string) {
+	t.Helper()
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Errorf("%s: %v", what, err)
+			return
+		}
+		if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Errorf("%s: %v", what, err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Errorf("%s: %v (run with %s=1 to create the golden file)", what, err, UpdateEnv)
+		return
+	}
+	if got != string(want) {
+		t.Errorf("%s got\n%s\nwant (%s)\n%s", what, got, golden, want)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A TimeoutTable maps MIME types to the maximum time to process documents
+// of that type, so that a slow format such as an image needing OCR is not
+// killed by a timeout suited to plain text, and a fast format does not hang
+// for the time allowed to OCR. Keys are either full MIME types, such as
+// "application/pdf", or a type with a wildcard subtype, such as "image/*".
+// Full MIME types take precedence, and the key "*/*", if any, applies to
+// all other types.
+type TimeoutTable map[string]time.Duration
+
+// DefaultTimeouts is a TimeoutTable suitable for a server running OCR on
+// images.
+var DefaultTimeouts = TimeoutTable{
+	"text/plain":      5 * time.Second,
+	"text/*":          15 * time.Second,
+	"application/pdf": 60 * time.Second,
+	"image/*":         120 * time.Second,
+	"*/*":             30 * time.Second,
+}
+
+// Timeout returns the timeout for mimeType, or 0 if there is none.
+// Parameters of mimeType, such as a charset, are ignored.
+func (t TimeoutTable) Timeout(mimeType string) time.Duration {
+	mimeType = baseMIMEType(mimeType)
+	if d, ok := t[mimeType]; ok {
+		return d
+	}
+	if i := strings.Index(mimeType, "/"); i >= 0 {
+		if d, ok := t[mimeType[:i]+"/*"]; ok {
+			return d
+		}
+	}
+	return t["*/*"]
+}
+
+// WithMIMETimeouts limits the time of requests declaring the MIME type of
+// their input, with a Content-Type header, to the timeout of that type in t,
+// such as DefaultTimeouts. Requests exceeding it fail with an error matching
+// context.DeadlineExceeded. A Pipeline of the Client with no Timeouts uses t
+// for the detected MIME type of every document.
+func WithMIMETimeouts(t TimeoutTable) Option {
+	return func(c *Client) {
+		c.timeouts = t
+	}
+}
+
+// mimeTimeout returns ctx limited to the timeout of the MIME type in
+// header, if any, and the function releasing its resources.
+func (c *Client) mimeTimeout(ctx context.Context, header http.Header) (context.Context, context.CancelFunc) {
+	if c.timeouts == nil {
+		return ctx, func() {}
+	}
+	ct := header.Get("Content-Type")
+	if ct == "" {
+		return ctx, func() {}
+	}
+	if d := c.timeouts.Timeout(ct); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
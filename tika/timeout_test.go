@@ -0,0 +1,128 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutTable(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     time.Duration
+	}{
+		{"text/plain", 5 * time.Second},
+		{"text/plain; charset=UTF-8", 5 * time.Second},
+		{"text/html", 15 * time.Second},
+		{"image/png", 120 * time.Second},
+		{"application/pdf", 60 * time.Second},
+		{"application/zip", 30 * time.Second},
+		{"", 30 * time.Second},
+	}
+	for _, test := range tests {
+		if got := DefaultTimeouts.Timeout(test.mimeType); got != test.want {
+			t.Errorf("DefaultTimeouts.Timeout(%q) = %v, want %v", test.mimeType, got, test.want)
+		}
+	}
+	if got := (TimeoutTable{"image/*": time.Second}).Timeout("text/plain"); got != 0 {
+		t.Errorf("Timeout of a type missing from the table = %v, want 0", got)
+	}
+	if got := TimeoutTable(nil).Timeout("text/plain"); got != 0 {
+		t.Errorf("Timeout of a nil table = %v, want 0", got)
+	}
+}
+
+// slowServer answers after the delay in the X-Delay header, detecting the
+// MIME type of a request as its body.
+func slowServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if r.URL.Path == "/detect/stream" {
+			w.Write(b)
+			return
+		}
+		d, _ := time.ParseDuration(r.Header.Get("X-Delay"))
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+		}
+		w.Write(b)
+	}))
+}
+
+func TestWithMIMETimeouts(t *testing.T) {
+	ts := slowServer()
+	defer ts.Close()
+	c := NewClient(nil, ts.URL, WithMIMETimeouts(TimeoutTable{"image/*": time.Second, "text/*": 10 * time.Millisecond}))
+	tests := []struct {
+		contentType string
+		wantErr     bool
+	}{
+		{"text/plain", true},
+		{"image/png", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		h := http.Header{"X-Delay": {"100ms"}}
+		if test.contentType != "" {
+			h.Set("Content-Type", test.contentType)
+		}
+		_, err := c.ParseWithHeader(context.Background(), strings.NewReader("body"), h)
+		if test.wantErr != errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("ParseWithHeader of %q taking 100ms got error %v, want timeout %v", test.contentType, err, test.wantErr)
+		}
+	}
+}
+
+func TestPipelineTimeouts(t *testing.T) {
+	ts := slowServer()
+	defer ts.Close()
+	delay := http.Header{"X-Delay": {"100ms"}}
+	p := &Pipeline{
+		Client: NewClient(nil, ts.URL, WithMIMETimeouts(TimeoutTable{"*/*": 10 * time.Millisecond})),
+		Rules: map[string]Rule{
+			"text/html": {Header: delay, Timeout: time.Second},
+		},
+		Default: Rule{Header: delay},
+	}
+	tests := []struct {
+		mimeType string
+		timeouts TimeoutTable
+		wantErr  bool
+	}{
+		{mimeType: "image/png", timeouts: TimeoutTable{"image/*": time.Second}},
+		{mimeType: "text/plain", timeouts: TimeoutTable{"text/*": 10 * time.Millisecond}, wantErr: true},
+		// Rule.Timeout takes precedence.
+		{mimeType: "text/html", timeouts: TimeoutTable{"text/*": 10 * time.Millisecond}},
+		// Without Timeouts, those of the Client apply.
+		{mimeType: "application/pdf", wantErr: true},
+	}
+	for _, test := range tests {
+		p.Timeouts = test.timeouts
+		_, err := p.Process(context.Background(), strings.NewReader(test.mimeType))
+		if test.wantErr != errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Process of %s taking 100ms with timeouts %v got error %v, want timeout %v", test.mimeType, test.timeouts, err, test.wantErr)
+		}
+	}
+}
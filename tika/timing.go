@@ -0,0 +1,102 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+)
+
+// XTIKAParseTimeMillis is the metadata key of the time the server spent
+// parsing a document, in milliseconds.
+const XTIKAParseTimeMillis = "X-TIKA:parse_time_millis"
+
+// A ParseResult is a parsed document with timing information, to find slow
+// documents and tune timeouts.
+type ParseResult struct {
+	// Content is the extracted text of the document.
+	Content string
+	// Metadata is the metadata of the document.
+	Metadata Metadata
+	// Elapsed is the time from sending the request to reading the whole
+	// response.
+	Elapsed time.Duration
+	// BytesSent is the size of the uploaded document.
+	BytesSent int64
+	// ParseTime is the time the server reports spending on parsing, from
+	// XTIKAParseTimeMillis, or 0 if it is not reported.
+	ParseTime time.Duration
+}
+
+// ParseTimed parses input like Parse, and reports how long it took. It uses
+// the /rmeta/text endpoint, which reports the server-side parse time. Embedded
+// documents are not included in the result.
+func (c *Client) ParseTimed(ctx context.Context, input io.Reader) (*ParseResult, error) {
+	r := &ParseResult{}
+	var counter *countingReader
+	switch in := input.(type) {
+	case nil:
+	case interface{ Len() int }:
+		// Keep readers whose size net/http knows, such as a
+		// *bytes.Reader, so the request has a Content-Length.
+		r.BytesSent = int64(in.Len())
+	default:
+		counter = &countingReader{r: input}
+		input = counter
+	}
+	start := time.Now()
+	err := c.MetaRecursiveStream(ctx, input, "text", func(m Metadata) error {
+		if r.Metadata == nil {
+			r.Metadata = m
+		}
+		return nil
+	})
+	r.Elapsed = time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	if counter != nil {
+		r.BytesSent = counter.n
+	}
+	r.Content = r.Metadata.Get(XTIKAContent)
+	r.ParseTime = r.Metadata.ParseTime()
+	return r, nil
+}
+
+// ParseTime returns the time the server reports spending on parsing the
+// document, from XTIKAParseTimeMillis, or 0 if it is not reported.
+func (m Metadata) ParseTime() time.Duration {
+	ms, err := strconv.ParseInt(m.Get(XTIKAParseTimeMillis), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
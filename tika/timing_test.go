@@ -0,0 +1,80 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, `[{"X-TIKA:content":%q,"X-TIKA:parse_time_millis":["42"]},{"X-TIKA:content":"embedded"}]`, b)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	inputs := []struct {
+		name  string
+		input io.Reader
+	}{
+		{"strings.Reader", strings.NewReader("hello")},
+		{"plain reader", io.MultiReader(strings.NewReader("hel"), strings.NewReader("lo"))},
+	}
+	for _, in := range inputs {
+		got, err := c.ParseTimed(context.Background(), in.input)
+		if err != nil {
+			t.Errorf("ParseTimed(%s) got error: %v", in.name, err)
+			continue
+		}
+		if got.Content != "hello" {
+			t.Errorf("ParseTimed(%s) Content = %q, want %q", in.name, got.Content, "hello")
+		}
+		if got.BytesSent != 5 {
+			t.Errorf("ParseTimed(%s) BytesSent = %d, want 5", in.name, got.BytesSent)
+		}
+		if got.ParseTime != 42*time.Millisecond {
+			t.Errorf("ParseTimed(%s) ParseTime = %v, want 42ms", in.name, got.ParseTime)
+		}
+		if got.Elapsed <= 0 {
+			t.Errorf("ParseTimed(%s) Elapsed = %v, want > 0", in.name, got.Elapsed)
+		}
+	}
+}
+
+func TestMetadataParseTime(t *testing.T) {
+	tests := []struct {
+		m    Metadata
+		want time.Duration
+	}{
+		{Metadata{XTIKAParseTimeMillis: {"1500"}}, 1500 * time.Millisecond},
+		{Metadata{XTIKAParseTimeMillis: {"bad"}}, 0},
+		{Metadata{}, 0},
+	}
+	for _, test := range tests {
+		if got := test.m.ParseTime(); got != test.want {
+			t.Errorf("%v.ParseTime() = %v, want %v", test.m, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+)
+
+// unixSocketURL is the URL used by clients connecting over a Unix domain
+// socket. The host is ignored by the dialer.
+const unixSocketURL = "http://localhost"
+
+// UnixSocketHTTPClient returns an *http.Client that sends every request over
+// the Unix domain socket at path, regardless of the host in the request URL.
+// Any other custom dialer can be used the same way by setting DialContext on
+// the Transport of the *http.Client passed to NewClient.
+func UnixSocketHTTPClient(path string) *http.Client {
+	var d net.Dialer
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// NewUnixSocketClient creates a new Client that connects to a Tika Server, or
+// a proxy in front of one, listening on the Unix domain socket at path. See
+// Server.UnixSocket.
+func NewUnixSocketClient(path string) *Client {
+	return NewClient(UnixSocketHTTPClient(path), unixSocketURL)
+}
+
+// UnixSocket makes Start serve a reverse proxy to the server on the Unix
+// domain socket at path, so the server can be reached through file system
+// permissions rather than only the network. Connect to it with
+// NewUnixSocketClient. The socket is removed by Stop or Shutdown. A stale
+// socket left at path by a process which did not remove it is replaced,
+// but Start fails if another process is listening on it or if path is not a
+// socket. If used, UnixSocket must be called before starting the server.
+func (s *Server) UnixSocket(path string) error {
+	if s.cmd != nil {
+		return fmt.Errorf("server process already started, cannot add a Unix socket")
+	}
+	s.socket = path
+	return nil
+}
+
+// startProxy starts the reverse proxy configured by UnixSocket, if any.
+func (s *Server) startProxy() error {
+	if s.socket == "" {
+		return nil
+	}
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return err
+	}
+	removeStaleSocket(s.socket)
+	l, err := net.Listen("unix", s.socket)
+	if err != nil {
+		return fmt.Errorf("could not listen on %q: %v", s.socket, err)
+	}
+	s.proxy = &http.Server{Handler: httputil.NewSingleHostReverseProxy(u)}
+	go s.proxy.Serve(l)
+	return nil
+}
+
+// removeStaleSocket removes the socket at path if no process listens on it.
+func removeStaleSocket(path string) {
+	fi, err := os.Lstat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return
+	}
+	os.Remove(path)
+}
+
+// stopProxy stops the reverse proxy started by startProxy, if any.
+func (s *Server) stopProxy() {
+	if s.proxy == nil {
+		return
+	}
+	s.proxy.Close()
+	s.proxy = nil
+	os.Remove(s.socket)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestUnixSocketProxy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix domain sockets are not supported")
+	}
+	want := "1.14"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, want)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "socket")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tika.sock")
+
+	s := &Server{url: ts.URL}
+	if err := s.UnixSocket(path); err != nil {
+		t.Fatalf("UnixSocket got error: %v", err)
+	}
+	if err := s.startProxy(); err != nil {
+		t.Fatalf("startProxy got error: %v", err)
+	}
+	got, err := NewUnixSocketClient(path).Version(context.Background())
+	if err != nil {
+		t.Fatalf("Version over the Unix socket got error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Version over the Unix socket = %q, want %q", got, want)
+	}
+
+	s.cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cleanup did not remove the socket: %v", err)
+	}
+}
+
+func TestUnixSocketStale(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix domain sockets are not supported")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "1.14")
+	}))
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "tika.sock")
+
+	// Leave a socket nobody listens on, like a crashed process.
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen got error: %v", err)
+	}
+	if ul, ok := l.(interface{ SetUnlinkOnClose(bool) }); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+	l.Close()
+
+	s := &Server{url: ts.URL, socket: path}
+	if err := s.startProxy(); err != nil {
+		t.Fatalf("startProxy with a stale socket got error: %v", err)
+	}
+	defer s.cleanup()
+	if _, err := NewUnixSocketClient(path).Version(context.Background()); err != nil {
+		t.Errorf("Version over the replaced socket got error: %v", err)
+	}
+
+	// A live socket is not replaced.
+	other := &Server{url: ts.URL, socket: path}
+	if err := other.startProxy(); err == nil {
+		other.stopProxy()
+		t.Errorf("startProxy on a socket in use got no error")
+	}
+}
+
+func TestStartUnixSocketError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix domain sockets are not supported")
+	}
+	path, err := os.Executable()
+	if err != nil {
+		t.Skip("cannot find current test executable")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "1.14")
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("error creating test server: %v", err)
+	}
+	s, err := NewServer(path, tsURL.Port())
+	if err != nil {
+		t.Fatalf("NewServer got error: %v", err)
+	}
+	if err := s.UnixSocket(filepath.Join(t.TempDir(), "missing", "tika.sock")); err != nil {
+		t.Fatalf("UnixSocket got error: %v", err)
+	}
+	if err := s.Start(context.Background()); err == nil {
+		s.Stop()
+		t.Fatal("Start with an invalid socket path got no error")
+	}
+	if s.cmd != nil {
+		t.Errorf("Start with an invalid socket path left the process running")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// An EmbeddedFile is a file embedded in a document, as returned by Unpack.
+type EmbeddedFile struct {
+	// Name is the name of the file within the document.
+	Name string
+	// Size is the size of the file in bytes.
+	Size int64
+	// MIMEType is the type of the file, guessed from the extension of Name,
+	// or application/octet-stream if it is unknown.
+	MIMEType string
+	// Content is the content of the file. It is only valid during the call
+	// to the function passed to Unpack.
+	Content io.Reader
+}
+
+// Unpack extracts the files embedded in input, such as the attachments of an
+// email or the images in a document, and calls fn with each in turn. The
+// files are streamed and never held in memory all at once. If fn returns an
+// error, Unpack stops and returns it.
+//
+// If the Client was created with WithEmbeddedTypes, only files of those types
+// are passed to fn. If it was created with WithRedactor, Unpack returns
+// ErrNotRedactable.
+func (c *Client) Unpack(ctx context.Context, input io.Reader, fn func(EmbeddedFile) error) error {
+	if err := c.redactable(); err != nil {
+		return err
+	}
+	return c.unpack(ctx, input, nil, fn)
+}
+
+// unpack is like Unpack, sending header with the request.
+func (c *Client) unpack(ctx context.Context, input io.Reader, header http.Header, fn func(EmbeddedFile) error) error {
+	header = header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Accept", "application/x-tar")
+	body, err := c.call(ctx, input, "PUT", "/unpack", header)
+	if err != nil {
+		var ce ClientError
+		if errors.As(err, &ce) && ce.StatusCode == http.StatusNoContent {
+			// There are no embedded files.
+			return nil
+		}
+		return err
+	}
+	defer body.Close()
+	tr := tar.NewReader(body)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		f := EmbeddedFile{Name: h.Name, Size: h.Size, MIMEType: typeByName(h.Name), Content: tr}
+		if !c.wantEmbedded(f.MIMEType) {
+			continue
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+}
+
+// typeByName guesses the MIME type of a file from the extension of name.
+func typeByName(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return baseMIMEType(t)
+	}
+	return "application/octet-stream"
+}
+
+// WithEmbeddedTypes limits the embedded documents returned by Unpack,
+// MetaRecursive, and the other recursive methods to those whose MIME type
+// matches one of patterns. A pattern is a MIME type, such as
+// "application/pdf", or a type with a wildcard subtype, such as "image/*".
+// The container document is always returned by the recursive methods.
+//
+// The server still processes every embedded document; filtering saves memory
+// and work in the caller.
+func WithEmbeddedTypes(patterns ...string) Option {
+	return func(c *Client) {
+		c.embeddedTypes = append([]string(nil), patterns...)
+	}
+}
+
+// wantEmbedded reports whether an embedded document of type mimeType passes
+// the WithEmbeddedTypes filter.
+func (c *Client) wantEmbedded(mimeType string) bool {
+	if len(c.embeddedTypes) == 0 {
+		return true
+	}
+	return matchMIMEType(c.embeddedTypes, mimeType) != ""
+}
+
+// matchMIMEType returns the first of patterns matching mimeType, or "" if
+// none does. A pattern is a MIME type, a type with a wildcard subtype, such
+// as "image/*", or "*/*". Parameters of mimeType are ignored.
+func matchMIMEType(patterns []string, mimeType string) string {
+	mimeType = baseMIMEType(mimeType)
+	for _, p := range patterns {
+		if p == mimeType || p == "*/*" {
+			return p
+		}
+		if strings.HasSuffix(p, "/*") && strings.HasPrefix(mimeType, p[:len(p)-1]) {
+			return p
+		}
+	}
+	return ""
+}
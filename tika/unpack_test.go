@@ -0,0 +1,115 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func tarServer(files map[string]string, order []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/unpack" || r.Header.Get("Accept") != "application/x-tar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if len(files) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		tw := tar.NewWriter(w)
+		for _, name := range order {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg})
+			tw.Write([]byte(files[name]))
+		}
+		tw.Close()
+	}))
+}
+
+func TestUnpack(t *testing.T) {
+	files := map[string]string{"image1.png": "png", "doc.pdf": "pdf", "notes": "?"}
+	order := []string{"image1.png", "doc.pdf", "notes"}
+	ts := tarServer(files, order)
+	defer ts.Close()
+
+	tests := []struct {
+		opts []Option
+		want []string
+	}{
+		{nil, []string{"image1.png:image/png:png", "doc.pdf:application/pdf:pdf", "notes:application/octet-stream:?"}},
+		{[]Option{WithEmbeddedTypes("image/*")}, []string{"image1.png:image/png:png"}},
+		{[]Option{WithEmbeddedTypes("application/pdf", "text/plain")}, []string{"doc.pdf:application/pdf:pdf"}},
+	}
+	for _, test := range tests {
+		c := NewClient(nil, ts.URL, test.opts...)
+		var got []string
+		err := c.Unpack(context.Background(), nil, func(f EmbeddedFile) error {
+			b, err := ioutil.ReadAll(f.Content)
+			got = append(got, fmt.Sprintf("%s:%s:%s", f.Name, f.MIMEType, b))
+			return err
+		})
+		if err != nil {
+			t.Errorf("Unpack returned an error: %v", err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Unpack got %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestUnpackNoContent(t *testing.T) {
+	ts := tarServer(nil, nil)
+	defer ts.Close()
+	err := NewClient(nil, ts.URL).Unpack(context.Background(), nil, func(EmbeddedFile) error {
+		t.Error("Unpack called fn with no embedded files")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Unpack returned an error: %v", err)
+	}
+}
+
+func TestMetaRecursiveEmbeddedTypes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"Content-Type":"message/rfc822","X-TIKA:content":"mail"},
+			{"Content-Type":"image/jpeg","X-TIKA:embedded_resource_path":"/a.jpg","X-TIKA:content":""},
+			{"Content-Type":"application/pdf","X-TIKA:embedded_resource_path":"/b.pdf","X-TIKA:content":"pdf"},
+			{"Content-Type":"text/plain; charset=UTF-8","X-TIKA:embedded_resource_path":"/c.txt","X-TIKA:content":"txt"}
+		]`)
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil, ts.URL, WithEmbeddedTypes("image/*", "application/pdf"))
+	docs, err := c.MetaRecursive(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("MetaRecursive returned an error: %v", err)
+	}
+	var got []string
+	for _, d := range docs {
+		got = append(got, Metadata(d).Get(XTIKAEmbeddedResourcePath))
+	}
+	if want := []string{"", "/a.jpg", "/b.pdf"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MetaRecursive got paths %q, want %q", got, want)
+	}
+}
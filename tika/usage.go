@@ -0,0 +1,117 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrUsageUnsupported is returned by MonitorUsage on platforms where the
+// resource usage of a process cannot be read. Only Linux is supported.
+var ErrUsageUnsupported = errors.New("resource usage not supported on this platform")
+
+// ResourceUsage is a sample of the resources used by the Java process.
+type ResourceUsage struct {
+	// Time is when the sample was taken.
+	Time time.Time
+	// PID is the process ID of the Java process.
+	PID int
+	// RSS is the resident set size, in bytes.
+	RSS int64
+	// CPUTime is the user and system CPU time used since the process started.
+	CPUTime time.Duration
+	// CPUPercent is the CPU used since the previous sample of the same
+	// process, where 100 is one core. It is 0 for the first sample.
+	CPUPercent float64
+}
+
+// PID returns the process ID of the Java process of s, or 0 if it is not
+// running or, for an adopted server, unknown. PID may be called while another
+// goroutine starts or stops s, for example in RunService.
+func (s *Server) PID() int {
+	if s.adopted {
+		if s.proc == nil {
+			return 0
+		}
+		return s.proc.Pid
+	}
+	s.procMu.Lock()
+	defer s.procMu.Unlock()
+	return s.pid
+}
+
+// ProcessState returns the state of the last Java process of s that exited,
+// including its exit code and CPU times, or nil if none has exited yet or it
+// was killed without being waited for. Like PID, it may be called
+// concurrently with starting and stopping s.
+func (s *Server) ProcessState() *os.ProcessState {
+	s.procMu.Lock()
+	defer s.procMu.Unlock()
+	return s.state
+}
+
+// started records the process ID of a new Java process.
+func (s *Server) started(pid int) {
+	s.procMu.Lock()
+	s.pid = pid
+	s.procMu.Unlock()
+}
+
+// exited records that the Java process exited with state.
+func (s *Server) exited(state *os.ProcessState) {
+	s.procMu.Lock()
+	s.pid = 0
+	s.state = state
+	s.procMu.Unlock()
+}
+
+// MonitorUsage samples the resource usage of the Java process every interval
+// until ctx is done, calling fn with every sample, for example to alert on
+// the memory leaks of a long-running server. Samples are skipped while the
+// process is not running. An error reading a sample is passed to fn, except
+// for ErrUsageUnsupported, which is returned right away. Otherwise
+// MonitorUsage returns ctx.Err().
+func (s *Server) MonitorUsage(ctx context.Context, interval time.Duration, fn func(ResourceUsage, error)) error {
+	var prev ResourceUsage
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if pid := s.PID(); pid > 0 {
+			u, err := processUsage(pid)
+			if err == ErrUsageUnsupported {
+				return err
+			}
+			if err == nil && prev.PID == pid {
+				if d := u.Time.Sub(prev.Time); d > 0 {
+					u.CPUPercent = 100 * float64(u.CPUTime-prev.CPUTime) / float64(d)
+				}
+			}
+			if err == nil {
+				prev = u
+			}
+			fn(u, err)
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTick is the unit of the CPU times in /proc/<pid>/stat. USER_HZ is 100
+// on all supported Linux architectures.
+const clockTick = time.Second / 100
+
+// processUsage reads the resource usage of the process with the given ID
+// from /proc/<pid>/stat.
+func processUsage(pid int) (ResourceUsage, error) {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+	u := ResourceUsage{Time: time.Now(), PID: pid}
+	// The fields follow the command name, which is in parentheses and may
+	// contain spaces. fields[0] is the state, field 3 of proc(5).
+	stat := string(b)
+	fields := strings.Fields(stat[strings.LastIndexByte(stat, ')')+1:])
+	if len(fields) < 22 {
+		return ResourceUsage{}, fmt.Errorf("invalid /proc/%d/stat: %q", pid, stat)
+	}
+	utime, err1 := strconv.ParseInt(fields[11], 10, 64)
+	stime, err2 := strconv.ParseInt(fields[12], 10, 64)
+	rss, err3 := strconv.ParseInt(fields[21], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return ResourceUsage{}, fmt.Errorf("invalid /proc/%d/stat: %q", pid, stat)
+	}
+	u.CPUTime = time.Duration(utime+stime) * clockTick
+	u.RSS = rss * int64(os.Getpagesize())
+	return u, nil
+}
+
+// processZombie reports whether the process with the given ID exited but was
+// not waited for yet.
+func processZombie(pid int) bool {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	stat := string(b)
+	fields := strings.Fields(stat[strings.LastIndexByte(stat, ')')+1:])
+	return len(fields) > 0 && fields[0] == "Z"
+}
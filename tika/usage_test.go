@@ -0,0 +1,80 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPIDAndProcessState(t *testing.T) {
+	s := serviceServer(t, "10")
+	if got := s.PID(); got != 0 {
+		t.Errorf("PID() before Start = %d, want 0", got)
+	}
+	if err := s.StartAndWaitReady(context.Background(), ReadinessOptions{PollInterval: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	if got, want := s.PID(), s.cmd.Process.Pid; got != want {
+		t.Errorf("PID() = %d, want %d", got, want)
+	}
+	if st := s.ProcessState(); st != nil {
+		t.Errorf("ProcessState() while running = %v, want nil", st)
+	}
+	s.Stop()
+	if got := s.PID(); got != 0 {
+		t.Errorf("PID() after Stop = %d, want 0", got)
+	}
+	if st := s.ProcessState(); st == nil || st.Success() {
+		t.Errorf("ProcessState() after Stop = %v, want a killed process", st)
+	}
+}
+
+func TestMonitorUsage(t *testing.T) {
+	s := serviceServer(t, "10")
+	if err := s.StartAndWaitReady(context.Background(), ReadinessOptions{PollInterval: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("Start got error: %v", err)
+	}
+	defer s.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var samples []ResourceUsage
+	err := s.MonitorUsage(ctx, 10*time.Millisecond, func(u ResourceUsage, err error) {
+		if err != nil {
+			t.Errorf("MonitorUsage got sample error: %v", err)
+		}
+		samples = append(samples, u)
+		if len(samples) == 2 {
+			cancel()
+		}
+	})
+	if err == ErrUsageUnsupported {
+		t.Skip(err)
+	}
+	if err != context.Canceled {
+		t.Errorf("MonitorUsage got error %v, want %v", err, context.Canceled)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("MonitorUsage got %d samples, want 2", len(samples))
+	}
+	for _, u := range samples {
+		if u.PID != s.PID() || u.RSS <= 0 || u.CPUPercent < 0 {
+			t.Errorf("MonitorUsage got sample %+v", u)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A VersionMismatchError is returned by Start when the server reports a
+// version other than the one set with ExpectVersion, or older than the one
+// set with MinimumVersion. This usually means the wrong JAR was started, for
+// example when several JARs live in the same directory.
+type VersionMismatchError struct {
+	// Reported is the version reported by the server's /version endpoint,
+	// such as "Apache Tika 1.21".
+	Reported string
+	// Expected is the version set with ExpectVersion, if any.
+	Expected Version
+	// Minimum is the version set with MinimumVersion, if any.
+	Minimum Version
+}
+
+func (e *VersionMismatchError) Error() string {
+	if e.Expected != "" {
+		return fmt.Sprintf("server reported version %q, want %s", e.Reported, e.Expected)
+	}
+	return fmt.Sprintf("server reported version %q, want %s or later", e.Reported, e.Minimum)
+}
+
+// ExpectVersion makes Start check that the server reports version v once it
+// is ready. If it does not, Start stops the server and returns a
+// *VersionMismatchError.
+func (s *Server) ExpectVersion(v Version) {
+	s.expectVersion = v
+}
+
+// MinimumVersion makes Start check that the server reports version v or
+// later once it is ready, for example to refuse versions with known
+// vulnerabilities. If it does not, Start stops the server and returns a
+// *VersionMismatchError.
+func (s *Server) MinimumVersion(v Version) {
+	s.minVersion = v
+}
+
+// checkVersion checks the version reported by the server against the
+// versions set with ExpectVersion and MinimumVersion.
+func (s *Server) checkVersion(ctx context.Context) error {
+	if s.expectVersion == "" && s.minVersion == "" {
+		return nil
+	}
+	reported, err := NewClient(nil, s.url).Version(ctx)
+	if err != nil {
+		return fmt.Errorf("error checking server version: %v", err)
+	}
+	reported = strings.TrimSpace(reported)
+	v := versionRE.FindString(reported)
+	if s.expectVersion != "" && v != string(s.expectVersion) {
+		return &VersionMismatchError{Reported: reported, Expected: s.expectVersion}
+	}
+	if s.minVersion != "" && (v == "" || compareVersions(v, string(s.minVersion)) < 0) {
+		return &VersionMismatchError{Reported: reported, Minimum: s.minVersion}
+	}
+	return nil
+}
+
+// versionRE matches the version number in the response of /version, such as
+// "1.21" in "Apache Tika 1.21".
+var versionRE = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// compareVersions compares the dotted version numbers a and b, returning -1,
+// 0, or 1. Missing components are 0, so "2.0" equals "2.0.0". Anything after
+// the numbers, such as "-BETA", is ignored.
+func compareVersions(a, b string) int {
+	as, bs := versionParts(a), versionParts(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	var parts []int
+	for _, p := range strings.Split(versionRE.FindString(v), ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// ErrUnsupportedVersion is returned, possibly wrapped, by ParseVersion and
+// LatestVersionOf when no supported version matches.
+var ErrUnsupportedVersion = errors.New("unsupported Tika Server version")
+
+// SupportedVersions returns the versions of Tika Server that can be
+// downloaded and verified, oldest first. The result is a copy, so callers
+// may modify it.
+func SupportedVersions() []Version {
+	vs := append([]Version(nil), Versions...)
+	sort.Slice(vs, func(i, j int) bool {
+		return compareVersions(string(vs[i]), string(vs[j])) < 0
+	})
+	return vs
+}
+
+// LatestVersion returns the newest supported version of Tika Server.
+func LatestVersion() Version {
+	vs := SupportedVersions()
+	return vs[len(vs)-1]
+}
+
+// LatestVersionOf returns the newest supported version of Tika Server whose
+// major version number is major, such as 1.21 for 1. It returns an error
+// wrapping ErrUnsupportedVersion if there is none.
+func LatestVersionOf(major int) (Version, error) {
+	var latest Version
+	for _, v := range SupportedVersions() {
+		if p := versionParts(string(v)); len(p) > 0 && p[0] == major {
+			latest = v
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("%d.x: %w", major, ErrUnsupportedVersion)
+	}
+	return latest, nil
+}
+
+// Latest1x returns the newest supported 1.x version of Tika Server.
+func Latest1x() Version {
+	v, err := LatestVersionOf(1)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// ParseVersion resolves s to a supported version of Tika Server. It accepts
+// exact versions such as "1.21" or "v1.21", major versions such as "1" or
+// "1.x", which resolve to the newest supported version in that line, and
+// "latest", which resolves to LatestVersion. It returns an error wrapping
+// ErrUnsupportedVersion if no supported version matches.
+func ParseVersion(s string) (Version, error) {
+	t := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "v")
+	if t == "latest" {
+		return LatestVersion(), nil
+	}
+	if m := strings.TrimSuffix(t, ".x"); !strings.Contains(m, ".") {
+		if major, err := strconv.Atoi(m); err == nil {
+			return LatestVersionOf(major)
+		}
+	}
+	if versionRE.FindString(t) == t {
+		for _, v := range SupportedVersions() {
+			if compareVersions(t, string(v)) == 0 {
+				return v, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%q: %w", s, ErrUnsupportedVersion)
+}
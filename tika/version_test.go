@@ -0,0 +1,150 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.21", "1.21", 0},
+		{"1.9", "1.21", -1},
+		{"2.0", "1.28.5", 1},
+		{"2.0", "2.0.0", 0},
+		{"Apache Tika 1.14", "1.14", 0},
+		{"3.0.0-BETA", "3.0.0", 0},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestStartVersion(t *testing.T) {
+	path, err := os.Executable()
+	if err != nil {
+		t.Skip("cannot find current test executable")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "Apache Tika 1.20")
+	}))
+	defer ts.Close()
+	tsURL, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("error creating test server: %v", err)
+	}
+	tests := []struct {
+		name     string
+		expect   Version
+		minimum  Version
+		mismatch bool
+	}{
+		{name: "no check"},
+		{name: "expected", expect: Version120},
+		{name: "unexpected", expect: Version121, mismatch: true},
+		{name: "above minimum", minimum: Version119},
+		{name: "below minimum", minimum: Version121, mismatch: true},
+	}
+	for _, test := range tests {
+		s, err := NewServer(path, tsURL.Port())
+		if err != nil {
+			t.Fatalf("NewServer got error: %v", err)
+		}
+		if test.expect != "" {
+			s.ExpectVersion(test.expect)
+		}
+		if test.minimum != "" {
+			s.MinimumVersion(test.minimum)
+		}
+		err = s.Start(context.Background())
+		var verr *VersionMismatchError
+		if got := errors.As(err, &verr); got != test.mismatch {
+			t.Errorf("Start(%s) got error %v, want mismatch %v", test.name, err, test.mismatch)
+		}
+		if err != nil {
+			if s.cmd != nil {
+				t.Errorf("Start(%s) did not stop the process after failing", test.name)
+			}
+			continue
+		}
+		s.Stop()
+	}
+}
+
+func TestSupportedVersions(t *testing.T) {
+	vs := SupportedVersions()
+	if want := []Version{Version119, Version120, Version121}; !reflect.DeepEqual(vs, want) {
+		t.Errorf("SupportedVersions() = %v, want %v", vs, want)
+	}
+	vs[0] = "0.1"
+	if Versions[0] != Version119 {
+		t.Errorf("modifying the result of SupportedVersions changed Versions to %v", Versions)
+	}
+	if got := LatestVersion(); got != Version121 {
+		t.Errorf("LatestVersion() = %v, want %v", got, Version121)
+	}
+	if got := Latest1x(); got != Version121 {
+		t.Errorf("Latest1x() = %v, want %v", got, Version121)
+	}
+	if got, err := LatestVersionOf(2); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("LatestVersionOf(2) = %v, %v, want ErrUnsupportedVersion", got, err)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{in: "1.20", want: Version120},
+		{in: " v1.19 ", want: Version119},
+		{in: "1.21.0", want: Version121},
+		{in: "1", want: Version121},
+		{in: "1.x", want: Version121},
+		{in: "latest", want: Version121},
+		{in: "1.18", wantErr: true},
+		{in: "2.x", wantErr: true},
+		{in: "1.21-BETA", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := ParseVersion(test.in)
+		if test.wantErr {
+			if !errors.Is(err, ErrUnsupportedVersion) {
+				t.Errorf("ParseVersion(%q) = %v, %v, want ErrUnsupportedVersion", test.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseVersion(%q) = %v, %v, want %v", test.in, got, err, test.want)
+		}
+	}
+}
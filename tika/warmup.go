@@ -0,0 +1,70 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Warmup establishes up to n connections to the server ahead of time by
+// sending n concurrent /version requests, so that the first documents of a
+// burst do not pay for connection setup. It returns the first error
+// encountered, if any.
+//
+// Connections are kept by the http.Client's Transport once Warmup returns, so
+// its MaxIdleConnsPerHost (2 for http.DefaultTransport) should be at least n.
+func (c *Client) Warmup(ctx context.Context, n int) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Version(ctx); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// KeepAlive calls Warmup(ctx, n) every interval until ctx is done, keeping n
+// idle connections alive through NAT and proxy idle timeouts. Errors are
+// ignored; a connection that was dropped is replaced by the next ping.
+// KeepAlive blocks, so it is usually run in its own goroutine.
+func (c *Client) KeepAlive(ctx context.Context, n int, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			c.Warmup(ctx, n)
+		}
+	}
+}
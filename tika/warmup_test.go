@@ -0,0 +1,97 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmup(t *testing.T) {
+	const n = 4
+	var (
+		conns, requests int64
+		pending         sync.WaitGroup
+	)
+	pending.Add(n)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hold the first n requests until all of them have arrived, so each
+		// needs its own connection.
+		if atomic.AddInt64(&requests, 1) <= n {
+			pending.Done()
+			pending.Wait()
+		}
+		w.Write([]byte("Apache Tika 1.14"))
+	}))
+	ts.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt64(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	hc := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: n}}
+	c := NewClient(hc, ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Warmup(ctx, n); err != nil {
+		t.Fatalf("Warmup got error: %v", err)
+	}
+	if got := atomic.LoadInt64(&conns); got != n {
+		t.Errorf("Warmup opened %d connections, want %d", got, n)
+	}
+	// The warm connections are reused.
+	if err := c.Warmup(ctx, n); err != nil {
+		t.Fatalf("second Warmup got error: %v", err)
+	}
+	if got := atomic.LoadInt64(&conns); got != n {
+		t.Errorf("second Warmup left %d connections, want %d", got, n)
+	}
+}
+
+func TestWarmupError(t *testing.T) {
+	if err := errorClient.Warmup(context.Background(), 2); err == nil {
+		t.Error("Warmup got no error, want an error")
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	var pings int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&pings, 1)
+	}))
+	defer ts.Close()
+	c := NewClient(nil, ts.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.KeepAlive(ctx, 1, time.Millisecond)
+		close(done)
+	}()
+	for atomic.LoadInt64(&pings) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
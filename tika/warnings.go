@@ -0,0 +1,94 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// XTIKAExceptionPrefix is the prefix of the metadata keys Tika uses to record
+// exceptions that did not stop the parse, such as
+// X-TIKA:EXCEPTION:embedded_exception.
+const XTIKAExceptionPrefix = "X-TIKA:EXCEPTION:"
+
+// A ParseWarning is an exception Tika recorded while parsing a document,
+// which means the extraction of that document may be incomplete.
+type ParseWarning struct {
+	// Path is the embedded resource path of the document the exception was
+	// recorded on, or "" for the container document.
+	Path string
+	// Kind is the metadata key without XTIKAExceptionPrefix, for example
+	// "embedded_exception" or "warn".
+	Kind string
+	// Message is the recorded exception, usually a Java stack trace.
+	Message string
+}
+
+// Warnings returns the exceptions recorded in m, sorted by Kind.
+func (m Metadata) Warnings() []ParseWarning {
+	var ws []ParseWarning
+	path := m.Get(XTIKAEmbeddedResourcePath)
+	for k, vs := range m {
+		if !strings.HasPrefix(k, XTIKAExceptionPrefix) {
+			continue
+		}
+		for _, v := range vs {
+			ws = append(ws, ParseWarning{Path: path, Kind: strings.TrimPrefix(k, XTIKAExceptionPrefix), Message: v})
+		}
+	}
+	sort.SliceStable(ws, func(i, j int) bool { return ws[i].Kind < ws[j].Kind })
+	return ws
+}
+
+// DocumentWarnings returns the exceptions recorded in every document of docs,
+// as returned by MetaRecursive, in document order.
+func DocumentWarnings(docs []Metadata) []ParseWarning {
+	var ws []ParseWarning
+	for _, d := range docs {
+		ws = append(ws, d.Warnings()...)
+	}
+	return ws
+}
+
+// WithStrictEmbedded makes MetaRecursive, ParseRecursive, and the other
+// recursive methods fail with an *EmbeddedExceptionError if any document
+// records an exception, instead of returning a partial extraction.
+func WithStrictEmbedded() Option {
+	return func(c *Client) {
+		c.strictEmbedded = true
+	}
+}
+
+// EmbeddedExceptionError is returned by the recursive methods of a Client
+// created with WithStrictEmbedded when a document records an exception.
+type EmbeddedExceptionError struct {
+	ParseWarning
+}
+
+func (e *EmbeddedExceptionError) Error() string {
+	msg := e.Message
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		msg = msg[:i]
+	}
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("exception in document %s: %s: %s", path, e.Kind, strings.TrimSpace(msg))
+}
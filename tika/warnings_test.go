@@ -0,0 +1,76 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	docs := []Metadata{
+		{
+			XTIKAContent:            {"container"},
+			"X-TIKA:EXCEPTION:warn": {"w1", "w2"},
+			"X-TIKA:EXCEPTION:embedded_stream_exception": {"stream"},
+		},
+		{XTIKAContent: {"clean"}, XTIKAEmbeddedResourcePath: {"/a.txt"}},
+		{XTIKAEmbeddedResourcePath: {"/b.doc"}, "X-TIKA:EXCEPTION:runtime": {"boom"}},
+	}
+	got := DocumentWarnings(docs)
+	want := []ParseWarning{
+		{Kind: "embedded_stream_exception", Message: "stream"},
+		{Kind: "warn", Message: "w1"},
+		{Kind: "warn", Message: "w2"},
+		{Path: "/b.doc", Kind: "runtime", Message: "boom"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DocumentWarnings got %+v, want %+v", got, want)
+	}
+	if ws := docs[1].Warnings(); ws != nil {
+		t.Errorf("Warnings got %+v for a clean document, want nil", ws)
+	}
+}
+
+func TestStrictEmbedded(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"X-TIKA:content":"a"},{"X-TIKA:embedded_resource_path":"/b.doc","X-TIKA:EXCEPTION:embedded_exception":"java.io.IOException: bad\n\tat Foo"}]`)
+	}))
+	defer ts.Close()
+
+	if _, err := NewClient(nil, ts.URL).ParseRecursive(context.Background(), nil); err != nil {
+		t.Fatalf("ParseRecursive got error %v without WithStrictEmbedded", err)
+	}
+
+	c := NewClient(nil, ts.URL, WithStrictEmbedded())
+	_, err := c.ParseRecursive(context.Background(), nil)
+	var ee *EmbeddedExceptionError
+	if !errors.As(err, &ee) {
+		t.Fatalf("ParseRecursive got error %v, want an *EmbeddedExceptionError", err)
+	}
+	if ee.Path != "/b.doc" || ee.Kind != "embedded_exception" {
+		t.Errorf("ParseRecursive got %+v, want path /b.doc and kind embedded_exception", ee.ParseWarning)
+	}
+	if want := "exception in document /b.doc: embedded_exception: java.io.IOException: bad"; err.Error() != want {
+		t.Errorf("Error got %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// XHTMLToText converts XHTML produced by Tika, such as the XTIKAContent of
+// MetaRecursive results, to plain text. Paragraphs and other blocks are
+// separated by blank lines, list items are put on their own lines with a
+// bullet or number, nested lists are indented, and table cells are separated
+// by tabs with one row per line. The content of <head>, <script>, and
+// <style> is dropped.
+func XHTMLToText(r io.Reader) (string, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	w := &textWriter{}
+	type list struct {
+		ordered bool
+		n       int
+	}
+	var (
+		lists []list
+		skip  int
+		pre   int
+		cells int
+	)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if skip > 0 || name == "head" || name == "script" || name == "style" {
+				skip++
+				continue
+			}
+			switch name {
+			case "p", "div", "blockquote", "table", "h1", "h2", "h3", "h4", "h5", "h6":
+				w.breakLines(2)
+			case "pre":
+				w.breakLines(2)
+				pre++
+			case "br":
+				w.breakLines(1)
+			case "ul", "ol":
+				if len(lists) == 0 {
+					w.breakLines(2)
+				}
+				lists = append(lists, list{ordered: name == "ol"})
+			case "li":
+				w.breakLines(1)
+				marker := "-"
+				if len(lists) > 0 {
+					l := &lists[len(lists)-1]
+					if l.ordered {
+						l.n++
+						marker = fmt.Sprintf("%d.", l.n)
+					}
+				}
+				indent := 0
+				if len(lists) > 1 {
+					indent = len(lists) - 1
+				}
+				w.raw(strings.Repeat("  ", indent) + marker + " ")
+			case "tr":
+				w.breakLines(1)
+				cells = 0
+			case "td", "th":
+				if cells > 0 {
+					w.raw("\t")
+				}
+				cells++
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if skip > 0 {
+				skip--
+				continue
+			}
+			switch name {
+			case "p", "div", "blockquote", "table", "h1", "h2", "h3", "h4", "h5", "h6":
+				w.breakLines(2)
+			case "pre":
+				pre--
+				w.breakLines(2)
+			case "ul", "ol":
+				if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+				}
+				if len(lists) == 0 {
+					w.breakLines(2)
+				}
+			case "li", "tr":
+				w.breakLines(1)
+			}
+		case xml.CharData:
+			if skip == 0 {
+				w.text(string(t), pre > 0)
+			}
+		}
+	}
+	return strings.TrimRight(w.b.String(), " \t\n"), nil
+}
+
+// Text returns the XTIKAContent of m as plain text, converting it with
+// XHTMLToText if it is XHTML, as it is when MetaRecursive is called with the
+// "xml" or "html" content type.
+func (m Metadata) Text() (string, error) {
+	content := m.Get(XTIKAContent)
+	if !strings.HasPrefix(strings.TrimSpace(content), "<") {
+		return content, nil
+	}
+	return XHTMLToText(strings.NewReader(content))
+}
+
+// textWriter accumulates text, collapsing whitespace and line breaks.
+type textWriter struct {
+	b     strings.Builder
+	lines int  // Number of trailing newlines.
+	space bool // Whether a space is pending before the next word.
+}
+
+// breakLines ends the current line and adds blank lines so the text ends in
+// at least n newlines. Nothing is added at the start of the text.
+func (w *textWriter) breakLines(n int) {
+	w.space = false
+	if w.b.Len() == 0 {
+		return
+	}
+	for w.lines < n {
+		w.b.WriteByte('\n')
+		w.lines++
+	}
+}
+
+// raw writes s without collapsing whitespace.
+func (w *textWriter) raw(s string) {
+	if s == "" {
+		return
+	}
+	w.b.WriteString(s)
+	if trimmed := strings.TrimRight(s, "\n"); trimmed == "" {
+		w.lines += len(s)
+	} else {
+		w.lines = len(s) - len(trimmed)
+	}
+	w.space = false
+}
+
+// text writes s, collapsing whitespace unless pre is true.
+func (w *textWriter) text(s string, pre bool) {
+	if pre {
+		w.raw(s)
+		return
+	}
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" {
+			w.space = true
+		}
+		return
+	}
+	if (w.space || strings.TrimLeft(s, " \t\r\n") != s) && w.lines == 0 && w.b.Len() > 0 {
+		last := w.b.String()[w.b.Len()-1]
+		if last != ' ' && last != '\t' {
+			w.b.WriteByte(' ')
+		}
+	}
+	w.b.WriteString(strings.Join(words, " "))
+	w.lines = 0
+	w.space = strings.TrimRight(s, " \t\r\n") != s
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2017 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tika
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestXHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "paragraphs",
+			in: `<html><head><title>T</title><style>p{}</style></head><body><p>One  <b>bold</b>
+			text.</p><p>Two&amp;<br/>three</p></body></html>`,
+			want: "One bold text.\n\nTwo&\nthree",
+		},
+		{
+			name: "lists",
+			in:   `<body><p>Intro</p><ul><li>a</li><li>b<ol><li>x</li><li>y</li></ol></li></ul><p>End</p></body>`,
+			want: "Intro\n\n- a\n- b\n  1. x\n  2. y\n\nEnd",
+		},
+		{
+			name: "table",
+			in:   `<body><table><tr><th>Name</th><th>Age</th></tr><tr><td>Ann</td><td>3</td></tr></table></body>`,
+			want: "Name\tAge\nAnn\t3",
+		},
+		{
+			name: "pre",
+			in:   "<body><p>a</p><pre>  x\n    y</pre><p>b</p></body>",
+			want: "a\n\n  x\n    y\n\nb",
+		},
+		{
+			name: "pages",
+			in:   `<body><div class="page"><p>p1</p></div><div class="page"><p>p2</p></div></body>`,
+			want: "p1\n\np2",
+		},
+	}
+	for _, test := range tests {
+		got, err := XHTMLToText(strings.NewReader(test.in))
+		if err != nil {
+			t.Errorf("XHTMLToText(%s) returned an error: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("XHTMLToText(%s) got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestMetadataText(t *testing.T) {
+	m := Metadata{XTIKAContent: {"<html><body><p>a</p><p>b</p></body></html>"}}
+	if got, err := m.Text(); err != nil || got != "a\n\nb" {
+		t.Errorf("Text got %q, %v, want %q, nil", got, err, "a\n\nb")
+	}
+	m = Metadata{XTIKAContent: {"plain text"}}
+	if got, err := m.Text(); err != nil || got != "plain text" {
+		t.Errorf("Text got %q, %v, want %q, nil", got, err, "plain text")
+	}
+}